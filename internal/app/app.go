@@ -71,6 +71,10 @@ func (a *App) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 	case tea.KeyPressMsg:
 		switch msg.String() {
 		case "ctrl+c":
+			// Persist the browser's query buffers before exiting
+			if a.browserScreen != nil {
+				a.browserScreen.Cleanup()
+			}
 			return a, tea.Quit
 		default:
 			// Pass all other keys to the active screen
@@ -86,7 +90,7 @@ func (a *App) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 	case screens.ConnectSuccessMsg:
 		// Initialize browser screen now that we have DB
 		a.db = msg.DB
-		a.browserScreen = screens.NewBrowserModel(a.db)
+		a.browserScreen = screens.NewBrowserModel(a.db, msg.Config)
 
 		// Ensure the browser gets current dimensions immediately.
 		// Without this it can stay in a "Loading..." state waiting for a resize.
@@ -139,6 +143,9 @@ func (a *App) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 			a.connectScreen.Update(tea.WindowSizeMsg{Width: a.width, Height: a.height})
 		}
 		a.currentScreen = ScreenConnect
+		if msg.Path != "" {
+			return a, tea.Batch(a.connectScreen.Init(), a.connectScreen.ConnectTo(msg.Path))
+		}
 		return a, a.connectScreen.Init()
 
 	case ErrorMsg:
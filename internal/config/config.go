@@ -8,6 +8,10 @@
 //   - Theme preference
 //   - Connection history (successful connections only, no passwords)
 //   - Recent queries (last 20)
+//   - Query editor buffers, per connection
+//   - SQL formatter options
+//   - How result cells are displayed
+//   - Results grid column layouts, per connection and table or query
 package config
 
 import (
@@ -25,6 +29,7 @@ const (
 	appName        = "tornado"
 	maxConnections = 10
 	maxQueries     = 20
+	maxLayouts     = 100 // Column layouts kept per connection
 	configFileName = "config.yaml"
 )
 
@@ -41,6 +46,19 @@ type Config struct {
 	// Queries is the list of recent queries
 	Queries []string `yaml:"queries"`
 
+	// QueryBuffers holds the query editor buffers, keyed by ConnectionKey
+	QueryBuffers map[string]BufferSet `yaml:"query_buffers,omitempty"`
+
+	// Formatter holds the SQL formatter options
+	Formatter FormatterConfig `yaml:"formatter"`
+
+	// Display holds how result cells are shown
+	Display DisplayConfig `yaml:"display"`
+
+	// ColumnLayouts holds the results grid column layouts, keyed by
+	// ConnectionKey and then by table or query
+	ColumnLayouts map[string]map[string]ColumnLayout `yaml:"column_layouts,omitempty"`
+
 	// Internal - not persisted
 	configPath string
 }
@@ -59,6 +77,69 @@ type ConnectionEntry struct {
 	UseCount      int       `yaml:"use_count"`
 }
 
+// BufferSet is the saved state of the query editor buffers for one connection.
+type BufferSet struct {
+	Active  int           `yaml:"active"`
+	Buffers []BufferEntry `yaml:"buffers"`
+}
+
+// BufferEntry is a single saved query editor buffer.
+type BufferEntry struct {
+	Name    string `yaml:"name"`
+	Path    string `yaml:"path,omitempty"` // SQL file the buffer was opened from or written to
+	Content string `yaml:"content"`
+	Line    int    `yaml:"line,omitempty"`
+	Column  int    `yaml:"column,omitempty"`
+}
+
+// FormatterConfig holds the SQL formatter options.
+type FormatterConfig struct {
+	KeywordCase  string `yaml:"keyword_case"`  // upper, lower or preserve
+	Indent       int    `yaml:"indent"`        // Spaces per indentation level
+	AlignColumns bool   `yaml:"align_columns"` // One column per line, aligned under the first
+}
+
+// DisplayConfig holds how result cells are shown.
+type DisplayConfig struct {
+	TimeZone       string `yaml:"time_zone"`       // Local, UTC or an IANA name such as Europe/Berlin
+	TimeFormat     string `yaml:"time_format"`     // Go reference layout for timestamps
+	FloatPrecision int    `yaml:"float_precision"` // Digits after the point, -1 for as many as needed
+
+	location *time.Location // TimeZone resolved when the config is loaded
+}
+
+// Location returns the time zone timestamps are shown in. An unknown zone
+// falls back to local time.
+func (d DisplayConfig) Location() *time.Location {
+	if d.location == nil {
+		return time.Local
+	}
+	return d.location
+}
+
+// resolveLocation looks up TimeZone once, so rendering need not.
+func (d *DisplayConfig) resolveLocation() {
+	d.location = nil
+	if loc, err := time.LoadLocation(d.TimeZone); err == nil && d.TimeZone != "" {
+		d.location = loc
+	}
+}
+
+// ColumnLayout is how the results grid shows the columns of one table or
+// query. Columns are named so the layout survives columns being added.
+type ColumnLayout struct {
+	Widths    map[string]int `yaml:"widths,omitempty"` // Widths set by hand
+	Hidden    []string       `yaml:"hidden,omitempty"`
+	Order     []string       `yaml:"order,omitempty"`  // Columns moved by hand, in display order
+	Frozen    int            `yaml:"frozen,omitempty"` // Leading columns kept in view when scrolling
+	UpdatedAt time.Time      `yaml:"updated_at"`
+}
+
+// IsZero reports whether the layout changes nothing.
+func (l ColumnLayout) IsZero() bool {
+	return len(l.Widths) == 0 && len(l.Hidden) == 0 && len(l.Order) == 0 && l.Frozen == 0
+}
+
 // Global config instance
 var (
 	globalConfig *Config
@@ -85,7 +166,17 @@ func Load() (*Config, error) {
 		Theme:       "nord", // Default theme
 		Connections: make([]ConnectionEntry, 0),
 		Queries:     make([]string, 0),
-		configPath:  configPath,
+		Formatter: FormatterConfig{
+			KeywordCase:  "upper",
+			Indent:       2,
+			AlignColumns: true,
+		},
+		Display: DisplayConfig{
+			TimeZone:       "Local",
+			TimeFormat:     "2006-01-02 15:04:05",
+			FloatPrecision: -1,
+		},
+		configPath: configPath,
 	}
 
 	// Check if config file exists
@@ -106,6 +197,8 @@ func Load() (*Config, error) {
 		}
 	}
 
+	cfg.Display.resolveLocation()
+
 	globalMu.Lock()
 	globalConfig = cfg
 	globalMu.Unlock()
@@ -275,6 +368,123 @@ func (c *Config) GetQueries() []string {
 	return result
 }
 
+// ConnectionKey returns the key identifying a connection's saved state.
+// It uses the same fields connectionsEqual compares.
+func ConnectionKey(cfg models.ConnectionConfig) string {
+	switch cfg.Type {
+	case "sqlite":
+		return "sqlite:" + cfg.Path
+	case "postgres":
+		return fmt.Sprintf("postgres:%s@%s:%d/%s", cfg.User, cfg.Host, cfg.Port, cfg.Database)
+	}
+	return cfg.Type + ":" + cfg.Name
+}
+
+// GetFormatter returns the SQL formatter options.
+func (c *Config) GetFormatter() FormatterConfig {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	return c.Formatter
+}
+
+// GetDisplay returns how result cells are shown.
+func (c *Config) GetDisplay() DisplayConfig {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	return c.Display
+}
+
+// GetQueryBuffers returns the saved query buffers for a connection.
+func (c *Config) GetQueryBuffers(key string) (BufferSet, bool) {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+
+	set, ok := c.QueryBuffers[key]
+	if !ok {
+		return BufferSet{}, false
+	}
+	// Return a copy
+	buffers := make([]BufferEntry, len(set.Buffers))
+	copy(buffers, set.Buffers)
+	set.Buffers = buffers
+	return set, true
+}
+
+// SetQueryBuffers saves the query buffers for a connection.
+func (c *Config) SetQueryBuffers(key string, set BufferSet) error {
+	if key == "" {
+		return nil
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if c.QueryBuffers == nil {
+		c.QueryBuffers = make(map[string]BufferSet)
+	}
+	c.QueryBuffers[key] = set
+	return c.saveUnlocked()
+}
+
+// GetColumnLayout returns the saved column layout for a table or query.
+func (c *Config) GetColumnLayout(conn, key string) (ColumnLayout, bool) {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	l, ok := c.ColumnLayouts[conn][key]
+	if !ok {
+		return ColumnLayout{}, false
+	}
+	// Return a copy
+	widths := make(map[string]int, len(l.Widths))
+	for name, w := range l.Widths {
+		widths[name] = w
+	}
+	l.Widths = widths
+	l.Hidden = append([]string(nil), l.Hidden...)
+	l.Order = append([]string(nil), l.Order...)
+	return l, true
+}
+
+// SetColumnLayout saves the column layout for a table or query. A zero
+// layout removes it. Only the most recently changed layouts are kept.
+func (c *Config) SetColumnLayout(conn, key string, layout ColumnLayout) error {
+	if conn == "" || key == "" {
+		return nil
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	layouts := c.ColumnLayouts[conn]
+	if layout.IsZero() {
+		if _, ok := layouts[key]; !ok {
+			return nil
+		}
+		delete(layouts, key)
+		return c.saveUnlocked()
+	}
+
+	if layouts == nil {
+		if c.ColumnLayouts == nil {
+			c.ColumnLayouts = make(map[string]map[string]ColumnLayout)
+		}
+		layouts = make(map[string]ColumnLayout)
+		c.ColumnLayouts[conn] = layouts
+	}
+	layout.UpdatedAt = time.Now()
+	layouts[key] = layout
+	for len(layouts) > maxLayouts {
+		oldest := ""
+		for k, l := range layouts {
+			if oldest == "" || l.UpdatedAt.Before(layouts[oldest].UpdatedAt) {
+				oldest = k
+			}
+		}
+		delete(layouts, oldest)
+	}
+	return c.saveUnlocked()
+}
+
 // Helper methods
 
 func (c *Config) moveConnectionToFront(index int) {
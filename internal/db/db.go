@@ -29,6 +29,10 @@ type Database interface {
 	// Exec executes a SQL statement that doesn't return rows.
 	Exec(sql string) (*models.ExecResult, error)
 
+	// Validate compiles each statement in sql without executing it.
+	// A statement that fails to compile is reported as a *ValidationError.
+	Validate(sql string) error
+
 	// ListTables returns a list of all user tables in the database.
 	ListTables() ([]string, error)
 
@@ -52,10 +56,37 @@ type Database interface {
 	// (Primarily for PostgreSQL, returns empty for SQLite)
 	ListSequences() ([]string, error)
 
+	// SchemaVersion returns a counter that changes whenever the schema does,
+	// including changes made by other connections. Backends that cannot
+	// report one return 0.
+	SchemaVersion() (int64, error)
+
+	// DataVersion returns a counter that changes whenever another
+	// connection commits to the database. Backends that cannot report one
+	// return 0.
+	DataVersion() (int64, error)
+
+	// SetReadOnly makes the connection refuse to change data, enforced by
+	// the database itself, or lets it write again.
+	SetReadOnly(on bool) error
+
 	// GetType returns the database type (sqlite, postgres, etc.)
 	GetType() string
 }
 
+// ValidationError describes a statement that failed to compile. Offset and
+// Length locate the offending text within the validated SQL; Offset is -1
+// when the position is unknown.
+type ValidationError struct {
+	Message string
+	Offset  int
+	Length  int
+}
+
+func (e *ValidationError) Error() string {
+	return e.Message
+}
+
 // Open creates a new database connection based on the config type.
 func Open(config models.ConnectionConfig) (Database, error) {
 	switch config.Type {
@@ -171,6 +171,17 @@ func (p *PostgresDB) Exec(sql string) (*models.ExecResult, error) {
 	return nil, nil
 }
 
+// Validate checks that each statement compiles without running it.
+//
+// TODO: Implement Validate method
+// PREPARE each statement and DEALLOCATE it again; the server reports the
+// error location as a 1-based character position (pq.Error.Position) that
+// maps directly to ValidationError.Offset.
+func (p *PostgresDB) Validate(sql string) error {
+	// TODO: Implement
+	return nil
+}
+
 // ListTables returns all tables in the current schema.
 //
 // Query the information_schema:
@@ -245,6 +256,37 @@ func (p *PostgresDB) ListSequences() ([]string, error) {
 	return nil, nil
 }
 
+// SchemaVersion reports schema changes made by other sessions.
+//
+// TODO: Implement SchemaVersion method
+// Postgres has no schema counter; an event trigger that bumps a sequence
+// on ddl_command_end would provide one.
+func (p *PostgresDB) SchemaVersion() (int64, error) {
+	// TODO: Implement
+	return 0, nil
+}
+
+// DataVersion reports commits made by other sessions.
+//
+// TODO: Implement DataVersion method
+// LISTEN on a channel fed by row triggers, or the xact_commit counter in
+// pg_stat_database (which also counts our own commits).
+func (p *PostgresDB) DataVersion() (int64, error) {
+	// TODO: Implement
+	return 0, nil
+}
+
+// SetReadOnly makes the session refuse writes.
+//
+// TODO: Implement SetReadOnly method
+// SET SESSION CHARACTERISTICS AS TRANSACTION READ ONLY (or READ WRITE)
+// applies to every later transaction of the session; pin a single
+// connection so the pool cannot hand out one without it.
+func (p *PostgresDB) SetReadOnly(on bool) error {
+	// TODO: Implement
+	return nil
+}
+
 // GetType returns "postgres" to identify the database type.
 func (p *PostgresDB) GetType() string {
 	return "postgres"
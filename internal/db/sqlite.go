@@ -7,9 +7,13 @@ package db
 import (
 	"database/sql"
 	"fmt"
+	"regexp"
+	"sort"
+	"strings"
 	"time"
 
 	"github.com/jupiterozeye/tornado/internal/models"
+	"github.com/jupiterozeye/tornado/internal/sqlparse"
 	_ "modernc.org/sqlite"
 )
 
@@ -170,6 +174,144 @@ func (s *SQLiteDB) Exec(sql string) (*models.ExecResult, error) {
 	}, nil
 }
 
+// SetReadOnly switches SQLite's query_only pragma, which makes every
+// statement that would write fail. The pool holds a single connection, so
+// the setting covers everything run after it.
+func (s *SQLiteDB) SetReadOnly(on bool) error {
+	if !s.connected || s.db == nil {
+		return fmt.Errorf("not connected to database")
+	}
+	value := "OFF"
+	if on {
+		value = "ON"
+	}
+	_, err := s.db.Exec("PRAGMA query_only = " + value)
+	return err
+}
+
+// Validate prepares each statement, which compiles it against the current
+// schema without executing it. Validation stops after the first schema
+// change, since later statements may use objects it would create.
+func (s *SQLiteDB) Validate(sql string) error {
+	if !s.connected || s.db == nil {
+		return fmt.Errorf("not connected to database")
+	}
+
+	for _, stmt := range sqlparse.Statements(sql) {
+		if err := s.prepare(stmt.Text); err != nil {
+			msg := sqliteErrorMessage(err)
+			start, end := s.locateError(stmt.Text, msg)
+			if start < 0 {
+				return &ValidationError{Message: msg, Offset: -1}
+			}
+			return &ValidationError{Message: msg, Offset: stmt.Pos + start, Length: end - start}
+		}
+		switch strings.ToUpper(strings.Fields(stmt.Text)[0]) {
+		case "CREATE", "DROP", "ALTER", "ATTACH", "DETACH":
+			return nil
+		}
+	}
+	return nil
+}
+
+// prepare compiles a single statement and discards it.
+func (s *SQLiteDB) prepare(sql string) error {
+	stmt, err := s.db.Prepare(sql)
+	if err != nil {
+		return err
+	}
+	return stmt.Close()
+}
+
+var (
+	// sqliteErrorCode is the result code the driver appends to messages
+	sqliteErrorCode = regexp.MustCompile(` \(\d+\)$`)
+	// sqliteTokenError names the token the parser stopped at
+	sqliteTokenError = regexp.MustCompile(`^(?:near "(.*)": syntax error|unrecognized token: "(.*)")$`)
+	// sqliteNameErrors name the table, column or function that failed to resolve
+	sqliteNameErrors = []*regexp.Regexp{
+		regexp.MustCompile(`^(?:no such [a-z ]+|ambiguous column name): (\S+)$`),
+		regexp.MustCompile(`has no column named (\S+)$`),
+		regexp.MustCompile(`^(?:table|index|view|trigger) (\S+) already exists$`),
+		regexp.MustCompile(`function (\w+)\(\)$`),
+	}
+)
+
+// sqliteErrorMessage strips the driver's generic prefix and result code.
+func sqliteErrorMessage(err error) string {
+	msg := sqliteErrorCode.ReplaceAllString(err.Error(), "")
+	return strings.TrimPrefix(msg, "SQL logic error: ")
+}
+
+// locateError maps a compile error message to the byte range of the
+// offending text in sql. It returns -1, -1 when the message names nothing
+// that can be found.
+func (s *SQLiteDB) locateError(sql, msg string) (start, end int) {
+	if m := sqliteTokenError.FindStringSubmatch(msg); m != nil {
+		near := m[1] + m[2]
+		if near == "" {
+			return -1, -1
+		}
+		// SQLite names the token but not where it is. The offending
+		// occurrence is the first one that still fails the same way when
+		// the statement is cut off right after it.
+		first := -1
+		for from, tries := 0, 0; tries < 20; tries++ {
+			i := strings.Index(sql[from:], near)
+			if i < 0 {
+				break
+			}
+			i += from
+			if first < 0 {
+				first = i
+			}
+			if err := s.prepare(sql[:i+len(near)]); err != nil && sqliteErrorMessage(err) == msg {
+				return i, i + len(near)
+			}
+			from = i + len(near)
+		}
+		if first < 0 {
+			return -1, -1
+		}
+		return first, first + len(near)
+	}
+
+	tokens := sqlparse.Significant(sqlparse.Tokenize(sql))
+	if msg == "incomplete input" && len(tokens) > 0 {
+		last := tokens[len(tokens)-1]
+		return last.Pos, last.End()
+	}
+	for _, re := range sqliteNameErrors {
+		if m := re.FindStringSubmatch(msg); m != nil {
+			return findName(tokens, m[1])
+		}
+	}
+	return -1, -1
+}
+
+// findName returns the byte range of the first reference to a possibly
+// qualified name such as "u.email", falling back to its last part.
+func findName(tokens []sqlparse.Token, name string) (start, end int) {
+	parts := strings.Split(name, ".")
+	for len(parts) > 0 {
+		n := 2*len(parts) - 1
+	next:
+		for i := 0; i+n <= len(tokens); i++ {
+			for j, part := range parts {
+				if !tokens[i+2*j].IsIdent() || !strings.EqualFold(tokens[i+2*j].Ident(), part) {
+					continue next
+				}
+				if j > 0 && tokens[i+2*j-1].Text != "." {
+					continue next
+				}
+			}
+			return tokens[i].Pos, tokens[i+n-1].End()
+		}
+		parts = parts[1:]
+	}
+	return -1, -1
+}
+
 // ListTables returns all user tables in the SQLite database.
 func (s *SQLiteDB) ListTables() ([]string, error) {
 	if !s.connected || s.db == nil {
@@ -252,12 +394,121 @@ func (s *SQLiteDB) DescribeTable(name string) (*models.TableSchema, error) {
 
 		columns = append(columns, col)
 	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	foreignKeys, err := s.loadForeignKeys(name, columns)
+	if err != nil {
+		return nil, err
+	}
 
 	return &models.TableSchema{
-		Name:       name,
-		Columns:    columns,
-		PrimaryKey: primaryKeys,
-	}, rows.Err()
+		Name:        name,
+		Columns:     columns,
+		PrimaryKey:  primaryKeys,
+		ForeignKeys: foreignKeys,
+	}, nil
+}
+
+// loadForeignKeys returns a table's foreign keys and marks the columns that
+// are a foreign key on their own. PRAGMA foreign_key_list has a row per
+// column, grouped by key id and ordered within a key by seq.
+func (s *SQLiteDB) loadForeignKeys(name string, columns []models.Column) ([]models.ForeignKeyInfo, error) {
+	// Query PRAGMA foreign_key_list
+	rows, err := s.db.Query(fmt.Sprintf("PRAGMA foreign_key_list(\"%s\")", name))
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	type keyColumn struct {
+		seq      int
+		from, to string
+		toNull   bool
+		refTable string
+	}
+	var ids []int
+	byID := make(map[int][]keyColumn)
+	for rows.Next() {
+		var id, seq int
+		var refTable, from string
+		var to sql.NullString
+		var onUpdate, onDelete, match string
+
+		if err := rows.Scan(&id, &seq, &refTable, &from, &to, &onUpdate, &onDelete, &match); err != nil {
+			return nil, err
+		}
+		if _, ok := byID[id]; !ok {
+			ids = append(ids, id)
+		}
+		byID[id] = append(byID[id], keyColumn{seq: seq, from: from, to: to.String, toNull: !to.Valid, refTable: refTable})
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	rows.Close()
+
+	var keys []models.ForeignKeyInfo
+	for _, id := range ids {
+		parts := byID[id]
+		sort.Slice(parts, func(i, j int) bool { return parts[i].seq < parts[j].seq })
+		fk := models.ForeignKeyInfo{RefTable: parts[0].refTable}
+		for _, p := range parts {
+			fk.Columns = append(fk.Columns, p.from)
+			fk.RefColumns = append(fk.RefColumns, p.to)
+		}
+		// A NULL target column means the parent's primary key
+		if parts[0].toNull {
+			pk, err := s.primaryKeyColumns(fk.RefTable)
+			if err != nil {
+				return nil, err
+			}
+			for i := range fk.RefColumns {
+				if i < len(pk) {
+					fk.RefColumns[i] = pk[i]
+				}
+			}
+		}
+		keys = append(keys, fk)
+
+		if len(fk.Columns) != 1 {
+			continue
+		}
+		for i := range columns {
+			if columns[i].Name == fk.Columns[0] {
+				columns[i].IsForeignKey = true
+				columns[i].ForeignKeyTable = fk.RefTable
+				columns[i].ForeignKeyColumn = fk.RefColumns[0]
+			}
+		}
+	}
+	return keys, nil
+}
+
+// primaryKeyColumns returns a table's primary key columns in key order, or
+// "rowid" for tables without a declared primary key.
+func (s *SQLiteDB) primaryKeyColumns(table string) ([]string, error) {
+	rows, err := s.db.Query(fmt.Sprintf("SELECT name FROM pragma_table_info(\"%s\") WHERE pk > 0 ORDER BY pk", table))
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var pk []string
+	for rows.Next() {
+		var col string
+		if err := rows.Scan(&col); err != nil {
+			return nil, err
+		}
+		pk = append(pk, col)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	if len(pk) == 0 {
+		return []string{"rowid"}, nil
+	}
+	return pk, nil
 }
 
 // GetType returns "sqlite" to identify the database type.
@@ -356,5 +607,35 @@ func (s *SQLiteDB) ListSequences() ([]string, error) {
 	return []string{}, nil
 }
 
+// SchemaVersion returns PRAGMA schema_version, which SQLite increments on
+// every schema change made through any connection to the file.
+func (s *SQLiteDB) SchemaVersion() (int64, error) {
+	if !s.connected || s.db == nil {
+		return 0, fmt.Errorf("not connected to database")
+	}
+
+	var version int64
+	if err := s.db.QueryRow("PRAGMA schema_version").Scan(&version); err != nil {
+		return 0, err
+	}
+	return version, nil
+}
+
+// DataVersion returns PRAGMA data_version. It changes when another
+// connection or process commits to the file, including through the WAL, but
+// not for our own writes. The pool holds a single connection, so successive
+// calls compare against the same connection.
+func (s *SQLiteDB) DataVersion() (int64, error) {
+	if !s.connected || s.db == nil {
+		return 0, fmt.Errorf("not connected to database")
+	}
+
+	var version int64
+	if err := s.db.QueryRow("PRAGMA data_version").Scan(&version); err != nil {
+		return 0, err
+	}
+	return version, nil
+}
+
 // Ensure SQLiteDB implements Database interface at compile time.
 var _ Database = (*SQLiteDB)(nil)
@@ -0,0 +1,173 @@
+package db
+
+import (
+	"errors"
+	"path/filepath"
+	"reflect"
+	"testing"
+
+	"github.com/jupiterozeye/tornado/internal/models"
+)
+
+func TestSQLiteValidate(t *testing.T) {
+	s := NewSQLiteDB()
+	if err := s.Connect(models.ConnectionConfig{Type: "sqlite", Path: ":memory:"}); err != nil {
+		t.Fatal(err)
+	}
+	defer s.Disconnect()
+	if _, err := s.Exec("CREATE TABLE users (id INTEGER PRIMARY KEY, name TEXT)"); err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		sql  string
+		want string // Text at the reported error position, "" for valid SQL
+	}{
+		{sql: "SELECT id, name FROM users; SELECT 1", want: ""},
+		{sql: "SELECT id FORM users", want: "users"},
+		{sql: "SELECT 1;\nSELECT * FROM user_list u", want: "user_list"},
+		{sql: "SELECT u.nmae FROM users u", want: "u.nmae"},
+		{sql: "SELECT id FROM users WHERE", want: "WHERE"},
+		// Later statements may depend on the table the first one creates
+		{sql: "CREATE TABLE posts (id INTEGER); INSERT INTO posts VALUES (1)", want: ""},
+	}
+	for _, tt := range tests {
+		err := s.Validate(tt.sql)
+		var ve *ValidationError
+		if tt.want == "" {
+			if err != nil {
+				t.Errorf("%q: unexpected error %v", tt.sql, err)
+			}
+			continue
+		}
+		if !errors.As(err, &ve) || ve.Offset < 0 {
+			t.Errorf("%q: got %v, want a located validation error", tt.sql, err)
+			continue
+		}
+		if got := tt.sql[ve.Offset : ve.Offset+ve.Length]; got != tt.want {
+			t.Errorf("%q: error %q at %q, want %q", tt.sql, ve.Message, got, tt.want)
+		}
+	}
+
+	// Of two identical tokens, the second one is the error
+	var ve *ValidationError
+	if err := s.Validate("SELECT 1 = = 2"); !errors.As(err, &ve) || ve.Offset != 11 {
+		t.Errorf("repeated token: got %+v, want offset 11", ve)
+	}
+}
+
+func TestSQLiteVersions(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "watch.db")
+	open := func() *SQLiteDB {
+		s := NewSQLiteDB()
+		if err := s.Connect(models.ConnectionConfig{Type: "sqlite", Path: path}); err != nil {
+			t.Fatal(err)
+		}
+		return s
+	}
+	s, other := open(), open()
+	defer s.Disconnect()
+	defer other.Disconnect()
+
+	schema, _ := s.SchemaVersion()
+	data, _ := s.DataVersion()
+
+	// Our own writes leave data_version alone
+	if _, err := s.Exec("CREATE TABLE a (x INTEGER)"); err != nil {
+		t.Fatal(err)
+	}
+	if v, _ := s.DataVersion(); v != data {
+		t.Errorf("DataVersion changed after own write: %d -> %d", data, v)
+	}
+	if v, _ := s.SchemaVersion(); v == schema {
+		t.Errorf("SchemaVersion unchanged after CREATE TABLE")
+	}
+
+	if _, err := other.Exec("INSERT INTO a VALUES (1)"); err != nil {
+		t.Fatal(err)
+	}
+	if v, _ := s.DataVersion(); v == data {
+		t.Errorf("DataVersion unchanged after another connection's write")
+	}
+}
+
+func TestSQLiteSetReadOnly(t *testing.T) {
+	s := NewSQLiteDB()
+	if err := s.Connect(models.ConnectionConfig{Type: "sqlite", Path: ":memory:"}); err != nil {
+		t.Fatal(err)
+	}
+	defer s.Disconnect()
+	if _, err := s.Exec("CREATE TABLE t (id INTEGER); INSERT INTO t VALUES (1), (2)"); err != nil {
+		t.Fatal(err)
+	}
+	if err := s.SetReadOnly(true); err != nil {
+		t.Fatal(err)
+	}
+
+	// Writes are refused however they are phrased or run
+	for _, sql := range []string{
+		"DELETE FROM t",
+		"WITH x AS (SELECT 1) DELETE FROM t",
+		"SELECT 1; DELETE FROM t",
+	} {
+		s.Query(sql)
+		s.Exec(sql)
+	}
+	r, err := s.Query("SELECT COUNT(*) FROM t")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if n := r.Rows[0][0]; n != int64(2) {
+		t.Errorf("rows left = %v, want 2", n)
+	}
+
+	if err := s.SetReadOnly(false); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := s.Exec("DELETE FROM t"); err != nil {
+		t.Errorf("write after SetReadOnly(false): %v", err)
+	}
+}
+
+func TestSQLiteForeignKeys(t *testing.T) {
+	s := NewSQLiteDB()
+	if err := s.Connect(models.ConnectionConfig{Type: "sqlite", Path: ":memory:"}); err != nil {
+		t.Fatal(err)
+	}
+	defer s.Disconnect()
+	if _, err := s.Exec(`
+CREATE TABLE users (id INTEGER PRIMARY KEY);
+CREATE TABLE orders (region TEXT, num INTEGER, PRIMARY KEY (region, num));
+CREATE TABLE lines (
+	order_num INTEGER, order_region TEXT, user_id INTEGER REFERENCES users,
+	FOREIGN KEY (order_region, order_num) REFERENCES orders
+)`); err != nil {
+		t.Fatal(err)
+	}
+
+	schema, err := s.DescribeTable("lines")
+	if err != nil {
+		t.Fatal(err)
+	}
+	// The composite key stays one key, in key order, with the parent's
+	// primary key filled in
+	want := map[string]models.ForeignKeyInfo{
+		"orders": {Columns: []string{"order_region", "order_num"}, RefTable: "orders", RefColumns: []string{"region", "num"}},
+		"users":  {Columns: []string{"user_id"}, RefTable: "users", RefColumns: []string{"id"}},
+	}
+	if len(schema.ForeignKeys) != len(want) {
+		t.Fatalf("foreign keys = %+v, want %d", schema.ForeignKeys, len(want))
+	}
+	for _, fk := range schema.ForeignKeys {
+		if !reflect.DeepEqual(fk, want[fk.RefTable]) {
+			t.Errorf("foreign key = %+v, want %+v", fk, want[fk.RefTable])
+		}
+	}
+
+	// Only the single-column key marks its column
+	for _, c := range schema.Columns {
+		if got := c.IsForeignKey; got != (c.Name == "user_id") {
+			t.Errorf("%s.IsForeignKey = %v", c.Name, got)
+		}
+	}
+}
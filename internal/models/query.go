@@ -73,7 +73,8 @@ type Column struct {
 	// IsPrimaryKey indicates if this column is a primary key
 	IsPrimaryKey bool
 
-	// IsForeignKey indicates if this column references another table
+	// IsForeignKey indicates if this column alone references another table.
+	// Columns of a composite foreign key are listed in TableSchema.ForeignKeys
 	IsForeignKey bool
 
 	// ForeignKeyTable is the referenced table (if IsForeignKey)
@@ -95,6 +96,9 @@ type TableSchema struct {
 	// PrimaryKey is the name of the primary key column(s)
 	PrimaryKey []string
 
+	// ForeignKeys lists the table's foreign keys, including composite ones
+	ForeignKeys []ForeignKeyInfo
+
 	// Indexes contains information about table indexes
 	Indexes []IndexInfo
 
@@ -102,6 +106,14 @@ type TableSchema struct {
 	RowCount int64
 }
 
+// ForeignKeyInfo represents a foreign key. Columns reference RefColumns of
+// RefTable pairwise, in key order.
+type ForeignKeyInfo struct {
+	Columns    []string
+	RefTable   string
+	RefColumns []string
+}
+
 // IndexInfo represents information about a database index.
 type IndexInfo struct {
 	Name    string
@@ -0,0 +1,97 @@
+package sqlparse
+
+// SchemaChange is a CREATE, ALTER or DROP statement found in a script.
+type SchemaChange struct {
+	Verb string // CREATE, ALTER or DROP
+	Kind string // Object keyword: TABLE, VIEW, INDEX, TRIGGER, SEQUENCE, ...
+	// Names are the unqualified objects the statement touches. A rename
+	// lists both the old and the new name.
+	Names []string
+}
+
+// ddlModifiers may appear between CREATE and the object keyword.
+var ddlModifiers = map[string]bool{
+	"OR": true, "REPLACE": true, "TEMP": true, "TEMPORARY": true,
+	"UNIQUE": true, "VIRTUAL": true, "MATERIALIZED": true, "UNLOGGED": true,
+	"GLOBAL": true, "LOCAL": true, "RECURSIVE": true,
+}
+
+// ddlNamePrefix may appear between the object keyword and its name.
+var ddlNamePrefix = map[string]bool{
+	"IF": true, "NOT": true, "EXISTS": true, "CONCURRENTLY": true, "ONLY": true,
+}
+
+// SchemaChanges returns the DDL statements of a script in order.
+func SchemaChanges(script string) []SchemaChange {
+	var out []SchemaChange
+	for _, stmt := range Statements(script) {
+		if c, ok := schemaChange(Significant(Tokenize(stmt.Text))); ok {
+			out = append(out, c)
+		}
+	}
+	return out
+}
+
+func schemaChange(tokens []Token) (SchemaChange, bool) {
+	if len(tokens) < 2 || tokens[0].Kind != Word {
+		return SchemaChange{}, false
+	}
+	verb := tokens[0].Upper()
+	if verb != "CREATE" && verb != "ALTER" && verb != "DROP" {
+		return SchemaChange{}, false
+	}
+
+	i := 1
+	for i < len(tokens) && tokens[i].Kind == Word && ddlModifiers[tokens[i].Upper()] {
+		i++
+	}
+	if i >= len(tokens) || tokens[i].Kind != Word {
+		return SchemaChange{}, false
+	}
+	c := SchemaChange{Verb: verb, Kind: tokens[i].Upper()}
+	i++
+	for i < len(tokens) && tokens[i].Kind == Word && ddlNamePrefix[tokens[i].Upper()] {
+		i++
+	}
+
+	// DROP takes a list of names; CREATE INDEX ON t has none
+	for i < len(tokens) && !(tokens[i].Kind == Word && tokens[i].Upper() == "ON") {
+		name, next := qualifiedName(tokens, i)
+		if name == "" {
+			break
+		}
+		c.Names = append(c.Names, name)
+		i = next
+		if verb != "DROP" || i >= len(tokens) || tokens[i].Text != "," {
+			break
+		}
+		i++
+	}
+
+	if verb == "ALTER" {
+		for j := i; j+2 < len(tokens); j++ {
+			if tokens[j].Upper() == "RENAME" && tokens[j+1].Upper() == "TO" {
+				if name, _ := qualifiedName(tokens, j+2); name != "" {
+					c.Names = append(c.Names, name)
+				}
+				break
+			}
+		}
+	}
+	return c, true
+}
+
+// qualifiedName reads schema.name at tokens[i] and returns the last part and
+// the index just past it, or "" when tokens[i] is not a name.
+func qualifiedName(tokens []Token, i int) (string, int) {
+	if i >= len(tokens) || !tokens[i].IsIdent() {
+		return "", i
+	}
+	name := tokens[i].Ident()
+	i++
+	for i+1 < len(tokens) && tokens[i].Text == "." && tokens[i+1].IsIdent() {
+		name = tokens[i+1].Ident()
+		i += 2
+	}
+	return name, i
+}
@@ -0,0 +1,28 @@
+package sqlparse
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestSchemaChanges(t *testing.T) {
+	sql := `SELECT 1;
+CREATE TABLE IF NOT EXISTS main."Order Items" (id INTEGER);
+create unique index idx_a on a (x);
+CREATE INDEX ON b (y);
+DROP TABLE IF EXISTS a, s.b CASCADE;
+ALTER TABLE a RENAME TO b;
+ALTER TABLE c ADD COLUMN d TEXT;
+INSERT INTO t VALUES (1)`
+	want := []SchemaChange{
+		{Verb: "CREATE", Kind: "TABLE", Names: []string{"Order Items"}},
+		{Verb: "CREATE", Kind: "INDEX", Names: []string{"idx_a"}},
+		{Verb: "CREATE", Kind: "INDEX"},
+		{Verb: "DROP", Kind: "TABLE", Names: []string{"a", "b"}},
+		{Verb: "ALTER", Kind: "TABLE", Names: []string{"a", "b"}},
+		{Verb: "ALTER", Kind: "TABLE", Names: []string{"c"}},
+	}
+	if got := SchemaChanges(sql); !reflect.DeepEqual(got, want) {
+		t.Errorf("SchemaChanges =\n%+v\nwant\n%+v", got, want)
+	}
+}
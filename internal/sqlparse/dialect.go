@@ -0,0 +1,210 @@
+package sqlparse
+
+import "strings"
+
+// Dialect is the SQL vocabulary of one database backend: its keywords,
+// built-in functions and type names. Names are upper-case.
+type Dialect struct {
+	Name      string
+	Keywords  []string
+	Functions []string
+	Types     []string
+	// Pragmas lists SQLite PRAGMA names (lower-case); empty for other backends.
+	Pragmas []string
+
+	keywords  map[string]bool
+	functions map[string]bool
+	types     map[string]bool
+	docs      map[string]FunctionDoc
+}
+
+// IsKeyword reports whether word is a keyword of the dialect.
+func (d *Dialect) IsKeyword(word string) bool {
+	return d.keywords[strings.ToUpper(word)]
+}
+
+// IsFunction reports whether name is a built-in function of the dialect.
+func (d *Dialect) IsFunction(name string) bool {
+	return d.functions[strings.ToUpper(name)]
+}
+
+// IsType reports whether name is a type name of the dialect.
+func (d *Dialect) IsType(name string) bool {
+	return d.types[strings.ToUpper(name)]
+}
+
+// newDialect builds a dialect from the common catalog plus backend additions.
+func newDialect(name string, keywords, functions, types []string) *Dialect {
+	d := &Dialect{
+		Name:      name,
+		Keywords:  mergeNames(commonKeywords, keywords),
+		Functions: mergeNames(commonFunctions, functions),
+		Types:     mergeNames(commonTypes, types),
+	}
+	d.keywords = nameSet(d.Keywords)
+	d.functions = nameSet(d.Functions)
+	d.types = nameSet(d.Types)
+	return d
+}
+
+func mergeNames(base, extra []string) []string {
+	seen := nameSet(base)
+	out := append([]string{}, base...)
+	for _, name := range extra {
+		if !seen[name] {
+			seen[name] = true
+			out = append(out, name)
+		}
+	}
+	return out
+}
+
+func nameSet(names []string) map[string]bool {
+	set := make(map[string]bool, len(names))
+	for _, name := range names {
+		set[name] = true
+	}
+	return set
+}
+
+// Built-in dialects. Generic covers backends without a catalog of their own.
+var (
+	Generic  = newDialect("generic", nil, nil, nil)
+	SQLite   = newDialect("sqlite", sqliteKeywords, sqliteFunctions, sqliteTypes)
+	Postgres = newDialect("postgres", postgresKeywords, postgresFunctions, postgresTypes)
+)
+
+func init() {
+	SQLite.Pragmas = sqlitePragmas
+	Generic.docs = docSet(commonDocs)
+	SQLite.docs = docSet(commonDocs, sqliteDocs)
+	Postgres.docs = docSet(commonDocs, postgresDocs)
+}
+
+// dialects maps Database.GetType() values to their dialect.
+var dialects = map[string]*Dialect{
+	"sqlite":     SQLite,
+	"postgres":   Postgres,
+	"postgresql": Postgres,
+}
+
+// DialectFor returns the dialect for a database type as reported by
+// Database.GetType(), falling back to Generic for unknown types.
+func DialectFor(dbType string) *Dialect {
+	if d, ok := dialects[strings.ToLower(dbType)]; ok {
+		return d
+	}
+	return Generic
+}
+
+// commonKeywords are the keywords shared by every supported dialect.
+var commonKeywords = []string{
+	"SELECT", "FROM", "WHERE", "AND", "OR", "NOT", "IN", "IS", "NULL", "AS",
+	"ON", "JOIN", "LEFT", "RIGHT", "INNER", "OUTER", "CROSS", "FULL", "NATURAL", "USING",
+	"INSERT", "INTO", "VALUES", "UPDATE", "SET", "DELETE",
+	"CREATE", "ALTER", "DROP", "TABLE", "INDEX", "VIEW", "TRIGGER", "DATABASE",
+	"ADD", "COLUMN", "RENAME", "TO", "TEMP", "TEMPORARY",
+	"IF", "EXISTS", "BEGIN", "END", "COMMIT", "ROLLBACK", "TRANSACTION", "SAVEPOINT", "RELEASE",
+	"ORDER", "BY", "GROUP", "HAVING", "LIMIT", "OFFSET",
+	"UNION", "ALL", "DISTINCT", "EXCEPT", "INTERSECT",
+	"ASC", "DESC", "NULLS", "FIRST", "LAST", "COLLATE", "ESCAPE",
+	"BETWEEN", "LIKE", "CASE", "WHEN", "THEN", "ELSE", "WITH", "RECURSIVE",
+	"PRIMARY", "KEY", "FOREIGN", "REFERENCES", "CONSTRAINT", "UNIQUE", "CHECK", "DEFAULT", "CASCADE",
+	"TRUE", "FALSE", "EXPLAIN", "ANALYZE",
+	"WINDOW", "OVER", "PARTITION", "FILTER", "ROWS", "RANGE", "GROUPS",
+	"UNBOUNDED", "PRECEDING", "FOLLOWING", "CURRENT", "ROW",
+}
+
+// commonFunctions are the functions shared by every supported dialect.
+var commonFunctions = []string{
+	"COUNT", "SUM", "AVG", "MIN", "MAX",
+	"COALESCE", "NULLIF", "CAST",
+	"LENGTH", "LOWER", "UPPER", "TRIM", "LTRIM", "RTRIM", "SUBSTR", "SUBSTRING", "REPLACE",
+	"ABS", "ROUND", "CEIL", "FLOOR", "MOD", "POWER", "SQRT", "LN", "LOG", "EXP", "SIGN",
+	"ROW_NUMBER", "RANK", "DENSE_RANK", "PERCENT_RANK", "CUME_DIST", "NTILE",
+	"LAG", "LEAD", "FIRST_VALUE", "LAST_VALUE", "NTH_VALUE",
+	"CURRENT_TIMESTAMP", "CURRENT_DATE", "CURRENT_TIME",
+}
+
+// commonTypes are the type names shared by every supported dialect.
+var commonTypes = []string{
+	"INTEGER", "INT", "SMALLINT", "BIGINT", "REAL", "FLOAT", "DOUBLE", "DECIMAL", "NUMERIC",
+	"TEXT", "VARCHAR", "CHAR", "BOOLEAN", "DATE", "TIMESTAMP",
+}
+
+var sqliteKeywords = []string{
+	"PRAGMA", "AUTOINCREMENT", "WITHOUT", "ROWID", "STRICT", "VIRTUAL",
+	"REPLACE", "ABORT", "FAIL", "IGNORE", "CONFLICT", "DO", "NOTHING", "RETURNING",
+	"GLOB", "REGEXP", "MATCH", "ISNULL", "NOTNULL",
+	"VACUUM", "ATTACH", "DETACH", "REINDEX", "INDEXED",
+	"DEFERRABLE", "INITIALLY", "DEFERRED", "IMMEDIATE", "EXCLUSIVE",
+	"GENERATED", "ALWAYS", "STORED", "QUERY", "PLAN",
+}
+
+var sqliteFunctions = []string{
+	"IFNULL", "IIF", "INSTR", "PRINTF", "FORMAT", "CHAR", "UNICODE", "HEX", "UNHEX", "QUOTE",
+	"RANDOM", "RANDOMBLOB", "ZEROBLOB", "TYPEOF", "LIKELY", "UNLIKELY", "GLOB",
+	"CONCAT", "CONCAT_WS", "GROUP_CONCAT", "STRING_AGG", "TOTAL",
+	"DATE", "TIME", "DATETIME", "JULIANDAY", "UNIXEPOCH", "STRFTIME", "TIMEDIFF",
+	"CHANGES", "TOTAL_CHANGES", "LAST_INSERT_ROWID", "SQLITE_VERSION",
+	"JSON", "JSON_ARRAY", "JSON_OBJECT", "JSON_EXTRACT", "JSON_TYPE", "JSON_VALID",
+	"JSON_SET", "JSON_INSERT", "JSON_REPLACE", "JSON_REMOVE", "JSON_PATCH",
+	"JSON_ARRAY_LENGTH", "JSON_EACH", "JSON_TREE", "JSON_GROUP_ARRAY", "JSON_GROUP_OBJECT",
+	"CEILING", "PI", "TRUNC",
+}
+
+var sqliteTypes = []string{
+	"BLOB", "DATETIME", "ANY",
+}
+
+// sqlitePragmas are the PRAGMA names offered by completion.
+var sqlitePragmas = []string{
+	"application_id", "auto_vacuum", "busy_timeout", "cache_size", "case_sensitive_like",
+	"compile_options", "database_list", "defer_foreign_keys", "encoding",
+	"foreign_key_check", "foreign_key_list", "foreign_keys", "freelist_count",
+	"function_list", "index_info", "index_list", "index_xinfo", "integrity_check",
+	"journal_mode", "locking_mode", "max_page_count", "mmap_size", "optimize",
+	"page_count", "page_size", "pragma_list", "query_only", "quick_check",
+	"recursive_triggers", "schema_version", "secure_delete", "synchronous",
+	"table_info", "table_list", "table_xinfo", "temp_store", "user_version",
+	"wal_checkpoint",
+}
+
+var postgresKeywords = []string{
+	"RETURNING", "ILIKE", "SIMILAR", "LATERAL", "ONLY", "ARRAY", "ANY", "SOME", "SYMMETRIC",
+	"CONFLICT", "DO", "NOTHING", "FETCH", "NEXT", "TIES", "FOR", "SHARE", "NOWAIT", "SKIP", "LOCKED",
+	"SCHEMA", "SEQUENCE", "EXTENSION", "MATERIALIZED", "CONCURRENTLY", "REFRESH",
+	"FUNCTION", "PROCEDURE", "RETURNS", "LANGUAGE", "DECLARE", "TYPE", "ENUM", "DOMAIN",
+	"GRANT", "REVOKE", "ROLE", "OWNER", "TABLESPACE", "INHERITS",
+	"VACUUM", "COPY", "TRUNCATE", "RESTART", "IDENTITY", "GENERATED", "ALWAYS", "STORED",
+	"LISTEN", "NOTIFY", "SHOW", "RESET", "COMMENT", "VERBOSE", "BUFFERS",
+	"DEFERRABLE", "INITIALLY", "DEFERRED", "IMMEDIATE", "ISNULL", "NOTNULL",
+	"ZONE", "WITHOUT", "PRECISION", "VARYING",
+}
+
+var postgresFunctions = []string{
+	"NOW", "AGE", "EXTRACT", "DATE_TRUNC", "DATE_PART", "MAKE_DATE", "MAKE_INTERVAL",
+	"TO_CHAR", "TO_DATE", "TO_TIMESTAMP", "TO_NUMBER", "CLOCK_TIMESTAMP",
+	"CONCAT", "CONCAT_WS", "FORMAT", "LEFT", "RIGHT", "LPAD", "RPAD", "INITCAP",
+	"POSITION", "STRPOS", "SPLIT_PART", "REGEXP_REPLACE", "REGEXP_MATCHES", "REGEXP_SPLIT_TO_ARRAY",
+	"STRING_AGG", "ARRAY_AGG", "ARRAY_LENGTH", "ARRAY_TO_STRING", "UNNEST", "CARDINALITY",
+	"GENERATE_SERIES", "GENERATE_SUBSCRIPTS",
+	"JSON_AGG", "JSON_BUILD_OBJECT", "JSON_BUILD_ARRAY", "TO_JSON", "ROW_TO_JSON",
+	"JSONB_AGG", "JSONB_BUILD_OBJECT", "JSONB_BUILD_ARRAY", "JSONB_OBJECT_AGG",
+	"JSONB_ARRAY_ELEMENTS", "JSONB_ARRAY_ELEMENTS_TEXT", "JSONB_ARRAY_LENGTH",
+	"JSONB_EACH", "JSONB_EACH_TEXT", "JSONB_OBJECT_KEYS", "JSONB_EXTRACT_PATH",
+	"JSONB_EXTRACT_PATH_TEXT", "JSONB_SET", "JSONB_INSERT", "JSONB_STRIP_NULLS",
+	"JSONB_TYPEOF", "JSONB_PRETTY", "JSONB_PATH_QUERY", "TO_JSONB",
+	"BOOL_AND", "BOOL_OR", "EVERY", "GREATEST", "LEAST", "CEILING", "TRUNC", "RANDOM",
+	"MD5", "GEN_RANDOM_UUID", "NEXTVAL", "CURRVAL", "SETVAL",
+	"CURRENT_USER", "CURRENT_SCHEMA", "VERSION",
+	"PG_SIZE_PRETTY", "PG_TOTAL_RELATION_SIZE", "PG_RELATION_SIZE", "PG_TYPEOF",
+}
+
+var postgresTypes = []string{
+	"SERIAL", "BIGSERIAL", "SMALLSERIAL", "INT2", "INT4", "INT8", "FLOAT4", "FLOAT8", "MONEY",
+	"CHARACTER", "BYTEA", "BOOL", "TIME", "TIMETZ", "TIMESTAMPTZ", "INTERVAL",
+	"UUID", "JSON", "JSONB", "XML", "INET", "CIDR", "MACADDR",
+	"TSVECTOR", "TSQUERY", "POINT", "INT4RANGE", "INT8RANGE", "NUMRANGE", "TSRANGE", "TSTZRANGE",
+	"DATERANGE", "REGCLASS", "OID",
+}
@@ -0,0 +1,467 @@
+package sqlparse
+
+import (
+	"strings"
+	"unicode/utf8"
+)
+
+// FormatOptions controls Format.
+type FormatOptions struct {
+	KeywordCase  string // "upper" (default), "lower" or "preserve"
+	Indent       int    // Spaces per indentation level; 2 if zero
+	AlignColumns bool   // One SELECT, SET or VALUES item per line, aligned under the first
+	Dialect      *Dialect
+}
+
+type frameKind int
+
+const (
+	queryFrame frameKind = iota // Statement or subquery
+	parenFrame                  // Function call, IN list, column list, ...
+	caseFrame                   // CASE ... END
+)
+
+// frame is one level of nesting in the formatted output.
+type frame struct {
+	kind   frameKind
+	indent int // Column clauses (or WHEN/ELSE) start at
+	close  int // Column the closing ")" or END goes at
+
+	clause       string
+	align        int  // Column list items line up at, -1 if unknown
+	alignPending bool // The next token starts the first list item
+	between      bool // Inside BETWEEN, before its AND
+}
+
+// alignedClauses have their comma-separated items put one per line.
+var alignedClauses = map[string]bool{"SELECT": true, "SET": true, "VALUES": true, "RETURNING": true}
+
+// breakingClauses start a new line at the query's indentation.
+var breakingClauses = map[string]bool{
+	"SELECT": true, "FROM": true, "WHERE": true, "HAVING": true, "LIMIT": true, "OFFSET": true,
+	"VALUES": true, "SET": true, "RETURNING": true, "WINDOW": true,
+	"UNION": true, "INTERSECT": true, "EXCEPT": true,
+}
+
+// joinModifiers can precede JOIN.
+var joinModifiers = map[string]bool{
+	"LEFT": true, "RIGHT": true, "INNER": true, "OUTER": true, "FULL": true, "CROSS": true, "NATURAL": true,
+}
+
+// formatter accumulates formatted output line by line.
+type formatter struct {
+	opts   FormatOptions
+	lines  []string
+	cur    string
+	frames []*frame
+
+	noSpace       bool // Suppress the space before the next token
+	lineCommentAt int  // Offset in cur of a trailing line comment, or -1
+}
+
+// Format pretty-prints SQL: keywords are re-cased, each clause starts a new
+// line, subqueries and CASE expressions are indented, and comments are kept.
+// Text that is not SQL passes through with only its whitespace changed.
+func Format(text string, opts FormatOptions) string {
+	if opts.Indent <= 0 {
+		opts.Indent = 2
+	}
+	if opts.Dialect == nil {
+		opts.Dialect = Generic
+	}
+	f := &formatter{opts: opts}
+	f.reset()
+
+	// Significant tokens and comments, with whether a line break preceded each
+	var toks []Token
+	var breakBefore []bool
+	sawBreak := false
+	for _, t := range Tokenize(text) {
+		if t.Kind == Whitespace {
+			sawBreak = sawBreak || strings.Contains(t.Text, "\n")
+			continue
+		}
+		toks = append(toks, t)
+		breakBefore = append(breakBefore, sawBreak)
+		sawBreak = false
+	}
+
+	var prev, prevPrev *Token
+	pendingStatement := false
+	commentBreak := -1 // Indent to break to after a line comment
+
+	for i := 0; i < len(toks); i++ {
+		t := toks[i]
+
+		if t.Kind == Comment {
+			if pendingStatement && breakBefore[i] {
+				f.statementBreak()
+				pendingStatement = false
+			} else if breakBefore[i] && !f.blank() {
+				f.newline(f.lineIndent())
+			}
+			if strings.HasPrefix(t.Text, "--") {
+				f.lineCommentAt = len(f.cur)
+			}
+			f.write(t.Text, true)
+			if strings.HasPrefix(t.Text, "--") || strings.Contains(t.Text, "\n") {
+				commentBreak = f.lineIndent()
+			}
+			continue
+		}
+
+		// "a -- note\n, b": the comma moves before the comment
+		if t.Text == "," && commentBreak >= 0 && f.lineCommentAt >= 0 {
+			f.cur = f.cur[:f.lineCommentAt] + "," + f.cur[f.lineCommentAt:]
+			f.newline(commentBreak)
+			if top := f.top(); top.kind == queryFrame && alignedClauses[top.clause] && f.opts.AlignColumns && top.align >= 0 {
+				f.cur = strings.Repeat(" ", top.align)
+			}
+			commentBreak = -1
+			prevPrev, prev = prev, &toks[i]
+			continue
+		}
+
+		if pendingStatement {
+			f.statementBreak()
+			pendingStatement = false
+			commentBreak = -1
+			prev, prevPrev = nil, nil
+		}
+		if commentBreak >= 0 {
+			f.newline(commentBreak)
+			commentBreak = -1
+		}
+
+		next := nextSignificant(toks, i)
+		top := f.top()
+		up := t.Upper()
+		keyword := t.Kind == Word && opts.Dialect.IsKeyword(up) &&
+			(prev == nil || prev.Text != ".") && (next == nil || next.Text != ".")
+		text := t.Text
+		if keyword {
+			text = f.caseKeyword(t.Text)
+		}
+		space := f.spaceBefore(prev, prevPrev, t)
+
+		// The first item of an aligned list fixes the alignment column
+		if top.kind == queryFrame && top.alignPending && !(keyword && (up == "DISTINCT" || up == "ALL")) {
+			top.align = f.startCol(space)
+			top.alignPending = false
+		}
+
+		switch {
+		case t.Text == ";":
+			f.write(";", false)
+			pendingStatement = true
+
+		case t.Text == ",":
+			f.write(",", false)
+			if top.kind == queryFrame {
+				switch {
+				case alignedClauses[top.clause] && f.opts.AlignColumns && top.align >= 0:
+					f.newline(top.align)
+				case top.clause == "WITH":
+					f.newline(top.indent)
+				}
+			}
+
+		case t.Text == "(":
+			if next != nil && (next.Upper() == "SELECT" || next.Upper() == "WITH" || next.Upper() == "VALUES") {
+				base := f.lineIndent()
+				f.write("(", space)
+				f.push(&frame{kind: queryFrame, indent: base + opts.Indent, close: base, align: -1})
+				f.newline(base + opts.Indent)
+			} else {
+				f.write("(", space)
+				f.push(&frame{kind: parenFrame})
+			}
+			f.noSpace = true
+
+		case t.Text == ")":
+			for f.top().kind == caseFrame && len(f.frames) > 1 {
+				f.pop()
+			}
+			if top := f.top(); top.kind == queryFrame && len(f.frames) > 1 {
+				f.newline(top.close)
+				f.pop()
+			} else if top.kind == parenFrame {
+				f.pop()
+			}
+			f.write(")", false)
+
+		case t.Text == "." || t.Text == "::":
+			f.write(t.Text, false)
+			f.noSpace = true
+
+		case t.Kind == Punct && (t.Text == "$" || t.Text == "@" || t.Text == ":"):
+			// Parameter prefixes: $1, @name, :name
+			f.write(t.Text, space)
+			f.noSpace = true
+
+		case t.Kind == Punct && (t.Text == "-" || t.Text == "+") && isUnaryPosition(prev, opts.Dialect):
+			f.write(t.Text, space)
+			f.noSpace = true
+
+		case top.kind == caseFrame && keyword && (up == "WHEN" || up == "ELSE"):
+			f.newline(top.indent)
+			f.write(text, true)
+
+		case top.kind == caseFrame && keyword && up == "END":
+			f.newline(top.close)
+			f.write(text, true)
+			f.pop()
+
+		case keyword && up == "CASE":
+			col := f.startCol(space)
+			f.write(text, space)
+			f.push(&frame{kind: caseFrame, indent: col + opts.Indent, close: col})
+
+		case top.kind == queryFrame && keyword:
+			f.queryKeyword(toks, &i, prev, prevPrev, text, up)
+
+		default:
+			f.write(text, space)
+		}
+
+		prevPrev, prev = prev, &toks[i]
+	}
+
+	f.flush()
+	return strings.Join(f.lines, "\n")
+}
+
+// queryKeyword writes a keyword at query level, starting a new line for
+// clause keywords. It may consume the following token (BY of GROUP BY).
+func (f *formatter) queryKeyword(toks []Token, i *int, prev, prevPrev *Token, text, up string) {
+	top := f.top()
+	t := toks[*i]
+	next := nextSignificant(toks, *i)
+	prevUp := ""
+	if prev != nil {
+		prevUp = prev.Upper()
+	}
+	statementStart := prev == nil || prev.Text == "(" || prev.Text == ")" || prev.Text == ";"
+
+	switch {
+	case (up == "GROUP" || up == "ORDER") && next != nil && next.Upper() == "BY":
+		f.newline(top.indent)
+		f.write(text, true)
+		*i = indexOf(toks, *next)
+		f.write(f.caseKeyword(next.Text), true)
+		top.clause = up + " BY"
+
+	case up == "FROM" && prevUp == "DELETE":
+		f.write(text, true)
+		top.clause = "FROM"
+
+	case breakingClauses[up]:
+		f.newline(top.indent)
+		f.write(text, true)
+		top.clause = up
+		top.alignPending = alignedClauses[up]
+		top.align = -1
+
+	case (up == "WITH" || up == "INSERT" || up == "UPDATE" || up == "DELETE") && statementStart:
+		f.newline(top.indent)
+		f.write(text, true)
+		top.clause = up
+
+	case joinModifiers[up] && isJoinStart(toks, *i) && !joinModifiers[prevUp]:
+		f.newline(top.indent)
+		f.write(text, true)
+		top.clause = "JOIN"
+
+	case up == "JOIN" && !joinModifiers[prevUp]:
+		f.newline(top.indent)
+		f.write(text, true)
+		top.clause = "JOIN"
+
+	case up == "ON":
+		f.write(text, true)
+		top.clause = "ON"
+
+	case up == "BETWEEN":
+		f.write(text, true)
+		top.between = true
+
+	case up == "AND" && top.between:
+		f.write(text, true)
+		top.between = false
+
+	case (up == "AND" || up == "OR") && (top.clause == "WHERE" || top.clause == "HAVING" || top.clause == "ON"):
+		f.newline(top.indent + f.opts.Indent)
+		f.write(text, true)
+
+	default:
+		f.write(text, f.spaceBefore(prev, prevPrev, t))
+	}
+}
+
+// isJoinStart reports whether the join modifier at i is followed by JOIN.
+func isJoinStart(toks []Token, i int) bool {
+	for j := i + 1; j < len(toks) && j <= i+3; j++ {
+		if toks[j].Kind == Comment {
+			continue
+		}
+		up := toks[j].Upper()
+		if up == "JOIN" {
+			return true
+		}
+		if !joinModifiers[up] {
+			return false
+		}
+	}
+	return false
+}
+
+// isUnaryPosition reports whether a + or - after prev is a sign rather than
+// a binary operator.
+func isUnaryPosition(prev *Token, d *Dialect) bool {
+	if prev == nil {
+		return true
+	}
+	switch prev.Kind {
+	case Punct:
+		return prev.Text != ")"
+	case Word:
+		return d.IsKeyword(prev.Text)
+	}
+	return false
+}
+
+func nextSignificant(toks []Token, i int) *Token {
+	for j := i + 1; j < len(toks); j++ {
+		if toks[j].Kind != Comment {
+			return &toks[j]
+		}
+	}
+	return nil
+}
+
+func indexOf(toks []Token, t Token) int {
+	for i := range toks {
+		if toks[i].Pos == t.Pos {
+			return i
+		}
+	}
+	return len(toks) - 1
+}
+
+// spaceBefore reports whether a space separates t from the previous tokens.
+func (f *formatter) spaceBefore(prev, prevPrev *Token, t Token) bool {
+	if f.noSpace {
+		return false
+	}
+	switch t.Text {
+	case ",", ")", ";", ".", "::":
+		return false
+	}
+	if prev == nil {
+		return true
+	}
+	callee := prev.Kind == QuotedIdent ||
+		(prev.Kind == Word && (!f.opts.Dialect.IsKeyword(prev.Text) || f.opts.Dialect.IsFunction(prev.Text)))
+	if t.Text == "(" && callee {
+		// "INTO t (a, b)" and "TABLE t (...)" are column lists, not calls
+		if prevPrev != nil {
+			switch prevPrev.Upper() {
+			case "INTO", "TABLE", "EXISTS", "VIEW":
+				return true
+			}
+		}
+		return false
+	}
+	// Postgres array subscripts lex like [bracketed] identifiers
+	if t.Kind == QuotedIdent && strings.HasPrefix(t.Text, "[") && (callee || prev.Text == ")") {
+		return false
+	}
+	return true
+}
+
+func (f *formatter) caseKeyword(word string) string {
+	switch f.opts.KeywordCase {
+	case "lower":
+		return strings.ToLower(word)
+	case "preserve":
+		return word
+	}
+	return strings.ToUpper(word)
+}
+
+func (f *formatter) reset() {
+	f.frames = []*frame{{kind: queryFrame, align: -1}}
+	f.lineCommentAt = -1
+}
+
+func (f *formatter) top() *frame {
+	return f.frames[len(f.frames)-1]
+}
+
+func (f *formatter) push(fr *frame) {
+	f.frames = append(f.frames, fr)
+}
+
+func (f *formatter) pop() {
+	if len(f.frames) > 1 {
+		f.frames = f.frames[:len(f.frames)-1]
+	}
+}
+
+// blank reports whether the current line has no content yet.
+func (f *formatter) blank() bool {
+	return strings.TrimSpace(f.cur) == ""
+}
+
+// lineIndent returns the indentation of the current line.
+func (f *formatter) lineIndent() int {
+	return len(f.cur) - len(strings.TrimLeft(f.cur, " "))
+}
+
+// startCol returns the column the next token starts at.
+func (f *formatter) startCol(space bool) int {
+	col := utf8.RuneCountInString(f.cur)
+	if space && !f.blank() && !strings.HasSuffix(f.cur, " ") {
+		col++
+	}
+	return col
+}
+
+// write appends text to the current line, after a space if space is set.
+func (f *formatter) write(text string, space bool) {
+	if f.noSpace {
+		space = false
+		f.noSpace = false
+	}
+	if space && !f.blank() && !strings.HasSuffix(f.cur, " ") {
+		f.cur += " "
+	}
+	f.cur += text
+}
+
+// newline ends the current line and indents the next one to col. A blank
+// current line is re-indented instead, so breaks never produce empty lines.
+func (f *formatter) newline(col int) {
+	if !f.blank() {
+		f.lines = append(f.lines, strings.TrimRight(f.cur, " "))
+	}
+	f.cur = strings.Repeat(" ", col)
+	f.noSpace = false
+	f.lineCommentAt = -1
+}
+
+// statementBreak separates two statements with a blank line.
+func (f *formatter) statementBreak() {
+	f.flush()
+	f.lines = append(f.lines, "")
+	f.reset()
+}
+
+func (f *formatter) flush() {
+	if !f.blank() {
+		f.lines = append(f.lines, strings.TrimRight(f.cur, " "))
+	}
+	f.cur = ""
+	f.noSpace = false
+	f.lineCommentAt = -1
+}
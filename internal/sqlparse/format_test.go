@@ -0,0 +1,102 @@
+package sqlparse
+
+import "testing"
+
+func TestFormat(t *testing.T) {
+	aligned := FormatOptions{KeywordCase: "upper", Indent: 2, AlignColumns: true}
+	tests := []struct {
+		sql  string
+		opts FormatOptions
+		want string
+	}{
+		{
+			sql:  "select id, name from users u where a = 1 and b in (select x from y) order by id",
+			opts: FormatOptions{KeywordCase: "upper", Indent: 2, AlignColumns: true},
+			want: "SELECT id,\n       name\nFROM users u\nWHERE a = 1\n  AND b IN (\n    SELECT x\n    FROM y\n  )\nORDER BY id",
+		},
+		{
+			// Comments survive and keywords keep the requested case
+			sql:  "SELECT a -- first col\n, b FROM t",
+			opts: FormatOptions{KeywordCase: "lower", Indent: 2, AlignColumns: true},
+			want: "select a, -- first col\n       b\nfrom t",
+		},
+		{
+			sql:  "select id, case when a > 1 then 'big' when a = 1 then 'one' else 'small' end as size from t",
+			opts: aligned,
+			want: "SELECT id,\n       CASE\n         WHEN a > 1 THEN 'big'\n         WHEN a = 1 THEN 'one'\n         ELSE 'small'\n       END AS size\nFROM t",
+		},
+		{
+			sql:  "select u.id, o.total from users u left join orders o on o.user_id = u.id and o.total > 0 inner join x on x.id = u.id where u.id = 1",
+			opts: aligned,
+			want: "SELECT u.id,\n       o.total\nFROM users u\nLEFT JOIN orders o ON o.user_id = u.id\n  AND o.total > 0\nINNER JOIN x ON x.id = u.id\nWHERE u.id = 1",
+		},
+		{
+			sql:  "insert into t (a, b) values (1, 'x'), (2, 'y')",
+			opts: aligned,
+			want: "INSERT INTO t (a, b)\nVALUES (1, 'x'),\n       (2, 'y')",
+		},
+		{
+			sql:  "update t set a = 1, b = 'x' where id = 2",
+			opts: aligned,
+			want: "UPDATE t\nSET a = 1,\n    b = 'x'\nWHERE id = 2",
+		},
+		{
+			sql:  "delete from t where id in (1, 2, 3)",
+			opts: aligned,
+			want: "DELETE FROM t\nWHERE id IN (1, 2, 3)",
+		},
+		{
+			// Subqueries indent one level; value lists stay on one line
+			sql:  "select * from (select id from t where x = 1) s where s.id in (select id from u)",
+			opts: aligned,
+			want: "SELECT *\nFROM (\n  SELECT id\n  FROM t\n  WHERE x = 1\n) s\nWHERE s.id IN (\n  SELECT id\n  FROM u\n)",
+		},
+		{
+			sql:  "with x as (select 1 as n) select n from x",
+			opts: aligned,
+			want: "WITH x AS (\n  SELECT 1 AS n\n)\nSELECT n\nFROM x",
+		},
+		{
+			sql:  "select a from t union all select b from u",
+			opts: aligned,
+			want: "SELECT a\nFROM t\nUNION ALL\nSELECT b\nFROM u",
+		},
+		{
+			sql:  "SELECT a FROM t WHERE b = 'it''s' GROUP BY a HAVING count(*) > 1 LIMIT 5",
+			opts: aligned,
+			want: "SELECT a\nFROM t\nWHERE b = 'it''s'\nGROUP BY a\nHAVING count(*) > 1\nLIMIT 5",
+		},
+		{
+			// Statements are separated by a blank line
+			sql:  "select 1; select 2;",
+			opts: aligned,
+			want: "SELECT 1;\n\nSELECT 2;",
+		},
+		{
+			sql:  "/* header */ select a /* inline */ from t -- trailing",
+			opts: aligned,
+			want: "/* header */\nSELECT a /* inline */\nFROM t -- trailing",
+		},
+		{
+			// A line comment ends its line; the next clause still indents
+			sql:  "select a from t where b = 1 -- note\nand c = 2",
+			opts: FormatOptions{KeywordCase: "preserve", Indent: 4},
+			want: "select a\nfrom t\nwhere b = 1 -- note\n    and c = 2",
+		},
+		{
+			sql:  "Select a, b From t Where x = 1",
+			opts: FormatOptions{KeywordCase: "preserve"},
+			want: "Select a, b\nFrom t\nWhere x = 1",
+		},
+		// Malformed input keeps its tokens rather than failing
+		{sql: "select (a from where", opts: aligned, want: "SELECT (a FROM WHERE"},
+		{sql: "select 'unterminated from t", opts: aligned, want: "SELECT 'unterminated from t"},
+		{sql: ")) select", opts: aligned, want: "))\nSELECT"},
+		{sql: "", opts: aligned, want: ""},
+	}
+	for _, tt := range tests {
+		if got := Format(tt.sql, tt.opts); got != tt.want {
+			t.Errorf("Format(%q) =\n%s\nwant\n%s", tt.sql, got, tt.want)
+		}
+	}
+}
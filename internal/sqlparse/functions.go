@@ -0,0 +1,212 @@
+package sqlparse
+
+import "strings"
+
+// FunctionDoc documents a built-in function for completion and signature help.
+type FunctionDoc struct {
+	Name    string
+	Args    []string // Parameter names; optional ones are in brackets, "..." repeats the last
+	Returns string
+	Summary string
+}
+
+// Signature formats the function as name(arg, ...).
+func (f FunctionDoc) Signature() string {
+	return f.Name + "(" + strings.Join(f.Args, ", ") + ")"
+}
+
+// ArgIndex maps the index of an argument in a call to the parameter it
+// corresponds to, or -1 if the call has more arguments than the function.
+func (f FunctionDoc) ArgIndex(arg int) int {
+	if arg < len(f.Args) {
+		return arg
+	}
+	if n := len(f.Args); n > 0 && f.Args[n-1] == "..." {
+		// Variadic: extra arguments repeat the parameter before "..."
+		return n - 2
+	}
+	return -1
+}
+
+// Doc returns the documentation of a built-in function of the dialect.
+func (d *Dialect) Doc(name string) (FunctionDoc, bool) {
+	doc, ok := d.docs[strings.ToUpper(name)]
+	return doc, ok
+}
+
+// fn builds a FunctionDoc from a comma-separated parameter list.
+func fn(name, args, returns, summary string) FunctionDoc {
+	doc := FunctionDoc{Name: name, Returns: returns, Summary: summary}
+	if args != "" {
+		for _, a := range strings.Split(args, ",") {
+			doc.Args = append(doc.Args, strings.TrimSpace(a))
+		}
+	}
+	return doc
+}
+
+// docSet indexes function docs by upper-case name; later docs override
+// earlier ones with the same name.
+func docSet(lists ...[]FunctionDoc) map[string]FunctionDoc {
+	set := make(map[string]FunctionDoc)
+	for _, list := range lists {
+		for _, doc := range list {
+			set[strings.ToUpper(doc.Name)] = doc
+		}
+	}
+	return set
+}
+
+var commonDocs = []FunctionDoc{
+	fn("count", "X", "INTEGER", "Number of rows, or of non-NULL values of X; count(*) counts all rows."),
+	fn("sum", "X", "NUMERIC", "Sum of the non-NULL values of X."),
+	fn("avg", "X", "REAL", "Average of the non-NULL values of X."),
+	fn("min", "X, ...", "ANY", "Smallest value; the aggregate form takes one argument."),
+	fn("max", "X, ...", "ANY", "Largest value; the aggregate form takes one argument."),
+	fn("coalesce", "X, Y, ...", "ANY", "First argument that is not NULL."),
+	fn("nullif", "X, Y", "ANY", "NULL if X equals Y, otherwise X."),
+	fn("cast", "X AS type", "type", "Converts X to the given type."),
+	fn("length", "X", "INTEGER", "Number of characters in a string."),
+	fn("lower", "X", "TEXT", "X converted to lower case."),
+	fn("upper", "X", "TEXT", "X converted to upper case."),
+	fn("trim", "X, [Y]", "TEXT", "X with characters in Y (default spaces) removed from both ends."),
+	fn("ltrim", "X, [Y]", "TEXT", "X with characters in Y removed from the start."),
+	fn("rtrim", "X, [Y]", "TEXT", "X with characters in Y removed from the end."),
+	fn("substr", "X, Y, [Z]", "TEXT", "Substring of X starting at character Y (1-based), Z characters long."),
+	fn("substring", "X, Y, [Z]", "TEXT", "Substring of X starting at character Y (1-based), Z characters long."),
+	fn("replace", "X, Y, Z", "TEXT", "X with every occurrence of Y replaced by Z."),
+	fn("abs", "X", "NUMERIC", "Absolute value of X."),
+	fn("round", "X, [Y]", "NUMERIC", "X rounded to Y decimal places (default 0)."),
+	fn("ceil", "X", "NUMERIC", "Smallest integer not less than X."),
+	fn("floor", "X", "NUMERIC", "Largest integer not greater than X."),
+	fn("mod", "X, Y", "NUMERIC", "Remainder of X divided by Y."),
+	fn("power", "X, Y", "REAL", "X raised to the power Y."),
+	fn("sqrt", "X", "REAL", "Square root of X."),
+	fn("ln", "X", "REAL", "Natural logarithm of X."),
+	fn("log", "[B], X", "REAL", "Logarithm of X, base 10 or B."),
+	fn("exp", "X", "REAL", "e raised to the power X."),
+	fn("sign", "X", "INTEGER", "-1, 0 or 1 according to the sign of X."),
+	fn("row_number", "", "INTEGER", "Number of the current row within its partition, from 1."),
+	fn("rank", "", "INTEGER", "Rank of the current row with gaps for ties."),
+	fn("dense_rank", "", "INTEGER", "Rank of the current row without gaps."),
+	fn("percent_rank", "", "REAL", "Relative rank of the current row: (rank - 1) / (rows - 1)."),
+	fn("cume_dist", "", "REAL", "Cumulative distribution: rows up to the current peer / total rows."),
+	fn("ntile", "N", "INTEGER", "Bucket number from 1 to N, dividing the partition evenly."),
+	fn("lag", "X, [offset], [default]", "ANY", "X evaluated at the row offset rows before the current row."),
+	fn("lead", "X, [offset], [default]", "ANY", "X evaluated at the row offset rows after the current row."),
+	fn("first_value", "X", "ANY", "X evaluated at the first row of the window frame."),
+	fn("last_value", "X", "ANY", "X evaluated at the last row of the window frame."),
+	fn("nth_value", "X, N", "ANY", "X evaluated at row N of the window frame."),
+}
+
+var sqliteDocs = []FunctionDoc{
+	fn("ifnull", "X, Y", "ANY", "X if it is not NULL, otherwise Y."),
+	fn("iif", "cond, X, Y", "ANY", "X if cond is true, otherwise Y."),
+	fn("instr", "X, Y", "INTEGER", "Position of the first Y in X, or 0 if absent."),
+	fn("printf", "format, ...", "TEXT", "String formatted like C printf."),
+	fn("format", "format, ...", "TEXT", "String formatted like C printf."),
+	fn("char", "X, ...", "TEXT", "String of the characters with the given code points."),
+	fn("unicode", "X", "INTEGER", "Code point of the first character of X."),
+	fn("hex", "X", "TEXT", "Upper-case hexadecimal rendering of X as a blob."),
+	fn("unhex", "X, [Y]", "BLOB", "Blob decoded from hexadecimal X, ignoring characters in Y."),
+	fn("quote", "X", "TEXT", "X as an SQL literal."),
+	fn("random", "", "INTEGER", "Pseudo-random 64-bit integer."),
+	fn("randomblob", "N", "BLOB", "N bytes of pseudo-random data."),
+	fn("zeroblob", "N", "BLOB", "Blob of N zero bytes."),
+	fn("typeof", "X", "TEXT", "Storage class of X: null, integer, real, text or blob."),
+	fn("glob", "pattern, X", "INTEGER", "1 if X matches the case-sensitive glob pattern."),
+	fn("concat", "X, ...", "TEXT", "Concatenation of the non-NULL arguments."),
+	fn("concat_ws", "sep, X, ...", "TEXT", "Non-NULL arguments joined with sep."),
+	fn("group_concat", "X, [sep]", "TEXT", "Non-NULL values of X joined with sep (default \",\")."),
+	fn("string_agg", "X, sep", "TEXT", "Non-NULL values of X joined with sep."),
+	fn("total", "X", "REAL", "Sum of the non-NULL values of X as a float; 0.0 for no rows."),
+	fn("date", "time, [modifier], ...", "TEXT", "Date as YYYY-MM-DD."),
+	fn("time", "time, [modifier], ...", "TEXT", "Time as HH:MM:SS."),
+	fn("datetime", "time, [modifier], ...", "TEXT", "Date and time as YYYY-MM-DD HH:MM:SS."),
+	fn("julianday", "time, [modifier], ...", "REAL", "Julian day number."),
+	fn("unixepoch", "time, [modifier], ...", "INTEGER", "Seconds since 1970-01-01 00:00:00 UTC."),
+	fn("strftime", "format, time, [modifier], ...", "TEXT", "Date and time formatted with strftime codes."),
+	fn("timediff", "A, B", "TEXT", "Time elapsed from B to A as +YYYY-MM-DD HH:MM:SS.SSS."),
+	fn("changes", "", "INTEGER", "Rows changed by the last INSERT, UPDATE or DELETE."),
+	fn("total_changes", "", "INTEGER", "Rows changed since the connection was opened."),
+	fn("last_insert_rowid", "", "INTEGER", "Rowid of the last inserted row."),
+	fn("sqlite_version", "", "TEXT", "SQLite library version."),
+	fn("json", "X", "TEXT", "X validated and minified as JSON."),
+	fn("json_array", "X, ...", "TEXT", "JSON array of the arguments."),
+	fn("json_object", "key, value, ...", "TEXT", "JSON object from key/value pairs."),
+	fn("json_extract", "json, path, ...", "ANY", "Value(s) at the given paths, e.g. '$.a[0]'."),
+	fn("json_type", "json, [path]", "TEXT", "JSON type of the value at path."),
+	fn("json_valid", "json", "INTEGER", "1 if the argument is well-formed JSON."),
+	fn("json_set", "json, path, value, ...", "TEXT", "JSON with values inserted or replaced at paths."),
+	fn("json_insert", "json, path, value, ...", "TEXT", "JSON with values inserted at paths that do not exist."),
+	fn("json_replace", "json, path, value, ...", "TEXT", "JSON with values replaced at paths that exist."),
+	fn("json_remove", "json, path, ...", "TEXT", "JSON with the values at paths removed."),
+	fn("json_patch", "target, patch", "TEXT", "target with an RFC 7396 merge patch applied."),
+	fn("json_array_length", "json, [path]", "INTEGER", "Number of elements in the array at path."),
+	fn("json_each", "json, [path]", "TABLE", "One row per element of the array or object at path."),
+	fn("json_tree", "json, [path]", "TABLE", "One row per element, walking the JSON recursively."),
+	fn("json_group_array", "X", "TEXT", "JSON array of all values of X."),
+	fn("json_group_object", "key, value", "TEXT", "JSON object of all key/value pairs."),
+	fn("trunc", "X", "NUMERIC", "X with its fractional part removed."),
+	fn("pi", "", "REAL", "The value of pi."),
+}
+
+var postgresDocs = []FunctionDoc{
+	fn("now", "", "timestamptz", "Start time of the current transaction."),
+	fn("age", "timestamp, [timestamp]", "interval", "Interval between the timestamps, or from now."),
+	fn("extract", "field FROM source", "numeric", "Field (year, month, epoch, ...) of a date/time value."),
+	fn("date_trunc", "field, source, [zone]", "timestamp", "source truncated to the given precision."),
+	fn("date_part", "field, source", "double precision", "Field of a date/time value."),
+	fn("make_date", "year, month, day", "date", "Date from its parts."),
+	fn("to_char", "value, format", "text", "Number or timestamp formatted as text."),
+	fn("to_date", "text, format", "date", "Date parsed from text."),
+	fn("to_timestamp", "text, format", "timestamptz", "Timestamp parsed from text, or from Unix epoch seconds."),
+	fn("to_number", "text, format", "numeric", "Number parsed from text."),
+	fn("concat", "X, ...", "text", "Concatenation of the non-NULL arguments."),
+	fn("concat_ws", "sep, X, ...", "text", "Non-NULL arguments joined with sep."),
+	fn("format", "format, ...", "text", "String formatted with %s, %I and %L placeholders."),
+	fn("left", "str, n", "text", "First n characters of str."),
+	fn("right", "str, n", "text", "Last n characters of str."),
+	fn("lpad", "str, length, [fill]", "text", "str padded on the left to length."),
+	fn("rpad", "str, length, [fill]", "text", "str padded on the right to length."),
+	fn("initcap", "str", "text", "str with the first letter of each word upper-cased."),
+	fn("strpos", "str, substr", "integer", "Position of substr in str, or 0."),
+	fn("split_part", "str, delimiter, n", "text", "nth field of str split on delimiter."),
+	fn("regexp_replace", "str, pattern, replacement, [flags]", "text", "str with regex matches replaced."),
+	fn("regexp_matches", "str, pattern, [flags]", "setof text[]", "Captured substrings of regex matches."),
+	fn("string_agg", "X, sep", "text", "Non-NULL values of X joined with sep."),
+	fn("array_agg", "X", "array", "Values of X collected into an array."),
+	fn("array_length", "array, dim", "integer", "Length of the given array dimension."),
+	fn("unnest", "array, ...", "setof", "One row per array element."),
+	fn("generate_series", "start, stop, [step]", "setof", "Series of values from start to stop."),
+	fn("json_agg", "X", "json", "Values of X collected into a JSON array."),
+	fn("json_build_object", "key, value, ...", "json", "JSON object from key/value pairs."),
+	fn("jsonb_agg", "X", "jsonb", "Values of X collected into a JSON array."),
+	fn("jsonb_build_object", "key, value, ...", "jsonb", "JSON object from key/value pairs."),
+	fn("jsonb_build_array", "X, ...", "jsonb", "JSON array of the arguments."),
+	fn("jsonb_object_agg", "key, value", "jsonb", "Key/value pairs collected into a JSON object."),
+	fn("jsonb_array_elements", "json", "setof jsonb", "One row per element of a JSON array."),
+	fn("jsonb_array_elements_text", "json", "setof text", "One row per element of a JSON array, as text."),
+	fn("jsonb_array_length", "json", "integer", "Number of elements in a JSON array."),
+	fn("jsonb_each", "json", "setof record", "One key/value row per member of a JSON object."),
+	fn("jsonb_object_keys", "json", "setof text", "Keys of a JSON object."),
+	fn("jsonb_extract_path", "json, path, ...", "jsonb", "Value at the path of keys."),
+	fn("jsonb_extract_path_text", "json, path, ...", "text", "Value at the path of keys, as text."),
+	fn("jsonb_set", "target, path, value, [create]", "jsonb", "target with the value at path replaced."),
+	fn("jsonb_strip_nulls", "json", "jsonb", "JSON with null object fields removed."),
+	fn("jsonb_typeof", "json", "text", "Type of the top-level JSON value."),
+	fn("jsonb_pretty", "json", "text", "JSON formatted with indentation."),
+	fn("to_jsonb", "X", "jsonb", "X converted to JSON."),
+	fn("bool_and", "X", "boolean", "True if every non-NULL value is true."),
+	fn("bool_or", "X", "boolean", "True if any non-NULL value is true."),
+	fn("greatest", "X, ...", "ANY", "Largest of the arguments, ignoring NULLs."),
+	fn("least", "X, ...", "ANY", "Smallest of the arguments, ignoring NULLs."),
+	fn("random", "", "double precision", "Random value in [0, 1)."),
+	fn("md5", "str", "text", "MD5 hash of str as hex."),
+	fn("gen_random_uuid", "", "uuid", "Random version 4 UUID."),
+	fn("nextval", "regclass", "bigint", "Advances the sequence and returns its new value."),
+	fn("currval", "regclass", "bigint", "Value most recently returned by nextval for the sequence."),
+	fn("pg_size_pretty", "bytes", "text", "Byte count in human-readable units."),
+	fn("pg_total_relation_size", "regclass", "bigint", "Disk space used by a table, its indexes and TOAST data."),
+	fn("pg_typeof", "X", "regtype", "Data type of X."),
+}
@@ -0,0 +1,272 @@
+// Package sqlparse provides a lightweight SQL lexer, statement analysis and
+// per-dialect keyword catalogs used by the editor for highlighting,
+// completion and other tooling.
+//
+// It is not a full SQL parser: it understands enough structure (statements,
+// parentheses, clauses, table references, CTEs and subqueries) to reason
+// about what is in scope at a cursor position, and it is tolerant of the
+// incomplete SQL found in an editor while typing.
+package sqlparse
+
+import (
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+// Kind is the lexical category of a token.
+type Kind int
+
+const (
+	Whitespace  Kind = iota
+	Word             // Identifier or keyword
+	QuotedIdent      // "name", `name` or [name]
+	String           // 'text'
+	Number
+	Comment // -- line or /* block */
+	Punct   // Operators and punctuation
+)
+
+// Token is a single lexical token. Pos is the byte offset of the token in the
+// source text.
+type Token struct {
+	Kind Kind
+	Text string
+	Pos  int
+	// Unterminated is set for a string, quoted identifier or block comment
+	// that runs to the end of the input.
+	Unterminated bool
+}
+
+// End returns the byte offset just past the token.
+func (t Token) End() int {
+	return t.Pos + len(t.Text)
+}
+
+// Upper returns the token text upper-cased, for keyword comparisons.
+func (t Token) Upper() string {
+	return strings.ToUpper(t.Text)
+}
+
+// IsIdent reports whether the token names something (a word or quoted identifier).
+func (t Token) IsIdent() bool {
+	return t.Kind == Word || t.Kind == QuotedIdent
+}
+
+// Ident returns the identifier the token names, without quotes.
+func (t Token) Ident() string {
+	if t.Kind != QuotedIdent || len(t.Text) == 0 {
+		return t.Text
+	}
+	s := t.Text[1:]
+	if !t.Unterminated && len(s) > 0 {
+		s = s[:len(s)-1]
+	}
+	switch t.Text[0] {
+	case '"':
+		return strings.ReplaceAll(s, `""`, `"`)
+	case '`':
+		return strings.ReplaceAll(s, "``", "`")
+	}
+	return s
+}
+
+// multiCharOps are operators lexed as a single Punct token, longest first.
+var multiCharOps = []string{"->>", "::", "<>", "!=", ">=", "<=", "||", "->", "=>"}
+
+// Tokenize splits SQL text into tokens. Every byte of text belongs to exactly
+// one token, so joining the token texts reproduces the input.
+func Tokenize(text string) []Token {
+	tokens, _ := tokenize(text, false)
+	return tokens
+}
+
+// TokenizeLine tokenizes one line of a larger text. inBlockComment reports
+// whether a /* comment was left open by the previous line; the returned flag
+// reports whether one is still open at the end of this line.
+func TokenizeLine(line string, inBlockComment bool) ([]Token, bool) {
+	return tokenize(line, inBlockComment)
+}
+
+func tokenize(text string, inBlockComment bool) ([]Token, bool) {
+	var tokens []Token
+	n := len(text)
+	i := 0
+
+	emit := func(kind Kind, start, end int, unterminated bool) {
+		tokens = append(tokens, Token{Kind: kind, Text: text[start:end], Pos: start, Unterminated: unterminated})
+		i = end
+	}
+
+	if inBlockComment {
+		end := strings.Index(text, "*/")
+		if end < 0 {
+			emit(Comment, 0, n, true)
+			return tokens, true
+		}
+		emit(Comment, 0, end+2, false)
+	}
+
+	for i < n {
+		ch := text[i]
+		switch {
+		case ch == '-' && i+1 < n && text[i+1] == '-':
+			end := strings.IndexByte(text[i:], '\n')
+			if end < 0 {
+				end = n
+			} else {
+				end += i
+			}
+			emit(Comment, i, end, false)
+
+		case ch == '/' && i+1 < n && text[i+1] == '*':
+			end := strings.Index(text[i+2:], "*/")
+			if end < 0 {
+				emit(Comment, i, n, true)
+				return tokens, true
+			}
+			emit(Comment, i, i+2+end+2, false)
+
+		case ch == '\'':
+			end, ok := scanQuoted(text, i, '\'')
+			emit(String, i, end, !ok)
+
+		case ch == '"' || ch == '`':
+			end, ok := scanQuoted(text, i, ch)
+			emit(QuotedIdent, i, end, !ok)
+
+		case ch == '[':
+			end := strings.IndexByte(text[i:], ']')
+			if end < 0 {
+				emit(QuotedIdent, i, n, true)
+			} else {
+				emit(QuotedIdent, i, i+end+1, false)
+			}
+
+		case isDigit(ch) || (ch == '.' && i+1 < n && isDigit(text[i+1])):
+			end := i
+			for end < n && (isDigit(text[end]) || text[end] == '.') {
+				end++
+			}
+			emit(Number, i, end, false)
+
+		case ch == '_' || ch >= utf8.RuneSelf || unicode.IsLetter(rune(ch)):
+			end := i
+			for end < n {
+				r, size := utf8.DecodeRuneInString(text[end:])
+				if r != '_' && r != '$' && !unicode.IsLetter(r) && !unicode.IsDigit(r) {
+					break
+				}
+				end += size
+			}
+			if end == i {
+				// Non-letter multi-byte rune: treat as punctuation
+				_, size := utf8.DecodeRuneInString(text[i:])
+				end = i + size
+				emit(Punct, i, end, false)
+				continue
+			}
+			emit(Word, i, end, false)
+
+		case ch == ' ' || ch == '\t' || ch == '\n' || ch == '\r':
+			end := i
+			for end < n && (text[end] == ' ' || text[end] == '\t' || text[end] == '\n' || text[end] == '\r') {
+				end++
+			}
+			emit(Whitespace, i, end, false)
+
+		default:
+			end := i + 1
+			for _, op := range multiCharOps {
+				if strings.HasPrefix(text[i:], op) {
+					end = i + len(op)
+					break
+				}
+			}
+			emit(Punct, i, end, false)
+		}
+	}
+	return tokens, false
+}
+
+// scanQuoted returns the end of the quoted token starting at start, treating
+// a doubled quote as an escape. ok is false if the quote is never closed.
+func scanQuoted(text string, start int, quote byte) (end int, ok bool) {
+	i := start + 1
+	for i < len(text) {
+		if text[i] == quote {
+			if i+1 < len(text) && text[i+1] == quote {
+				i += 2
+				continue
+			}
+			return i + 1, true
+		}
+		i++
+	}
+	return len(text), false
+}
+
+func isDigit(ch byte) bool {
+	return ch >= '0' && ch <= '9'
+}
+
+// Significant returns the tokens that are not whitespace or comments.
+func Significant(tokens []Token) []Token {
+	out := make([]Token, 0, len(tokens))
+	for _, t := range tokens {
+		if t.Kind != Whitespace && t.Kind != Comment {
+			out = append(out, t)
+		}
+	}
+	return out
+}
+
+// Statement is one statement of a script. Text runs from the first to the
+// last significant token, without the terminating ';', and Pos is the byte
+// offset of Text in the script.
+type Statement struct {
+	Text string
+	Pos  int
+}
+
+// Statements splits a script on top-level semicolons. Semicolons inside a
+// trigger body (BEGIN ... END) do not end the CREATE TRIGGER statement, and
+// statements that are only comments are dropped.
+func Statements(text string) []Statement {
+	var out []Statement
+	var stmt []Token
+	trigger, depth := false, 0
+
+	flush := func() {
+		if len(stmt) > 0 {
+			first, last := stmt[0], stmt[len(stmt)-1]
+			out = append(out, Statement{Text: text[first.Pos:last.End()], Pos: first.Pos})
+		}
+		stmt = nil
+		trigger, depth = false, 0
+	}
+
+	for _, t := range Significant(Tokenize(text)) {
+		if t.Kind == Punct && t.Text == ";" && depth == 0 {
+			flush()
+			continue
+		}
+		if t.Kind == Word {
+			switch t.Upper() {
+			case "TRIGGER":
+				trigger = trigger || (len(stmt) > 0 && stmt[0].Upper() == "CREATE")
+			case "BEGIN", "CASE":
+				if trigger {
+					depth++
+				}
+			case "END":
+				if depth > 0 {
+					depth--
+				}
+			}
+		}
+		stmt = append(stmt, t)
+	}
+	flush()
+	return out
+}
@@ -0,0 +1,106 @@
+package sqlparse
+
+// writeKeywords start a statement that changes data.
+var writeKeywords = map[string]bool{
+	"INSERT": true, "UPDATE": true, "DELETE": true, "REPLACE": true, "MERGE": true, "UPSERT": true,
+}
+
+// ReadOnly reports whether every statement of a script only reads data:
+// SELECT, VALUES, WITH whose main statement and CTEs only select, and
+// EXPLAIN. EXPLAIN ANALYZE runs what it explains, so it reads only if that
+// does. SELECT ... INTO creates a table and is not read-only. The check is
+// lexical and errs towards calling a statement a write.
+func ReadOnly(script string) bool {
+	for _, stmt := range Statements(script) {
+		if !readsOnly(Significant(Tokenize(stmt.Text))) {
+			return false
+		}
+	}
+	return true
+}
+
+func readsOnly(tokens []Token) bool {
+	i := 0
+	for i < len(tokens) && tokens[i].Text == "(" {
+		i++
+	}
+	if i >= len(tokens) {
+		return true
+	}
+	if tokens[i].Kind != Word {
+		return false
+	}
+	switch tokens[i].Upper() {
+	case "EXPLAIN":
+		return !explainRuns(tokens[i+1:]) || readsOnly(explained(tokens[i+1:]))
+	case "SELECT", "VALUES", "WITH":
+	default:
+		return false
+	}
+
+	// Statements nested in parentheses, and the main statement after a
+	// CTE list, follow "(" or ")". A keyword followed by "(" is a function
+	// such as replace().
+	for j := i + 1; j < len(tokens); j++ {
+		t := tokens[j]
+		if t.Kind != Word {
+			continue
+		}
+		word := t.Upper()
+		if word == "INTO" {
+			return false
+		}
+		prev := tokens[j-1].Text
+		call := j+1 < len(tokens) && tokens[j+1].Text == "("
+		if writeKeywords[word] && (prev == "(" || prev == ")") && !call {
+			return false
+		}
+	}
+	return true
+}
+
+// explainRuns reports whether the options after EXPLAIN include ANALYZE,
+// which executes the statement.
+func explainRuns(tokens []Token) bool {
+	for _, t := range explainOptions(tokens) {
+		if t.Kind == Word && (t.Upper() == "ANALYZE" || t.Upper() == "ANALYSE") {
+			return true
+		}
+	}
+	return false
+}
+
+// explained returns the statement an EXPLAIN is about.
+func explained(tokens []Token) []Token {
+	return tokens[len(explainOptions(tokens)):]
+}
+
+// explainOptions returns the leading EXPLAIN options: QUERY PLAN, ANALYZE,
+// VERBOSE and the like, or a parenthesized option list.
+func explainOptions(tokens []Token) []Token {
+	if len(tokens) > 0 && tokens[0].Text == "(" {
+		depth := 0
+		for i, t := range tokens {
+			switch t.Text {
+			case "(":
+				depth++
+			case ")":
+				depth--
+				if depth == 0 {
+					return tokens[:i+1]
+				}
+			}
+		}
+		return tokens
+	}
+	i := 0
+	for i < len(tokens) && tokens[i].Kind == Word {
+		switch tokens[i].Upper() {
+		case "QUERY", "PLAN", "ANALYZE", "ANALYSE", "VERBOSE":
+			i++
+			continue
+		}
+		break
+	}
+	return tokens[:i]
+}
@@ -0,0 +1,35 @@
+package sqlparse
+
+import "testing"
+
+func TestReadOnly(t *testing.T) {
+	tests := []struct {
+		sql  string
+		want bool
+	}{
+		{"SELECT * FROM t", true},
+		{"select replace(name, 'a', 'b') from t; values (1)", true},
+		{"WITH x AS (SELECT 1) SELECT * FROM x", true},
+		{"(SELECT 1) UNION (SELECT 2)", true},
+		{"EXPLAIN QUERY PLAN DELETE FROM t", true},
+		{"EXPLAIN ANALYZE SELECT 1", true},
+		{"-- just a comment", true},
+
+		// The main statement after a CTE list writes
+		{"WITH x AS (SELECT 1) DELETE FROM t", false},
+		// So does a writable CTE
+		{"WITH d AS (DELETE FROM t RETURNING *) SELECT * FROM d", false},
+		// A read followed by a write
+		{"SELECT 1; DELETE FROM t", false},
+		{"SELECT * INTO copy FROM t", false},
+		{"EXPLAIN ANALYZE DELETE FROM t", false},
+		{"EXPLAIN (ANALYZE, BUFFERS) UPDATE t SET a = 1", false},
+		{"PRAGMA journal_mode = DELETE", false},
+		{"INSERT INTO t SELECT 1", false},
+	}
+	for _, tt := range tests {
+		if got := ReadOnly(tt.sql); got != tt.want {
+			t.Errorf("ReadOnly(%q) = %v, want %v", tt.sql, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,641 @@
+package sqlparse
+
+import "strings"
+
+// TableRef is a table made available by a FROM, JOIN, UPDATE or INTO clause,
+// or defined by a WITH clause.
+type TableRef struct {
+	Name  string // Table or CTE name; empty for a subquery
+	Alias string
+	// Columns lists the output columns of a subquery or CTE. It is nil for
+	// base tables, whose columns come from the schema.
+	Columns []string
+	// Star holds the tables a "*" in a subquery or CTE select list expands to.
+	Star []TableRef
+	// Derived is set for subqueries and CTEs.
+	Derived bool
+	// Pos is the byte offset of the reference in the analyzed text.
+	Pos int
+
+	part int // Compound (UNION) part of the query the ref belongs to
+}
+
+// RefName returns the name the table is referred to by in the query.
+func (r TableRef) RefName() string {
+	if r.Alias != "" {
+		return r.Alias
+	}
+	return r.Name
+}
+
+// Context describes what surrounds a cursor position in SQL text.
+type Context struct {
+	Word      string // Partial identifier before the cursor
+	WordStart int    // Byte offset where Word starts
+	Qualifier string // "u" when completing "u.na"
+
+	// Clause is the clause the cursor is in: SELECT, FROM, JOIN, ON, USING,
+	// WHERE, GROUP BY, ORDER BY, HAVING, SET, UPDATE, INTO, VALUES,
+	// RETURNING, LIMIT, OFFSET, PRAGMA, COLUMNS (an INSERT column list) or "".
+	Clause string
+	// ExpectTable is set where a table name is expected, e.g. right after
+	// FROM or JOIN.
+	ExpectTable bool
+	// Cast is set when the word follows a "::" type cast.
+	Cast bool
+	// Call names the function whose argument list the cursor is in, and
+	// CallArg is the index of the argument at the cursor.
+	Call    string
+	CallArg int
+	// Join is the table joined by the JOIN whose ON condition the cursor is
+	// in, or nil.
+	Join *TableRef
+
+	// Tables are the tables in scope at the cursor, innermost query first.
+	// References to CTEs are resolved to the CTE's columns.
+	Tables []TableRef
+	// CTEs are the common table expressions visible at the cursor.
+	CTEs []TableRef
+
+	InString  bool
+	InComment bool
+}
+
+// Lookup finds a table in scope by alias or name, case-insensitively.
+func (c Context) Lookup(name string) (TableRef, bool) {
+	for _, r := range c.Tables {
+		if strings.EqualFold(r.Alias, name) {
+			return r, true
+		}
+	}
+	for _, r := range c.Tables {
+		if r.Alias == "" && strings.EqualFold(r.Name, name) {
+			return r, true
+		}
+	}
+	for _, r := range c.CTEs {
+		if strings.EqualFold(r.Name, name) {
+			return r, true
+		}
+	}
+	return TableRef{}, false
+}
+
+// Analyze parses the statement containing cursor (a byte offset into text)
+// and describes the cursor's context.
+func Analyze(text string, cursor int) Context {
+	if cursor > len(text) {
+		cursor = len(text)
+	}
+	if cursor < 0 {
+		cursor = 0
+	}
+	tokens := Tokenize(text)
+	ctx := Context{WordStart: cursor}
+
+	for _, t := range tokens {
+		if t.Pos >= cursor || t.End() < cursor {
+			continue
+		}
+		switch t.Kind {
+		case String, QuotedIdent:
+			ctx.InString = t.End() > cursor || t.Unterminated
+		case Comment:
+			lineComment := strings.HasPrefix(t.Text, "--")
+			ctx.InComment = t.End() > cursor || t.Unterminated || lineComment
+		case Word:
+			ctx.Word = text[t.Pos:cursor]
+			ctx.WordStart = t.Pos
+		}
+	}
+	if ctx.InString || ctx.InComment {
+		return ctx
+	}
+
+	sig := Significant(tokens)
+	start, end := 0, len(sig)
+	for i, t := range sig {
+		if t.Kind != Punct || t.Text != ";" {
+			continue
+		}
+		if t.End() <= cursor {
+			start = i + 1
+		} else {
+			end = i
+			break
+		}
+	}
+	stmt := sig[start:end]
+
+	// A qualifier is "ident ." right before the word being completed
+	for i := len(stmt) - 1; i >= 1; i-- {
+		if stmt[i].Pos >= ctx.WordStart {
+			continue
+		}
+		if stmt[i].Text == "." && stmt[i].End() == ctx.WordStart && stmt[i-1].IsIdent() {
+			ctx.Qualifier = stmt[i-1].Ident()
+		}
+		break
+	}
+
+	for i := len(stmt) - 1; i >= 0; i-- {
+		if stmt[i].Pos < ctx.WordStart {
+			ctx.Cast = stmt[i].Text == "::"
+			break
+		}
+	}
+
+	ctx.Call, ctx.CallArg = callAt(stmt, cursor)
+
+	analyzeLevel(stmt, cursor, nil, nil, &ctx)
+	for i := range ctx.Tables {
+		ctx.Tables[i] = resolveCTE(ctx.Tables[i], ctx.CTEs, 0)
+	}
+	return ctx
+}
+
+// analyzeLevel fills ctx for the query level toks, descending into the
+// subquery containing the cursor if there is one. outer holds the tables of
+// enclosing queries, which remain visible to correlated subqueries.
+func analyzeLevel(toks []Token, cursor int, outer, ctes []TableRef, ctx *Context) {
+	q := parseQuery(toks, cursor)
+	ctes = append(append([]TableRef{}, q.ctes...), ctes...)
+
+	var tables []TableRef
+	for _, r := range q.refs {
+		if r.part == q.cursorPart {
+			tables = append(tables, r)
+		}
+	}
+	tables = append(tables, outer...)
+
+	ctx.Clause = q.clauseAt
+	ctx.ExpectTable = q.expectAt
+	ctx.Join = nil
+	if q.clauseAt == "ON" && q.joinAt >= 0 {
+		join := q.refs[q.joinAt]
+		ctx.Join = &join
+	}
+	ctx.Tables = tables
+	ctx.CTEs = ctes
+
+	g := q.cursorGroup
+	if g == nil {
+		return
+	}
+	inner := toks[g.open+1 : g.close]
+	switch {
+	case g.subquery:
+		analyzeLevel(inner, cursor, tables, ctes, ctx)
+	case g.columnsOf != nil:
+		ctx.Clause = "COLUMNS"
+		ctx.ExpectTable = false
+		ctx.Tables = []TableRef{*g.columnsOf}
+	default:
+		ctx.ExpectTable = false
+		if open, close, ok := findSubquery(inner, cursor); ok {
+			analyzeLevel(inner[open+1:close], cursor, tables, ctes, ctx)
+		}
+	}
+}
+
+// resolveCTE gives a reference to a CTE the CTE's columns.
+func resolveCTE(r TableRef, ctes []TableRef, depth int) TableRef {
+	if depth > 8 {
+		return r
+	}
+	if !r.Derived {
+		for _, c := range ctes {
+			if strings.EqualFold(c.Name, r.Name) {
+				r.Columns = c.Columns
+				r.Star = c.Star
+				r.Derived = true
+				break
+			}
+		}
+	}
+	if len(r.Star) > 0 {
+		star := make([]TableRef, len(r.Star))
+		for i, s := range r.Star {
+			star[i] = resolveCTE(s, ctes, depth+1)
+		}
+		r.Star = star
+	}
+	return r
+}
+
+// cursorGroup is the parenthesized group of a query level containing the cursor.
+type cursorGroup struct {
+	open, close int
+	subquery    bool
+	columnsOf   *TableRef // Table whose INSERT column list the group is
+}
+
+// query is the result of scanning one query level.
+type query struct {
+	ctes []TableRef
+	refs []TableRef
+
+	// Output column names of the first SELECT, and the tables "*" expands to
+	columns []string
+	star    []TableRef
+
+	clauseAt    string
+	expectAt    bool
+	joinAt      int // Index in refs of the table joined by the ON clause at the cursor
+	cursorPart  int
+	cursorGroup *cursorGroup
+}
+
+// reservedAfterTable are words that can follow a table reference and so
+// cannot be an implicit alias.
+var reservedAfterTable = map[string]bool{
+	"WHERE": true, "JOIN": true, "ON": true, "USING": true, "LEFT": true, "RIGHT": true,
+	"INNER": true, "OUTER": true, "FULL": true, "CROSS": true, "NATURAL": true,
+	"GROUP": true, "ORDER": true, "HAVING": true, "LIMIT": true, "OFFSET": true,
+	"UNION": true, "INTERSECT": true, "EXCEPT": true, "SET": true, "VALUES": true,
+	"SELECT": true, "WINDOW": true, "RETURNING": true, "AS": true, "AND": true,
+	"OR": true, "NOT": true, "LATERAL": true, "WITH": true, "DEFAULT": true,
+	"FROM": true, "INTO": true, "STRAIGHT_JOIN": true, "FETCH": true, "FOR": true,
+}
+
+// parseQuery scans one query level. When cursor is inside toks, the clause
+// state at the cursor is recorded; pass -1 when the cursor does not matter.
+func parseQuery(toks []Token, cursor int) *query {
+	q := &query{joinAt: -1}
+	n := len(toks)
+	i := 0
+	recorded := false
+
+	// WITH [RECURSIVE] name [(cols)] AS [NOT] [MATERIALIZED] (subquery), ...
+	if i < n && toks[i].Upper() == "WITH" {
+		i++
+		if i < n && toks[i].Upper() == "RECURSIVE" {
+			i++
+		}
+		for i < n && toks[i].IsIdent() {
+			cte := TableRef{Name: toks[i].Ident(), Derived: true, Pos: toks[i].Pos}
+			i++
+			var explicit []string
+			if i < n && toks[i].Text == "(" {
+				close := matchParen(toks, i)
+				for _, t := range toks[i+1 : close] {
+					if t.IsIdent() {
+						explicit = append(explicit, t.Ident())
+					}
+				}
+				i = close + 1
+			}
+			if i < n && toks[i].Upper() == "AS" {
+				i++
+			}
+			for i < n && (toks[i].Upper() == "NOT" || toks[i].Upper() == "MATERIALIZED") {
+				i++
+			}
+			if i < n && toks[i].Text == "(" {
+				close := matchParen(toks, i)
+				if cursor >= 0 && !recorded && containsCursor(toks, i, close, cursor) {
+					q.cursorGroup = &cursorGroup{open: i, close: close, subquery: true}
+					recorded = true
+				}
+				sub := parseQuery(toks[i+1:close], -1)
+				cte.Columns, cte.Star = sub.columns, sub.star
+				i = close + 1
+			}
+			if explicit != nil {
+				cte.Columns, cte.Star = explicit, nil
+			}
+			q.ctes = append(q.ctes, cte)
+			if i < n && toks[i].Text == "," {
+				i++
+				continue
+			}
+			break
+		}
+	}
+
+	clause := ""
+	expect := false
+	last := -1 // Index in q.refs of the ref that may still take an alias
+	part := 0
+	selectStart, selectEnd := -1, -1
+	var starQualifiers []string
+	onRef := -1 // Table joined by the current ON clause
+
+	record := func(expect bool) {
+		q.clauseAt, q.expectAt, q.cursorPart = clause, expect, part
+		if clause == "ON" {
+			q.joinAt = onRef
+		}
+		recorded = true
+	}
+
+	for i < n {
+		t := toks[i]
+		if cursor >= 0 && !recorded && t.End() >= cursor {
+			record(expect)
+		}
+
+		if t.Kind == Punct {
+			switch t.Text {
+			case "(":
+				close := matchParen(toks, i)
+				sub := isSubquery(toks, i, close)
+				inside := cursor >= 0 && containsCursor(toks, i, close, cursor)
+				switch {
+				case expect && sub:
+					s := parseQuery(toks[i+1:close], -1)
+					q.refs = append(q.refs, TableRef{Derived: true, Columns: s.columns, Star: s.star, Pos: t.Pos, part: part})
+					last = len(q.refs) - 1
+					expect = false
+				case clause == "INTO" && last >= 0 && q.refs[last].Alias == "" && !sub:
+					if inside {
+						ref := q.refs[last]
+						q.cursorGroup = &cursorGroup{open: i, close: close, columnsOf: &ref}
+					}
+					last = -1
+				default:
+					if clause != "FROM" && clause != "JOIN" {
+						last = -1
+					}
+				}
+				if inside && q.cursorGroup == nil {
+					q.cursorGroup = &cursorGroup{open: i, close: close, subquery: sub}
+				}
+				if inside {
+					record(false)
+				}
+				i = close + 1
+				continue
+			case ",":
+				if clause == "FROM" {
+					expect = true
+				}
+				last = -1
+			case "*":
+				if clause == "SELECT" && part == 0 && selectEnd < 0 {
+					qual := ""
+					if i >= 2 && toks[i-1].Text == "." && toks[i-2].IsIdent() {
+						qual = toks[i-2].Ident()
+					}
+					starQualifiers = append(starQualifiers, qual)
+				}
+			}
+			i++
+			continue
+		}
+
+		up := t.Upper()
+		isKeyword := t.Kind == Word
+		switch {
+		case isKeyword && up == "SELECT":
+			clause, expect, last = "SELECT", false, -1
+			if part == 0 && selectStart < 0 {
+				selectStart = i + 1
+			}
+		case isKeyword && up == "FROM":
+			if part == 0 && selectStart >= 0 && selectEnd < 0 {
+				selectEnd = i
+			}
+			clause, expect, last = "FROM", true, -1
+		case isKeyword && (up == "JOIN" || up == "STRAIGHT_JOIN"):
+			clause, expect, last = "JOIN", true, -1
+		case isKeyword && (up == "LEFT" || up == "RIGHT" || up == "INNER" || up == "OUTER" ||
+			up == "FULL" || up == "CROSS" || up == "NATURAL"):
+			last = -1
+		case isKeyword && (up == "GROUP" || up == "ORDER" || up == "PARTITION") && i+1 < n && toks[i+1].Upper() == "BY":
+			if up != "PARTITION" {
+				clause = up + " BY"
+			}
+			expect, last = false, -1
+			i++
+		case isKeyword && (up == "ON" || up == "USING" || up == "WHERE" || up == "HAVING" ||
+			up == "LIMIT" || up == "OFFSET" || up == "RETURNING" || up == "WINDOW" ||
+			up == "VALUES" || up == "SET" || up == "PRAGMA"):
+			if part == 0 && selectStart >= 0 && selectEnd < 0 {
+				selectEnd = i
+			}
+			if up == "ON" && len(q.refs) > 0 && q.refs[len(q.refs)-1].part == part {
+				onRef = len(q.refs) - 1
+			}
+			clause, expect, last = up, false, -1
+		case isKeyword && (up == "UPDATE" || up == "INTO"):
+			clause, expect, last = up, true, -1
+		case isKeyword && (up == "UNION" || up == "INTERSECT" || up == "EXCEPT"):
+			if part == 0 && selectStart >= 0 && selectEnd < 0 {
+				selectEnd = i
+			}
+			part++
+			clause, expect, last = "", false, -1
+		case isKeyword && (up == "LATERAL" || up == "ONLY") && expect:
+			// FROM LATERAL (...), FROM ONLY t
+		case expect && t.IsIdent():
+			name := t.Ident()
+			// schema.table: keep the table name
+			for i+2 < n && toks[i+1].Text == "." && toks[i+2].IsIdent() {
+				i += 2
+				name = toks[i].Ident()
+			}
+			q.refs = append(q.refs, TableRef{Name: name, Pos: t.Pos, part: part})
+			last = len(q.refs) - 1
+			expect = false
+		case last >= 0 && isKeyword && up == "AS":
+			if i+1 < n && toks[i+1].IsIdent() {
+				if cursor >= 0 && !recorded && toks[i+1].End() >= cursor {
+					record(false)
+				}
+				q.refs[last].Alias = toks[i+1].Ident()
+				i++
+			}
+			last = -1
+		case last >= 0 && q.refs[last].Alias == "" && t.IsIdent() && !(isKeyword && reservedAfterTable[up]):
+			q.refs[last].Alias = t.Ident()
+			last = -1
+		default:
+			if t.IsIdent() && last >= 0 {
+				last = -1
+			}
+		}
+		i++
+	}
+
+	if cursor >= 0 && !recorded {
+		record(expect)
+	}
+
+	if selectStart >= 0 {
+		if selectEnd < 0 {
+			selectEnd = n
+		}
+		q.columns = selectListColumns(toks[selectStart:selectEnd])
+		for _, qual := range starQualifiers {
+			for _, r := range q.refs {
+				if r.part != 0 {
+					continue
+				}
+				if qual == "" || strings.EqualFold(r.RefName(), qual) {
+					q.star = append(q.star, r)
+				}
+			}
+		}
+	}
+	return q
+}
+
+// selectListColumns returns the output column names of a select list.
+func selectListColumns(toks []Token) []string {
+	if len(toks) > 0 && (toks[0].Upper() == "DISTINCT" || toks[0].Upper() == "ALL") {
+		toks = toks[1:]
+	}
+	var cols []string
+	start := 0
+	for i := 0; i <= len(toks); i++ {
+		if i < len(toks) && toks[i].Text == "(" {
+			i = matchParen(toks, i)
+			continue
+		}
+		if i < len(toks) && toks[i].Text != "," {
+			continue
+		}
+		if name := selectItemName(toks[start:i]); name != "" {
+			cols = append(cols, name)
+		}
+		start = i + 1
+	}
+	return cols
+}
+
+// selectItemName returns the output name of one select list item, or "" if
+// it has none (e.g. "*" or an unaliased expression).
+func selectItemName(item []Token) string {
+	k := len(item)
+	if k == 0 || !item[k-1].IsIdent() {
+		return ""
+	}
+	lastTok := item[k-1]
+	if k == 1 {
+		return lastTok.Ident()
+	}
+	prev := item[k-2]
+	switch {
+	case prev.Upper() == "AS":
+		return lastTok.Ident()
+	case prev.Text == ".":
+		if k == 3 || (k == 5 && item[1].Text == ".") {
+			return lastTok.Ident()
+		}
+		return ""
+	case prev.IsIdent() || prev.Kind == Number || prev.Kind == String || prev.Text == ")":
+		// Implicit alias: "count(*) n", "t.col c"
+		if lastTok.Kind == Word && reservedAfterTable[lastTok.Upper()] {
+			return ""
+		}
+		return lastTok.Ident()
+	}
+	return ""
+}
+
+// callAt finds the innermost function call whose parentheses contain cursor
+// and the index of the argument at the cursor.
+func callAt(toks []Token, cursor int) (string, int) {
+	var open []int // Indexes of unclosed "(" before the cursor
+	for i, t := range toks {
+		if t.Pos >= cursor {
+			break
+		}
+		switch t.Text {
+		case "(":
+			open = append(open, i)
+		case ")":
+			if len(open) > 0 {
+				open = open[:len(open)-1]
+			}
+		}
+	}
+	if len(open) == 0 {
+		return "", 0
+	}
+	p := open[len(open)-1]
+	if p == 0 || toks[p-1].Kind != Word || isSubquery(toks, p, len(toks)) {
+		return "", 0
+	}
+
+	arg, depth := 0, 0
+	for _, t := range toks[p+1:] {
+		if t.Pos >= cursor {
+			break
+		}
+		switch t.Text {
+		case "(":
+			depth++
+		case ")":
+			depth--
+		case ",":
+			if depth == 0 {
+				arg++
+			}
+		}
+	}
+	return toks[p-1].Text, arg
+}
+
+// matchParen returns the index of the ")" closing the "(" at open, or
+// len(toks) if it is never closed.
+func matchParen(toks []Token, open int) int {
+	depth := 0
+	for i := open; i < len(toks); i++ {
+		switch toks[i].Text {
+		case "(":
+			depth++
+		case ")":
+			depth--
+			if depth == 0 {
+				return i
+			}
+		}
+	}
+	return len(toks)
+}
+
+// isSubquery reports whether the group opened at open holds a query.
+func isSubquery(toks []Token, open, close int) bool {
+	if open+1 >= close || open+1 >= len(toks) {
+		return false
+	}
+	switch toks[open+1].Upper() {
+	case "SELECT", "WITH", "VALUES":
+		return true
+	}
+	return false
+}
+
+// containsCursor reports whether cursor is between the parens of a group.
+// An unclosed group extends to the end of the text.
+func containsCursor(toks []Token, open, close, cursor int) bool {
+	if cursor < toks[open].End() {
+		return false
+	}
+	return close >= len(toks) || cursor <= toks[close].Pos
+}
+
+// findSubquery finds the innermost subquery group containing cursor, looking
+// through parentheses that are not subqueries (function calls, IN lists).
+func findSubquery(toks []Token, cursor int) (open, close int, ok bool) {
+	for i := 0; i < len(toks); i++ {
+		if toks[i].Text != "(" {
+			continue
+		}
+		c := matchParen(toks, i)
+		if containsCursor(toks, i, c, cursor) {
+			if isSubquery(toks, i, c) {
+				return i, c, true
+			}
+			if o, cl, found := findSubquery(toks[i+1:c], cursor); found {
+				return i + 1 + o, i + 1 + cl, true
+			}
+			return 0, 0, false
+		}
+		i = c
+	}
+	return 0, 0, false
+}
@@ -0,0 +1,201 @@
+package sqlparse
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+// analyzeAt analyzes sql with the cursor at the "|" marker.
+func analyzeAt(sql string) Context {
+	cursor := strings.Index(sql, "|")
+	return Analyze(strings.Replace(sql, "|", "", 1), cursor)
+}
+
+func refNames(refs []TableRef) []string {
+	var names []string
+	for _, r := range refs {
+		names = append(names, r.Name+":"+r.Alias)
+	}
+	return names
+}
+
+func TestAnalyze_clause(t *testing.T) {
+	tests := []struct {
+		sql         string
+		clause      string
+		expectTable bool
+		word        string
+		qualifier   string
+	}{
+		{sql: "SELECT | FROM users", clause: "SELECT"},
+		{sql: "SELECT * FROM |", clause: "FROM", expectTable: true},
+		{sql: "SELECT * FROM us|", clause: "FROM", expectTable: true, word: "us"},
+		{sql: "SELECT * FROM users u JOIN o|", clause: "JOIN", expectTable: true, word: "o"},
+		{sql: "SELECT * FROM users u WHERE u.na|", clause: "WHERE", word: "na", qualifier: "u"},
+		{sql: "SELECT * FROM users GROUP BY |", clause: "GROUP BY"},
+		{sql: "SELECT * FROM users WHERE id IN (SELECT user_id FROM |)", clause: "FROM", expectTable: true},
+		{sql: "SELECT count(|) FROM users", clause: "SELECT"},
+		{sql: "INSERT INTO users (na|) VALUES (1)", clause: "COLUMNS", word: "na"},
+		{sql: "UPDATE users SET |", clause: "SET"},
+		{sql: "SELECT 1; SELECT * FROM |", clause: "FROM", expectTable: true},
+	}
+	for _, tt := range tests {
+		ctx := analyzeAt(tt.sql)
+		if ctx.Clause != tt.clause || ctx.ExpectTable != tt.expectTable || ctx.Word != tt.word || ctx.Qualifier != tt.qualifier {
+			t.Errorf("%q: got clause=%q expectTable=%v word=%q qualifier=%q, want %q %v %q %q",
+				tt.sql, ctx.Clause, ctx.ExpectTable, ctx.Word, ctx.Qualifier,
+				tt.clause, tt.expectTable, tt.word, tt.qualifier)
+		}
+	}
+}
+
+func TestAnalyze_stringsAndComments(t *testing.T) {
+	if ctx := analyzeAt("SELECT * FROM users WHERE name = 'ab|"); !ctx.InString {
+		t.Error("expected cursor in string")
+	}
+	if ctx := analyzeAt("SELECT 'a' |"); ctx.InString {
+		t.Error("cursor after closed string reported as in string")
+	}
+	if ctx := analyzeAt("SELECT 1 -- note |"); !ctx.InComment {
+		t.Error("expected cursor in comment")
+	}
+}
+
+func TestAnalyze_tables(t *testing.T) {
+	tests := []struct {
+		sql  string
+		want []string
+	}{
+		{
+			sql:  "SELECT | FROM users u JOIN orders AS o ON o.user_id = u.id",
+			want: []string{"users:u", "orders:o"},
+		},
+		{
+			sql:  "SELECT | FROM main.users, posts p",
+			want: []string{"users:", "posts:p"},
+		},
+		{
+			// Subqueries see their own tables first, then the outer query's
+			sql:  "SELECT * FROM users u WHERE EXISTS (SELECT 1 FROM orders o WHERE o.user_id = |)",
+			want: []string{"orders:o", "users:u"},
+		},
+		{
+			// Only the UNION part containing the cursor is in scope
+			sql:  "SELECT id FROM users UNION SELECT | FROM posts",
+			want: []string{"posts:"},
+		},
+		{
+			sql:  "SELECT * FROM users u LEFT OUTER JOIN orders o ON u.id = o.user_id WHERE |",
+			want: []string{"users:u", "orders:o"},
+		},
+	}
+	for _, tt := range tests {
+		ctx := analyzeAt(tt.sql)
+		if got := refNames(ctx.Tables); !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("%q: tables = %v, want %v", tt.sql, got, tt.want)
+		}
+	}
+}
+
+func TestAnalyze_join(t *testing.T) {
+	ctx := analyzeAt("SELECT * FROM users u JOIN orders o ON |")
+	if ctx.Join == nil || ctx.Join.Name != "orders" || ctx.Join.Alias != "o" {
+		t.Errorf("join = %+v, want orders o", ctx.Join)
+	}
+	if ctx := analyzeAt("SELECT * FROM users u JOIN orders o ON o.user_id = u.id WHERE |"); ctx.Join != nil {
+		t.Errorf("join = %+v outside ON clause, want nil", ctx.Join)
+	}
+}
+
+func TestAnalyze_call(t *testing.T) {
+	tests := []struct {
+		sql  string
+		call string
+		arg  int
+	}{
+		{sql: "SELECT substr(|", call: "substr", arg: 0},
+		{sql: "SELECT substr(name, 2, |)", call: "substr", arg: 2},
+		{sql: "SELECT substr(coalesce(a, b), |", call: "substr", arg: 1},
+		{sql: "SELECT coalesce(a, b|) FROM t", call: "coalesce", arg: 1},
+		{sql: "SELECT substr(name, 1) |", call: "", arg: 0},
+		{sql: "SELECT * FROM t WHERE id IN (SELECT |", call: "", arg: 0},
+	}
+	for _, tt := range tests {
+		ctx := analyzeAt(tt.sql)
+		if ctx.Call != tt.call || ctx.CallArg != tt.arg {
+			t.Errorf("%q: call = %q arg %d, want %q arg %d", tt.sql, ctx.Call, ctx.CallArg, tt.call, tt.arg)
+		}
+	}
+
+	doc, ok := SQLite.Doc("SUBSTR")
+	if !ok || doc.Signature() != "substr(X, Y, [Z])" {
+		t.Errorf("substr doc = %+v", doc)
+	}
+	if coalesce, _ := SQLite.Doc("coalesce"); coalesce.ArgIndex(5) != 1 {
+		t.Errorf("variadic ArgIndex(5) = %d, want 1", coalesce.ArgIndex(5))
+	}
+}
+
+func TestAnalyze_derivedTables(t *testing.T) {
+	ctx := analyzeAt(`WITH recent (uid, total) AS (SELECT user_id, sum(amount) FROM orders GROUP BY 1),
+		active AS (SELECT u.*, 1 AS flag FROM users u)
+		SELECT | FROM recent r JOIN active a ON a.id = r.uid
+		JOIN (SELECT id, name AS label, count(*) n FROM tags GROUP BY id) t ON t.id = a.id`)
+
+	r, ok := ctx.Lookup("r")
+	if !ok || !r.Derived || !reflect.DeepEqual(r.Columns, []string{"uid", "total"}) {
+		t.Errorf("r = %+v, want CTE columns [uid total]", r)
+	}
+
+	a, ok := ctx.Lookup("a")
+	if !ok || !reflect.DeepEqual(a.Columns, []string{"flag"}) || len(a.Star) != 1 || a.Star[0].Name != "users" {
+		t.Errorf("a = %+v, want columns [flag] and star over users", a)
+	}
+
+	sub, ok := ctx.Lookup("t")
+	if !ok || !reflect.DeepEqual(sub.Columns, []string{"id", "label", "n"}) {
+		t.Errorf("t = %+v, want columns [id label n]", sub)
+	}
+
+	if _, ok := ctx.Lookup("recent"); !ok {
+		t.Error("CTE recent should be visible by name")
+	}
+}
+
+func TestTokenize_roundTrip(t *testing.T) {
+	sql := "SELECT \"a\"\"b\", 'it''s', x::int, [c d] -- end\n/* open"
+	var b strings.Builder
+	tokens := Tokenize(sql)
+	for _, tok := range tokens {
+		b.WriteString(tok.Text)
+	}
+	if b.String() != sql {
+		t.Errorf("round trip = %q, want %q", b.String(), sql)
+	}
+	if got := tokens[2].Ident(); got != `a"b` {
+		t.Errorf("quoted ident = %q, want %q", got, `a"b`)
+	}
+	if last := tokens[len(tokens)-1]; last.Kind != Comment || !last.Unterminated {
+		t.Errorf("last token = %+v, want unterminated comment", last)
+	}
+}
+
+func TestStatements(t *testing.T) {
+	sql := "SELECT 1; -- only a comment\n;\nCREATE TRIGGER t AFTER INSERT ON a BEGIN\n  UPDATE b SET n = CASE WHEN 1 THEN 2 END;\nEND;\n  SELECT 'a;b'"
+	var got []string
+	for _, s := range Statements(sql) {
+		if sql[s.Pos:s.Pos+len(s.Text)] != s.Text {
+			t.Errorf("statement %q does not match its position %d", s.Text, s.Pos)
+		}
+		got = append(got, s.Text)
+	}
+	want := []string{
+		"SELECT 1",
+		"CREATE TRIGGER t AFTER INSERT ON a BEGIN\n  UPDATE b SET n = CASE WHEN 1 THEN 2 END;\nEND",
+		"SELECT 'a;b'",
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("Statements = %q, want %q", got, want)
+	}
+}
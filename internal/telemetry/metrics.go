@@ -155,21 +155,25 @@ func Histogram(values []float64, buckets int) ([]float64, []int) {
 		return []float64{min, min}, []int{len(values)}
 	}
 
-	// Create bucket boundaries
-	bucketWidth := (max - min) / float64(buckets)
+	// Create bucket boundaries. Work in halves so max-min cannot overflow
+	// for values near the float limits; halving is exact.
+	halfWidth := (max/2 - min/2) / float64(buckets)
 	boundaries := make([]float64, buckets+1)
 	counts := make([]int, buckets)
 
 	for i := 0; i <= buckets; i++ {
-		boundaries[i] = min + float64(i)*bucketWidth
+		boundaries[i] = (min/2 + float64(i)*halfWidth) * 2
 	}
 
 	// Count values in each bucket
 	for _, v := range values {
-		idx := int((v - min) / bucketWidth)
+		idx := int((v/2 - min/2) / halfWidth)
 		if idx >= buckets {
 			idx = buckets - 1
 		}
+		if idx < 0 {
+			idx = 0
+		}
 		counts[idx]++
 	}
 
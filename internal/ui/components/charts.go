@@ -0,0 +1,307 @@
+// Package components - Terminal charts drawn with lipgloss.
+//
+// This file implements the charts used to plot query results, and meant for
+// the dashboard too:
+//   - BarChart: horizontal bars, one group of bars per label
+//   - LineChart: lines drawn with braille dots, two by four per cell
+//   - Sparkline: a one-line summary of a series
+//
+// They are passive: each function renders a string for the given size and
+// keeps no state.
+package components
+
+import (
+	"image/color"
+	"math"
+	"strconv"
+	"strings"
+
+	"charm.land/lipgloss/v2"
+)
+
+// Series is one named set of values in a chart. NaN and infinite values are
+// gaps.
+type Series struct {
+	Name   string
+	Values []float64
+	Color  color.Color
+}
+
+// sparkBlocks are the eight heights of a sparkline cell.
+var sparkBlocks = []rune("▁▂▃▄▅▆▇█")
+
+// Sparkline draws values as one line of block heights, averaging values
+// that share a cell when there are more values than width.
+func Sparkline(values []float64, width int, fg, bg color.Color) string {
+	values = resample(values, width)
+	lo, hi := valueRange(values)
+	var b strings.Builder
+	for _, v := range values {
+		switch {
+		case gap(v):
+			b.WriteRune(' ')
+		case hi == lo:
+			b.WriteRune(sparkBlocks[len(sparkBlocks)/2])
+		default:
+			i := int(position(v, lo, hi) * float64(len(sparkBlocks)-1))
+			b.WriteRune(sparkBlocks[min(i, len(sparkBlocks)-1)])
+		}
+	}
+	return lipgloss.NewStyle().Foreground(fg).Background(bg).Render(b.String())
+}
+
+// BarChart draws each label's values as horizontal bars, one per series,
+// with the value after the bar. Negative values extend left of a zero axis.
+func BarChart(labels []string, series []Series, width int, fg, bg color.Color) string {
+	labelW := 0
+	for _, l := range labels {
+		labelW = max(labelW, lipgloss.Width(l))
+	}
+	labelW = min(labelW, max(width/3, 1))
+	valueW := 0
+	lo, hi := 0.0, 0.0
+	for _, s := range series {
+		for _, v := range s.Values {
+			if gap(v) {
+				continue
+			}
+			valueW = max(valueW, len(FormatValue(v)))
+			lo, hi = min(lo, v), max(hi, v)
+		}
+	}
+	barW := max(width-labelW-valueW-3, 1)
+	zero := 0
+	if hi > lo {
+		zero = int(math.Round(position(0, lo, hi) * float64(barW)))
+	}
+
+	text := lipgloss.NewStyle().Foreground(fg).Background(bg)
+	pad := lipgloss.NewStyle().Background(bg)
+	var lines []string
+	for i, label := range labels {
+		for j, s := range series {
+			name := ""
+			if j == 0 {
+				name = truncateLine(label, labelW)
+			}
+			line := text.Render(name + strings.Repeat(" ", labelW-lipgloss.Width(name)) + " ")
+			if i >= len(s.Values) || gap(s.Values[i]) {
+				lines = append(lines, line)
+				continue
+			}
+			v := s.Values[i]
+			end := zero
+			if hi > lo {
+				end = int(math.Round(position(v, lo, hi) * float64(barW)))
+			}
+			from, to := min(zero, end), max(zero, end)
+			bar := strings.Repeat("█", to-from)
+			if bar == "" && v != 0 {
+				bar = "▏"
+			}
+			line += pad.Render(strings.Repeat(" ", from)) +
+				lipgloss.NewStyle().Foreground(s.Color).Background(bg).Render(bar) +
+				text.Render(" "+FormatValue(v))
+			lines = append(lines, line)
+		}
+	}
+	return strings.Join(lines, "\n")
+}
+
+// LineChart draws the series over a shared y axis, labelled with its range,
+// on a plot of width×height cells. xLabels, when given, name the first and
+// last points under the plot.
+func LineChart(series []Series, xLabels []string, width, height int, fg, bg color.Color) string {
+	lo, hi := math.Inf(1), math.Inf(-1)
+	points := 0
+	for _, s := range series {
+		l, h := valueRange(s.Values)
+		lo, hi = min(lo, l), max(hi, h)
+		points = max(points, len(s.Values))
+	}
+	if points == 0 || math.IsInf(lo, 0) {
+		return ""
+	}
+	if hi == lo {
+		lo, hi = lo-1, hi+1
+	}
+
+	axisW := max(len(FormatValue(hi)), len(FormatValue(lo)))
+	plotW := max(width-axisW-1, 2)
+	plotH := max(height-1, 1)
+	if len(xLabels) == 0 {
+		plotH = max(height, 1)
+	}
+
+	// Each cell holds 2×4 braille dots, coloured by the last series through it
+	dots := make([][]rune, plotH)
+	colors := make([][]color.Color, plotH)
+	for r := range dots {
+		dots[r] = make([]rune, plotW)
+		colors[r] = make([]color.Color, plotW)
+	}
+	dotW, dotH := plotW*2, plotH*4
+	set := func(x, y int, c color.Color) {
+		if x < 0 || y < 0 || x >= dotW || y >= dotH {
+			return
+		}
+		dots[y/4][x/2] |= brailleBit(x%2, y%4)
+		colors[y/4][x/2] = c
+	}
+	for _, s := range series {
+		px, py := -1, -1
+		for i, v := range s.Values {
+			if gap(v) {
+				px = -1
+				continue
+			}
+			x := 0
+			if points > 1 {
+				x = int(math.Round(float64(i) / float64(points-1) * float64(dotW-1)))
+			}
+			y := dotH - 1 - int(math.Round(position(v, lo, hi)*float64(dotH-1)))
+			if px < 0 {
+				set(x, y, s.Color)
+			} else {
+				drawLine(px, py, x, y, func(x, y int) { set(x, y, s.Color) })
+			}
+			px, py = x, y
+		}
+	}
+
+	axis := lipgloss.NewStyle().Foreground(fg).Background(bg)
+	lines := make([]string, 0, plotH+1)
+	for r := range dots {
+		label := ""
+		switch r {
+		case 0:
+			label = FormatValue(hi)
+		case plotH - 1:
+			label = FormatValue(lo)
+		}
+		var line strings.Builder
+		line.WriteString(axis.Render(strings.Repeat(" ", axisW-len(label)) + label + "┤"))
+		for c, d := range dots[r] {
+			if d == 0 {
+				line.WriteString(axis.Render(" "))
+				continue
+			}
+			line.WriteString(lipgloss.NewStyle().Foreground(colors[r][c]).Background(bg).Render(string(0x2800 + d)))
+		}
+		lines = append(lines, line.String())
+	}
+	if len(xLabels) > 0 {
+		first, last := xLabels[0], xLabels[len(xLabels)-1]
+		gap := plotW - lipgloss.Width(first) - lipgloss.Width(last)
+		under := truncateLine(first, plotW)
+		if gap > 0 && len(xLabels) > 1 {
+			under = first + strings.Repeat(" ", gap) + last
+		}
+		lines = append(lines, axis.Render(strings.Repeat(" ", axisW+1)+under))
+	}
+	return strings.Join(lines, "\n")
+}
+
+// FormatValue writes a chart value compactly: integers as they are, other
+// numbers to at most three decimals, and huge ones in exponent form.
+func FormatValue(v float64) string {
+	if math.Abs(v) >= 1e15 {
+		return strconv.FormatFloat(v, 'g', 4, 64)
+	}
+	if v == math.Trunc(v) && math.Abs(v) < 1e15 {
+		return strconv.FormatFloat(v, 'f', 0, 64)
+	}
+	return strconv.FormatFloat(math.Round(v*1000)/1000, 'f', -1, 64)
+}
+
+// brailleBit returns the bit of the dot at column x (0-1) and row y (0-3)
+// of a braille cell.
+func brailleBit(x, y int) rune {
+	if y == 3 {
+		return rune(0x40 << x)
+	}
+	return rune(1 << (y + 3*x))
+}
+
+// drawLine calls plot for each dot on the line between two dots.
+func drawLine(x0, y0, x1, y1 int, plot func(x, y int)) {
+	dx, dy := abs(x1-x0), -abs(y1-y0)
+	sx, sy := 1, 1
+	if x0 > x1 {
+		sx = -1
+	}
+	if y0 > y1 {
+		sy = -1
+	}
+	err := dx + dy
+	for {
+		plot(x0, y0)
+		if x0 == x1 && y0 == y1 {
+			return
+		}
+		if e2 := 2 * err; e2 >= dy {
+			err += dy
+			x0 += sx
+		} else {
+			err += dx
+			y0 += sy
+		}
+	}
+}
+
+func abs(n int) int {
+	if n < 0 {
+		return -n
+	}
+	return n
+}
+
+// gap reports whether a value is left out of a chart: NaN or infinite.
+func gap(v float64) bool {
+	return math.IsNaN(v) || math.IsInf(v, 0)
+}
+
+// position returns where v lies between lo and hi, from 0 to 1. Halving
+// first keeps hi-lo finite for values near the float limits; a flat range
+// puts everything at 0.
+func position(v, lo, hi float64) float64 {
+	p := (v/2 - lo/2) / (hi/2 - lo/2)
+	if math.IsNaN(p) || math.IsInf(p, 0) {
+		return 0
+	}
+	return min(max(p, 0), 1)
+}
+
+// valueRange returns the smallest and largest values, ignoring gaps.
+func valueRange(values []float64) (float64, float64) {
+	lo, hi := math.Inf(1), math.Inf(-1)
+	for _, v := range values {
+		if !gap(v) {
+			lo, hi = min(lo, v), max(hi, v)
+		}
+	}
+	return lo, hi
+}
+
+// resample fits values into n cells, averaging the values that share one.
+func resample(values []float64, n int) []float64 {
+	if len(values) <= n || n <= 0 {
+		return values
+	}
+	out := make([]float64, n)
+	for i := range out {
+		from, to := i*len(values)/n, (i+1)*len(values)/n
+		sum, count := 0.0, 0
+		for _, v := range values[from:to] {
+			if !gap(v) {
+				sum += v
+				count++
+			}
+		}
+		out[i] = math.NaN()
+		if count > 0 {
+			out[i] = sum / float64(count)
+		}
+	}
+	return out
+}
@@ -181,8 +181,20 @@ func (m *ExplorerModel) View() tea.View {
 // Helper methods
 
 func (m *ExplorerModel) flattenTree() {
+	current := m.CurrentNode()
 	m.flatList = nil
 	m.flattenNode(m.root, 0)
+
+	// Keep the cursor on the same node when the tree changes around it
+	for i, node := range m.flatList {
+		if node == current {
+			m.cursor = i
+			return
+		}
+	}
+	if m.cursor >= len(m.flatList) {
+		m.cursor = max(len(m.flatList)-1, 0)
+	}
 }
 
 func (m *ExplorerModel) flattenNode(node *TreeNode, depth int) {
@@ -362,17 +374,8 @@ func (m *ExplorerModel) expandOrLoad() tea.Cmd {
 	// Load children if needed
 	switch node.Type {
 	case NodeCategory:
-		switch node.Name {
-		case "Tables":
-			return m.loadTables()
-		case "Views":
-			return m.loadViews()
-		case "Indexes":
-			return m.loadIndexes()
-		case "Triggers":
-			return m.loadTriggers()
-		case "Sequences":
-			return m.loadSequences()
+		if cmd := m.loadCategory(node.Name); cmd != nil {
+			return cmd
 		}
 	case NodeTable:
 		return m.loadColumns(node.Name)
@@ -415,8 +418,58 @@ func (m *ExplorerModel) CurrentNode() *TreeNode {
 	return m.flatList[m.cursor]
 }
 
+// Refresh reloads what the tree shows after a schema change: the expanded
+// categories among categories and the columns of the expanded tables among
+// tables, where nil means all of them. Collapsed categories reload when they
+// are next expanded, and expanded nodes that still exist stay expanded.
+func (m *ExplorerModel) Refresh(categories, tables []string) tea.Cmd {
+	var cmds []tea.Cmd
+	for _, category := range m.root.Children {
+		if !category.Expanded {
+			continue
+		}
+		if categories == nil || containsFold(categories, category.Name) {
+			cmds = append(cmds, m.loadCategory(category.Name))
+		}
+		if category.Name != "Tables" {
+			continue
+		}
+		for _, table := range category.Children {
+			if table.Expanded && (tables == nil || containsFold(tables, table.Name)) {
+				cmds = append(cmds, m.loadColumns(table.Name))
+			}
+		}
+	}
+	return tea.Batch(cmds...)
+}
+
+func containsFold(names []string, name string) bool {
+	for _, n := range names {
+		if strings.EqualFold(n, name) {
+			return true
+		}
+	}
+	return false
+}
+
 // Async loading commands
 
+func (m *ExplorerModel) loadCategory(name string) tea.Cmd {
+	switch name {
+	case "Tables":
+		return m.loadTables()
+	case "Views":
+		return m.loadViews()
+	case "Indexes":
+		return m.loadIndexes()
+	case "Triggers":
+		return m.loadTriggers()
+	case "Sequences":
+		return m.loadSequences()
+	}
+	return nil
+}
+
 func (m *ExplorerModel) loadDatabaseObjects() tea.Cmd {
 	return func() tea.Msg {
 		tables, err := m.db.ListTables()
@@ -500,14 +553,23 @@ func (m *ExplorerModel) loadColumns(tableName string) tea.Cmd {
 func (m *ExplorerModel) updateTables(tables []string) {
 	for _, category := range m.root.Children {
 		if category.Name == "Tables" {
+			// Reuse the nodes of tables that still exist so they stay expanded
+			existing := make(map[string]*TreeNode, len(category.Children))
+			for _, table := range category.Children {
+				existing[table.Name] = table
+			}
 			category.Children = nil
 			for _, tableName := range tables {
-				category.Children = append(category.Children, &TreeNode{
-					Name:     tableName,
-					Type:     NodeTable,
-					Expanded: false,
-					Parent:   category,
-				})
+				node := existing[tableName]
+				if node == nil {
+					node = &TreeNode{
+						Name:     tableName,
+						Type:     NodeTable,
+						Expanded: false,
+						Parent:   category,
+					}
+				}
+				category.Children = append(category.Children, node)
 			}
 			category.Expanded = true
 			break
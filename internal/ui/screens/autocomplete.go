@@ -2,12 +2,14 @@ package screens
 
 import (
 	"fmt"
+	"sort"
 	"strings"
 	"time"
 	"unicode"
 
 	tea "charm.land/bubbletea/v2"
 	"charm.land/lipgloss/v2"
+	"github.com/jupiterozeye/tornado/internal/sqlparse"
 	"github.com/jupiterozeye/tornado/internal/ui/styles"
 )
 
@@ -19,6 +21,7 @@ const (
 	SuggestTable
 	SuggestColumn
 	SuggestFunction
+	SuggestJoin
 )
 
 // Suggestion represents an autocomplete suggestion
@@ -26,26 +29,51 @@ type Suggestion struct {
 	Text        string
 	Type        SuggestionType
 	Description string
+	Detail      string // Longer documentation shown beside the list
 }
 
-// QueryContext represents the editing context for autocomplete
-type QueryContext struct {
-	Text        string
-	CursorPos   int
-	CurrentWord string
-	PrevToken   string
-	InString    bool
-	InComment   bool
+// ForeignKey is columns of Table referencing RefColumns of RefTable
+// pairwise; composite keys have more than one.
+type ForeignKey struct {
+	Table      string
+	Columns    []string
+	RefTable   string
+	RefColumns []string
+}
+
+// predicate renders the join condition between the key's columns, qualified
+// by from, and the referenced columns, qualified by to. Composite keys join
+// each pair with AND.
+func (fk ForeignKey) predicate(from, to string, reversed bool) string {
+	parts := make([]string, len(fk.Columns))
+	for i := range fk.Columns {
+		if reversed {
+			parts[i] = fmt.Sprintf("%s.%s = %s.%s", to, fk.RefColumns[i], from, fk.Columns[i])
+		} else {
+			parts[i] = fmt.Sprintf("%s.%s = %s.%s", from, fk.Columns[i], to, fk.RefColumns[i])
+		}
+	}
+	return strings.Join(parts, " AND ")
+}
+
+// String describes the key as "table(columns) -> table(columns)", or
+// "table.column -> table.column" for a single column.
+func (fk ForeignKey) String() string {
+	if len(fk.Columns) == 1 {
+		return fmt.Sprintf("%s.%s -> %s.%s", fk.Table, fk.Columns[0], fk.RefTable, fk.RefColumns[0])
+	}
+	return fmt.Sprintf("%s(%s) -> %s(%s)", fk.Table, strings.Join(fk.Columns, ", "), fk.RefTable, strings.Join(fk.RefColumns, ", "))
 }
 
 // AutocompleteModel manages autocomplete state
 type AutocompleteModel struct {
-	Visible     bool
-	Suggestions []Suggestion
-	Selected    int
-	TriggerPos  int
-	Width       int
-	Height      int
+	Visible      bool
+	Suggestions  []Suggestion
+	Selected     int
+	TriggerPos   int
+	ReplaceStart int // Start of the word a suggestion replaces
+	Width        int
+	Height       int
 }
 
 // NewAutocompleteModel creates a new autocomplete model
@@ -59,174 +87,305 @@ func NewAutocompleteModel() *AutocompleteModel {
 	}
 }
 
-// getQueryContext analyzes the current query state
-func getQueryContext(queryText string, cursorPos int) QueryContext {
-	ctx := QueryContext{
-		Text:      queryText,
-		CursorPos: cursorPos,
-	}
-
-	if cursorPos > len(queryText) {
-		cursorPos = len(queryText)
-	}
+// columnClauses are the clauses where column names are expected.
+var columnClauses = map[string]bool{
+	"SELECT": true, "ON": true, "USING": true, "WHERE": true, "GROUP BY": true,
+	"ORDER BY": true, "HAVING": true, "SET": true, "RETURNING": true,
+}
 
-	// Find current word
-	start := cursorPos
-	for start > 0 && !isWordBreak(queryText[start-1]) {
-		start--
+// getSuggestions returns autocomplete suggestions for the parsed cursor
+// context, ranked by how well they match the word being typed.
+func getSuggestions(ctx sqlparse.Context, dialect *sqlparse.Dialect, tables []string, columns map[string][]string, foreignKeys []ForeignKey) []Suggestion {
+	if ctx.InString || ctx.InComment {
+		return nil
 	}
-	ctx.CurrentWord = queryText[start:cursorPos]
 
-	// Find previous token (skipping current word)
-	pos := start
-	for pos > 0 && unicode.IsSpace(rune(queryText[pos-1])) {
-		pos--
-	}
-	if pos > 0 {
-		tokenStart := pos
-		for tokenStart > 0 && !isWordBreak(queryText[tokenStart-1]) {
-			tokenStart--
+	var joins, candidates []Suggestion
+	switch {
+	case ctx.Cast:
+		candidates = getTypeSuggestions(dialect)
+	case ctx.Clause == "PRAGMA" && len(dialect.Pragmas) > 0:
+		candidates = getPragmaSuggestions(dialect)
+	case ctx.Qualifier != "":
+		if ref, ok := ctx.Lookup(ctx.Qualifier); ok {
+			candidates = getRefColumnSuggestions(ref, columns)
+		} else {
+			// Probably a schema name: "main.us"
+			candidates = getTableSuggestions(tables, ctx.CTEs)
 		}
-		ctx.PrevToken = strings.ToUpper(queryText[tokenStart:pos])
-	}
-
-	// Check if in string
-	quoteCount := 0
-	for i := 0; i < cursorPos; i++ {
-		if queryText[i] == '\'' {
-			// Check for escaped quote
-			if i == 0 || queryText[i-1] != '\'' {
-				quoteCount++
-			}
+	case ctx.ExpectTable:
+		candidates = getTableSuggestions(tables, ctx.CTEs)
+	case ctx.Clause == "COLUMNS" && len(ctx.Tables) > 0:
+		candidates = getRefColumnSuggestions(ctx.Tables[0], columns)
+	case columnClauses[ctx.Clause]:
+		if ctx.Join != nil {
+			joins = getJoinSuggestions(ctx, foreignKeys)
 		}
+		candidates = getScopeColumnSuggestions(ctx, columns)
+		candidates = append(candidates, getFunctionSuggestions(dialect)...)
+		candidates = append(candidates, getKeywordSuggestions(dialect)...)
+	default:
+		candidates = append(getKeywordSuggestions(dialect), getFunctionSuggestions(dialect)...)
 	}
-	ctx.InString = quoteCount%2 == 1
-
-	// Check if in comment
-	for i := 0; i < cursorPos-1; i++ {
-		if queryText[i] == '-' && queryText[i+1] == '-' {
-			// Line comment - check if we're still on this line
-			for j := i + 2; j < cursorPos; j++ {
-				if queryText[j] == '\n' {
-					ctx.InComment = false
-					break
-				}
-				if j == cursorPos-1 {
-					ctx.InComment = true
-				}
-			}
+
+	// Join predicates are what an ON clause most likely wants, so they stay
+	// ahead of shorter column and keyword matches
+	return append(rankSuggestions(joins, ctx.Word), rankSuggestions(candidates, ctx.Word)...)
+}
+
+func getKeywordSuggestions(dialect *sqlparse.Dialect) []Suggestion {
+	return catalogSuggestions(dialect.Keywords, SuggestKeyword, "keyword")
+}
+
+func getFunctionSuggestions(dialect *sqlparse.Dialect) []Suggestion {
+	suggestions := catalogSuggestions(dialect.Functions, SuggestFunction, "function")
+	for i, s := range suggestions {
+		if doc, ok := dialect.Doc(s.Text); ok {
+			suggestions[i].Description = doc.Signature() + " → " + doc.Returns
+			suggestions[i].Detail = doc.Summary
 		}
 	}
+	return suggestions
+}
 
-	return ctx
+func getTypeSuggestions(dialect *sqlparse.Dialect) []Suggestion {
+	return catalogSuggestions(dialect.Types, SuggestKeyword, "type")
 }
 
-func isWordBreak(ch byte) bool {
-	return unicode.IsSpace(rune(ch)) || strings.ContainsRune(".,;()=<>!+-*/", rune(ch))
+func getPragmaSuggestions(dialect *sqlparse.Dialect) []Suggestion {
+	return catalogSuggestions(dialect.Pragmas, SuggestKeyword, "pragma")
 }
 
-// getSuggestions returns autocomplete suggestions based on context
-func getSuggestions(ctx QueryContext, tables []string, columns map[string][]string) []Suggestion {
-	if ctx.InString || ctx.InComment {
-		return nil
+// catalogSuggestions turns names from a dialect catalog into suggestions.
+func catalogSuggestions(names []string, typ SuggestionType, desc string) []Suggestion {
+	suggestions := make([]Suggestion, 0, len(names))
+	for _, name := range names {
+		suggestions = append(suggestions, Suggestion{
+			Text:        name,
+			Type:        typ,
+			Description: desc,
+		})
 	}
+	return suggestions
+}
 
+// getTableSuggestions suggests schema tables and the CTEs defined by the query.
+func getTableSuggestions(tables []string, ctes []sqlparse.TableRef) []Suggestion {
 	var suggestions []Suggestion
-	prefix := strings.ToUpper(ctx.CurrentWord)
-
-	// Context-aware suggestions
-	switch ctx.PrevToken {
-	case "FROM", "INTO", "UPDATE", "TABLE", "JOIN":
-		// Suggest tables
-		for _, table := range tables {
-			if strings.HasPrefix(strings.ToUpper(table), prefix) {
-				suggestions = append(suggestions, Suggestion{
-					Text:        table,
-					Type:        SuggestTable,
-					Description: "table",
-				})
-			}
-		}
-	case "SELECT", "WHERE", "AND", "OR", "SET", "HAVING":
-		// Suggest columns and keywords
-		suggestions = append(suggestions, getColumnSuggestions(columns, prefix)...)
-		suggestions = append(suggestions, getKeywordSuggestions(prefix)...)
-	default:
-		// General suggestions - keywords and functions
-		suggestions = append(suggestions, getKeywordSuggestions(prefix)...)
-		suggestions = append(suggestions, getFunctionSuggestions(prefix)...)
+	for _, cte := range ctes {
+		suggestions = append(suggestions, Suggestion{
+			Text:        cte.Name,
+			Type:        SuggestTable,
+			Description: "cte",
+		})
+	}
+	for _, table := range tables {
+		suggestions = append(suggestions, Suggestion{
+			Text:        table,
+			Type:        SuggestTable,
+			Description: "table",
+		})
 	}
-
 	return suggestions
 }
 
-func getKeywordSuggestions(prefix string) []Suggestion {
-	keywords := []string{
-		"SELECT", "FROM", "WHERE", "INSERT", "UPDATE", "DELETE",
-		"CREATE", "DROP", "ALTER", "TABLE", "INDEX", "VIEW",
-		"JOIN", "INNER", "LEFT", "RIGHT", "OUTER", "ON",
-		"GROUP", "BY", "ORDER", "HAVING", "LIMIT", "OFFSET",
-		"AND", "OR", "NOT", "IN", "EXISTS", "BETWEEN",
-		"LIKE", "IS", "NULL", "TRUE", "FALSE",
-		"VALUES", "SET", "INTO", "AS", "DISTINCT", "ALL",
-		"UNION", "INTERSECT", "EXCEPT", "WITH", "RECURSIVE",
-		"CASE", "WHEN", "THEN", "ELSE", "END",
-		"COMMIT", "ROLLBACK", "BEGIN", "TRANSACTION",
+// getJoinSuggestions suggests join predicates for an ON clause, derived from
+// the foreign keys between the joined table and the other tables in scope:
+// "JOIN orders o ON " offers "o.user_id = u.id".
+func getJoinSuggestions(ctx sqlparse.Context, foreignKeys []ForeignKey) []Suggestion {
+	join := *ctx.Join
+	if join.Derived {
+		return nil
 	}
 
 	var suggestions []Suggestion
-	for _, kw := range keywords {
-		if strings.HasPrefix(kw, prefix) {
-			suggestions = append(suggestions, Suggestion{
-				Text:        kw,
-				Type:        SuggestKeyword,
-				Description: "keyword",
-			})
+	seen := make(map[string]bool)
+	add := func(text, desc string) {
+		if !seen[text] {
+			seen[text] = true
+			suggestions = append(suggestions, Suggestion{Text: text, Type: SuggestJoin, Description: desc})
+		}
+	}
+
+	for _, other := range ctx.Tables {
+		if other.Derived || other.Pos == join.Pos {
+			continue
+		}
+		for _, fk := range foreignKeys {
+			// The joined table references the other table
+			if strings.EqualFold(fk.Table, join.Name) && strings.EqualFold(fk.RefTable, other.Name) {
+				add(fk.predicate(join.RefName(), other.RefName(), false), fk.String())
+			}
+			// The other table references the joined table
+			if strings.EqualFold(fk.Table, other.Name) && strings.EqualFold(fk.RefTable, join.Name) {
+				add(fk.predicate(other.RefName(), join.RefName(), true), fk.String())
+			}
 		}
 	}
 	return suggestions
 }
 
-func getFunctionSuggestions(prefix string) []Suggestion {
-	functions := []string{
-		"COUNT", "SUM", "AVG", "MIN", "MAX",
-		"LENGTH", "SUBSTR", "TRIM", "UPPER", "LOWER",
-		"CONCAT", "REPLACE", "INSTR", "ABS", "ROUND",
-		"COALESCE", "IFNULL", "NULLIF", "DATE", "TIME",
-		"DATETIME", "STRFTIME", "RANDOM", "CHANGES",
-		"ROW_NUMBER", "RANK", "DENSE_RANK",
-		"LAG", "LEAD", "FIRST_VALUE", "LAST_VALUE",
+// getScopeColumnSuggestions suggests the columns of the tables in scope, and
+// the aliases that can qualify them. Without any tables in scope yet (e.g.
+// "SELECT |" before the FROM is written) every known column is suggested.
+func getScopeColumnSuggestions(ctx sqlparse.Context, columns map[string][]string) []Suggestion {
+	if len(ctx.Tables) == 0 {
+		var suggestions []Suggestion
+		for table, cols := range columns {
+			for _, col := range cols {
+				suggestions = append(suggestions, Suggestion{
+					Text:        col,
+					Type:        SuggestColumn,
+					Description: table + " column",
+				})
+			}
+		}
+		sort.Slice(suggestions, func(i, j int) bool {
+			return suggestions[i].Text < suggestions[j].Text
+		})
+		return suggestions
 	}
 
 	var suggestions []Suggestion
-	for _, fn := range functions {
-		if strings.HasPrefix(fn, prefix) {
+	seen := make(map[string]bool)
+	for _, ref := range ctx.Tables {
+		for _, s := range getRefColumnSuggestions(ref, columns) {
+			key := strings.ToLower(s.Text)
+			if !seen[key] {
+				seen[key] = true
+				suggestions = append(suggestions, s)
+			}
+		}
+	}
+	for _, ref := range ctx.Tables {
+		if ref.Alias != "" {
 			suggestions = append(suggestions, Suggestion{
-				Text:        fn,
-				Type:        SuggestFunction,
-				Description: "function",
+				Text:        ref.Alias,
+				Type:        SuggestTable,
+				Description: "alias for " + refLabel(ref),
 			})
 		}
 	}
 	return suggestions
 }
 
-func getColumnSuggestions(columns map[string][]string, prefix string) []Suggestion {
+// getRefColumnSuggestions suggests the columns of one table reference.
+func getRefColumnSuggestions(ref sqlparse.TableRef, columns map[string][]string) []Suggestion {
 	var suggestions []Suggestion
-	for table, cols := range columns {
-		for _, col := range cols {
-			if strings.HasPrefix(strings.ToUpper(col), prefix) {
-				suggestions = append(suggestions, Suggestion{
-					Text:        col,
-					Type:        SuggestColumn,
-					Description: table + " column",
-				})
+	desc := refLabel(ref) + " column"
+	for _, col := range refColumns(ref, columns, 0) {
+		suggestions = append(suggestions, Suggestion{
+			Text:        col,
+			Type:        SuggestColumn,
+			Description: desc,
+		})
+	}
+	return suggestions
+}
+
+// refColumns returns the columns of a table reference: schema columns for a
+// table, select list columns for a subquery or CTE.
+func refColumns(ref sqlparse.TableRef, columns map[string][]string, depth int) []string {
+	if !ref.Derived {
+		if cols, ok := columns[ref.Name]; ok {
+			return cols
+		}
+		for table, cols := range columns {
+			if strings.EqualFold(table, ref.Name) {
+				return cols
 			}
 		}
+		return nil
+	}
+	cols := append([]string{}, ref.Columns...)
+	if depth < 8 {
+		for _, s := range ref.Star {
+			cols = append(cols, refColumns(s, columns, depth+1)...)
+		}
+	}
+	return cols
+}
+
+// refLabel names a table reference in suggestion descriptions.
+func refLabel(ref sqlparse.TableRef) string {
+	if ref.Name != "" {
+		return ref.Name
+	}
+	if ref.Alias != "" {
+		return ref.Alias
+	}
+	return "subquery"
+}
+
+// rankSuggestions drops candidates that do not fuzzy-match word and orders
+// the rest best match first. Ties keep the candidates' order, which puts the
+// kinds most relevant to the context first.
+func rankSuggestions(candidates []Suggestion, word string) []Suggestion {
+	type scored struct {
+		Suggestion
+		score int
+	}
+	var matches []scored
+	for _, c := range candidates {
+		if score, ok := fuzzyScore(c.Text, word); ok {
+			matches = append(matches, scored{c, score})
+		}
+	}
+	sort.SliceStable(matches, func(i, j int) bool {
+		return matches[i].score > matches[j].score
+	})
+
+	suggestions := make([]Suggestion, len(matches))
+	for i, m := range matches {
+		suggestions[i] = m.Suggestion
 	}
 	return suggestions
 }
 
+// fuzzyScore reports whether pattern matches candidate as a case-insensitive
+// subsequence, and how well. Prefix matches, matches at word boundaries
+// ("cid" in "customer_id") and consecutive runs score higher.
+func fuzzyScore(candidate, pattern string) (int, bool) {
+	if pattern == "" {
+		return 0, true
+	}
+	c := []rune(candidate)
+	p := []rune(strings.ToLower(pattern))
+
+	score, pi, prev := 0, 0, -2
+	for ci := 0; ci < len(c) && pi < len(p); ci++ {
+		if unicode.ToLower(c[ci]) != p[pi] {
+			continue
+		}
+		score++
+		switch {
+		case ci == 0:
+			score += 8
+		case c[ci-1] == '_' || c[ci-1] == '.' || (unicode.IsLower(c[ci-1]) && unicode.IsUpper(c[ci])):
+			score += 6
+		}
+		if prev == ci-1 {
+			score += 4
+		}
+		prev = ci
+		pi++
+	}
+	if pi < len(p) {
+		return 0, false
+	}
+
+	lower := strings.ToLower(candidate)
+	if strings.HasPrefix(lower, string(p)) {
+		score += 20
+		if len(c) == len(p) {
+			score += 10
+		}
+	}
+	// Prefer shorter candidates among otherwise equal matches
+	score -= (len(c) - len(p)) / 4
+	return score, true
+}
+
 // Render renders the autocomplete dropdown
 func (m *AutocompleteModel) Render() string {
 	if !m.Visible || len(m.Suggestions) == 0 {
@@ -254,6 +413,7 @@ func (m *AutocompleteModel) Render() string {
 		SuggestTable:    lipgloss.NewStyle().Foreground(styles.Success),
 		SuggestColumn:   lipgloss.NewStyle().Foreground(styles.Warning),
 		SuggestFunction: lipgloss.NewStyle().Foreground(styles.Accent),
+		SuggestJoin:     lipgloss.NewStyle().Foreground(styles.Info),
 	}
 	defaultStyle := lipgloss.NewStyle().Foreground(styles.Text)
 
@@ -306,6 +466,89 @@ func (m *AutocompleteModel) Render() string {
 	return boxStyle.Render(content)
 }
 
+// suggestionDocWidth is the text width of the documentation panel.
+const suggestionDocWidth = 40
+
+// RenderDoc renders the documentation panel shown beside the dropdown for
+// the selected suggestion, or "" if it has no documentation.
+func (m *AutocompleteModel) RenderDoc() string {
+	if !m.Visible || m.Selected >= len(m.Suggestions) {
+		return ""
+	}
+	s := m.Suggestions[m.Selected]
+	if s.Detail == "" {
+		return ""
+	}
+
+	bg := lipgloss.NewStyle().Background(styles.BgDark)
+	sigStyle := bg.Foreground(styles.Accent).Bold(true)
+	textStyle := bg.Foreground(styles.Text)
+
+	var lines []string
+	for _, l := range wrapWords(s.Description, suggestionDocWidth) {
+		lines = append(lines, sigStyle.Render(padToWidth(l, suggestionDocWidth)))
+	}
+	lines = append(lines, bg.Render(strings.Repeat(" ", suggestionDocWidth)))
+	for _, l := range wrapWords(s.Detail, suggestionDocWidth) {
+		lines = append(lines, textStyle.Render(padToWidth(l, suggestionDocWidth)))
+	}
+
+	boxStyle := lipgloss.NewStyle().
+		Border(lipgloss.RoundedBorder()).
+		BorderForeground(styles.Border).
+		BorderBackground(styles.BgDark).
+		Background(styles.BgDark).
+		Padding(0, 1)
+	return boxStyle.Render(strings.Join(lines, "\n"))
+}
+
+// wrapWords wraps text at spaces to lines of at most width characters.
+func wrapWords(text string, width int) []string {
+	var lines []string
+	line := ""
+	for _, word := range strings.Fields(text) {
+		switch {
+		case line == "":
+			line = word
+		case len([]rune(line))+1+len([]rune(word)) > width:
+			lines = append(lines, line)
+			line = word
+		default:
+			line += " " + word
+		}
+	}
+	if line != "" {
+		lines = append(lines, line)
+	}
+	return lines
+}
+
+// renderSignatureHint renders the signature of the function call at the
+// cursor with the current argument highlighted, for the status bar.
+func renderSignatureHint(doc sqlparse.FunctionDoc, arg int) string {
+	bg := lipgloss.NewStyle().Background(styles.BgLight)
+	nameStyle := bg.Foreground(styles.Accent).Bold(true)
+	textStyle := bg.Foreground(styles.TextMuted)
+	argStyle := bg.Foreground(styles.Primary).Bold(true).Underline(true)
+
+	current := doc.ArgIndex(arg)
+	var b strings.Builder
+	b.WriteString(nameStyle.Render(doc.Name))
+	b.WriteString(textStyle.Render("("))
+	for i, a := range doc.Args {
+		if i > 0 {
+			b.WriteString(textStyle.Render(", "))
+		}
+		if i == current {
+			b.WriteString(argStyle.Render(a))
+		} else {
+			b.WriteString(textStyle.Render(a))
+		}
+	}
+	b.WriteString(textStyle.Render(") → " + doc.Returns + "  " + doc.Summary))
+	return b.String()
+}
+
 // HandleKey handles key presses when autocomplete is active
 func (m *AutocompleteModel) HandleKey(msg tea.KeyPressMsg) (bool, string) {
 	if !m.Visible {
@@ -0,0 +1,108 @@
+package screens
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/jupiterozeye/tornado/internal/sqlparse"
+)
+
+func suggestionsAt(sql string, tables []string, columns map[string][]string, foreignKeys ...ForeignKey) []string {
+	cursor := strings.Index(sql, "|")
+	ctx := sqlparse.Analyze(strings.Replace(sql, "|", "", 1), cursor)
+	var texts []string
+	for _, s := range getSuggestions(ctx, sqlparse.SQLite, tables, columns, foreignKeys) {
+		texts = append(texts, s.Text)
+	}
+	return texts
+}
+
+func TestGetSuggestions_scope(t *testing.T) {
+	tables := []string{"users", "orders", "order_items"}
+	columns := map[string][]string{
+		"users":       {"id", "name", "email"},
+		"orders":      {"id", "user_id", "total"},
+		"order_items": {"order_id", "sku"},
+	}
+
+	got := suggestionsAt("SELECT u.| FROM users u", tables, columns)
+	if strings.Join(got, ",") != "id,name,email" {
+		t.Errorf("alias columns = %v, want [id name email]", got)
+	}
+
+	got = suggestionsAt("SELECT o.| FROM (SELECT id, total AS amount FROM orders) o", tables, columns)
+	if strings.Join(got, ",") != "id,amount" {
+		t.Errorf("subquery columns = %v, want [id amount]", got)
+	}
+
+	got = suggestionsAt("SELECT * FROM orders WHERE s|", tables, columns)
+	for _, s := range got {
+		if s == "sku" {
+			t.Errorf("column of a table not in scope suggested: %v", got)
+		}
+	}
+
+	got = suggestionsAt("SELECT * FROM oi|", tables, columns)
+	if len(got) == 0 || got[0] != "order_items" {
+		t.Errorf("fuzzy table match = %v, want order_items first", got)
+	}
+}
+
+func TestGetSuggestions_joinPredicates(t *testing.T) {
+	tables := []string{"users", "orders"}
+	columns := map[string][]string{
+		"users":  {"id", "name"},
+		"orders": {"id", "user_id"},
+	}
+	fk := ForeignKey{Table: "orders", Columns: []string{"user_id"}, RefTable: "users", RefColumns: []string{"id"}}
+
+	got := suggestionsAt("SELECT * FROM users JOIN orders ON |", tables, columns, fk)
+	if len(got) == 0 || got[0] != "orders.user_id = users.id" {
+		t.Errorf("join suggestions = %v, want orders.user_id = users.id first", got)
+	}
+
+	got = suggestionsAt("SELECT * FROM orders o JOIN users u ON u|", tables, columns, fk)
+	if len(got) == 0 || got[0] != "u.id = o.user_id" {
+		t.Errorf("join suggestions = %v, want u.id = o.user_id first", got)
+	}
+
+	// A composite key joins on all of its columns
+	composite := ForeignKey{Table: "lines", Columns: []string{"region", "num"}, RefTable: "orders", RefColumns: []string{"region", "id"}}
+	got = suggestionsAt("SELECT * FROM orders o JOIN lines l ON |", []string{"orders", "lines"}, columns, composite)
+	if want := "l.region = o.region AND l.num = o.id"; len(got) == 0 || got[0] != want {
+		t.Errorf("join suggestions = %v, want %s first", got, want)
+	}
+}
+
+func TestGetSuggestions_dialect(t *testing.T) {
+	ctx := sqlparse.Analyze("SELECT x::js", len("SELECT x::js"))
+	var got []string
+	for _, s := range getSuggestions(ctx, sqlparse.Postgres, nil, nil, nil) {
+		got = append(got, s.Text)
+	}
+	if len(got) == 0 || got[0] != "JSON" {
+		t.Errorf("cast suggestions = %v, want JSON first", got)
+	}
+
+	if got := suggestionsAt("PRAGMA table_i|", nil, nil); len(got) == 0 || got[0] != "table_info" {
+		t.Errorf("pragma suggestions = %v, want table_info first", got)
+	}
+
+	if sqlparse.SQLite.IsKeyword("ILIKE") || !sqlparse.Postgres.IsKeyword("ilike") {
+		t.Error("ILIKE should be a Postgres keyword only")
+	}
+}
+
+func TestFuzzyScore(t *testing.T) {
+	if _, ok := fuzzyScore("customer_id", "cid"); !ok {
+		t.Error("cid should match customer_id")
+	}
+	if _, ok := fuzzyScore("name", "nx"); ok {
+		t.Error("nx should not match name")
+	}
+	prefix, _ := fuzzyScore("user_id", "us")
+	inner, _ := fuzzyScore("status", "us")
+	if prefix <= inner {
+		t.Errorf("prefix match scored %d, not above inner match %d", prefix, inner)
+	}
+}
@@ -27,6 +27,7 @@ import (
 	"context"
 	"fmt"
 	"image/color"
+	"slices"
 	"strings"
 	"time"
 
@@ -43,6 +44,7 @@ import (
 	"github.com/jupiterozeye/tornado/internal/config"
 	"github.com/jupiterozeye/tornado/internal/db"
 	"github.com/jupiterozeye/tornado/internal/models"
+	"github.com/jupiterozeye/tornado/internal/sqlparse"
 	"github.com/jupiterozeye/tornado/internal/ui/components"
 	"github.com/jupiterozeye/tornado/internal/ui/layout"
 	"github.com/jupiterozeye/tornado/internal/ui/styles"
@@ -109,16 +111,98 @@ type BrowserModel struct {
 	resultsCursorCol    int    // Selected column in results table
 	resultsScrollCol    int    // Horizontal scroll offset for table
 	showPreview         bool   // Preview popup visible
+	recordView          bool   // Results show the selected row vertically
+	recordScroll        int    // First line of the record view
 	previewContent      string // Content to preview
 	previewTitle        string // Title for preview popup
 	showCopyMenu        bool   // Copy menu popup visible
 
+	// jsonView shows a JSON cell in the preview as a tree, or nil
+	jsonView *jsonViewer
+	// hexView shows a binary cell in the preview as a hex dump, or nil
+	hexView *hexViewer
+	// profileView shows statistics for a results column, or nil
+	profileView *profileViewer
+	// selection is the visual block in the results grid, or nil
+	selection *cellSelection
+	// chartView plots the results in place of the grid, or nil
+	chartView *chartViewer
+	// baseline is the result marked for comparison, and diffView its diff
+	// with the current result shown in place of the grid, or nil
+	baseline *resultBaseline
+	diffView *diffViewer
+
+	// Column layouts of the results grid by layoutKey, and the auto-fit
+	// widths of the current result
+	columnLayouts map[string]*config.ColumnLayout
+	autoWidths    []int
+	autoWidthsOf  *models.QueryResult
+
+	// Server-side sort and filter for tables opened from the explorer
+	tableQuery        *tableQuery // nil when results did not come from the explorer
+	whereFilterActive bool        // Structured filter bar input mode active
+	whereFilterInput  string      // Structured filter bar text
+	keepResultsCursor bool        // Keep column cursor when the next result arrives
+
+	// Result history: one tab per executed query, activeTab is -1 when empty
+	resultTabs []*resultTab
+	activeTab  int
+
+	// Query buffers, persisted per connection under connKey
+	buffers            []*queryBuffer
+	activeBuffer       int
+	connKey            string
+	bufferSaves        *bufferSaver
+	editTracked        bool   // Undo/redo already updated the history for this key
+	insertUndoOpen     bool   // Current INSERT session already has an undo step
+	bufferRenameActive bool   // Buffer rename prompt input mode active
+	bufferRenameInput  string // Buffer rename prompt text
+
+	// Ex-style ":" command line
+	commandLineActive bool
+	commandLineInput  string
+	completions       []string // Tab completion candidates, nil when not completing
+	completionIndex   int
+	completionBase    string // Command line text before the completed word
+
+	// readOnly rejects statements that write, both here and in the database
+	readOnly bool
+
+	// Watching the database for commits from other connections. watch
+	// flags the results as stale; autoRerun also re-runs the active query.
+	watch            bool
+	autoRerun        bool
+	watchGen         int
+	dataVersion      int64
+	dataVersionKnown bool
+	dbChanged        bool
+
+	// queryWatch re-runs a query on an interval (":watch"), or nil
+	queryWatch    *queryWatch
+	queryWatchGen int
+
 	// Autocomplete
 	autocomplete *AutocompleteModel
 
+	// Signature help for the function call around the cursor in INSERT mode
+	signature    *sqlparse.FunctionDoc
+	signatureArg int
+
+	// Background validation of the buffer; validationText is the text the
+	// last check ran against, so stale results are not shown while typing
+	validation     *db.ValidationError
+	validationText string
+
+	// SQL dialect of the connected database, for completion
+	dialect *sqlparse.Dialect
+
 	// Schema cache for autocomplete
-	tables  []string
-	columns map[string][]string
+	tables      []string
+	columns     map[string][]string
+	foreignKeys []ForeignKey
+	// schemaVersion is the last schema version seen, to notice DDL from
+	// other connections
+	schemaVersion int64
 
 	// Visual mode selection tracking
 	visualStart struct {
@@ -129,10 +213,9 @@ type BrowserModel struct {
 		row int
 		col int
 	}
-	yankBuffer string
 
-	// Pending normal-mode key for multi-key commands (gg, dd, yy)
-	pendingNormalKey string
+	// Vim engine: pending command, registers, search and dot-repeat
+	vim vimState
 
 	// Context for cancelling background operations
 	ctx    context.Context
@@ -142,8 +225,9 @@ type BrowserModel struct {
 	cleanedUp bool
 }
 
-// NewBrowserModel creates a new browser screen model.
-func NewBrowserModel(database db.Database) *BrowserModel {
+// NewBrowserModel creates a new browser screen model. The connection config
+// identifies which saved query buffers to restore.
+func NewBrowserModel(database db.Database, conn models.ConnectionConfig) *BrowserModel {
 	s := styles.Default()
 	l := layout.New()
 
@@ -261,6 +345,12 @@ func NewBrowserModel(database db.Database) *BrowserModel {
 		Foreground(styles.TextMuted).
 		Background(styles.BgDark)
 
+	dialect := sqlparse.Generic
+	if database != nil {
+		dialect = sqlparse.DialectFor(database.GetType())
+	}
+	components.SetSQLDialect(dialect)
+
 	ctx, cancel := context.WithCancel(context.Background())
 
 	m := &BrowserModel{
@@ -274,19 +364,31 @@ func NewBrowserModel(database db.Database) *BrowserModel {
 		showExplorer:  true,
 		themeList:     themeList,
 		maximizedPane: PaneNone,
+		activeTab:     -1,
+		buffers:       []*queryBuffer{newQueryBuffer("scratch1")},
+		connKey:       config.ConnectionKey(conn),
+		bufferSaves:   newBufferSaver(),
 		autocomplete:  NewAutocompleteModel(),
+		dialect:       dialect,
 		columns:       make(map[string][]string),
 		ctx:           ctx,
 		cancel:        cancel,
 	}
+	m.restoreBuffers()
 
 	return m
 }
 
 // SchemaLoadedMsg is sent when schema loading completes in the background.
 type SchemaLoadedMsg struct {
-	Tables  []string
-	Columns map[string][]string
+	Tables      []string
+	Columns     map[string][]string
+	ForeignKeys []ForeignKey
+	// Partial is set when only some tables were described; Columns and
+	// ForeignKeys then cover just those tables.
+	Partial bool
+	// Version is the schema version read before loading started.
+	Version int64
 }
 
 // ExplorerInitMsg is sent when the explorer has been initialized in the background.
@@ -296,9 +398,11 @@ type ExplorerInitMsg struct {
 }
 
 // loadSchemaCmd returns a tea.Cmd that loads the schema in a goroutine.
-func (m *BrowserModel) loadSchemaCmd() tea.Cmd {
+// When only is non-nil just those tables are described again.
+func (m *BrowserModel) loadSchemaCmd(only []string) tea.Cmd {
 	db := m.db
 	ctx := m.ctx
+	currentVersion := m.schemaVersion
 	return func() tea.Msg {
 		if db == nil {
 			return SchemaLoadedMsg{}
@@ -311,6 +415,11 @@ func (m *BrowserModel) loadSchemaCmd() tea.Cmd {
 		default:
 		}
 
+		version, err := db.SchemaVersion()
+		if err != nil {
+			version = currentVersion
+		}
+
 		// Use the Database interface instead of SQLite-specific SQL
 		tables, err := db.ListTables()
 		if err != nil {
@@ -325,13 +434,19 @@ func (m *BrowserModel) loadSchemaCmd() tea.Cmd {
 		}
 
 		columns := make(map[string][]string)
+		var foreignKeys []ForeignKey
+		partial := only != nil
 
 		// Query for columns of each table using the Database interface
 		for _, table := range tables {
+			if partial && !containsTable(only, table) {
+				continue
+			}
+
 			// Check context before each table query
 			select {
 			case <-ctx.Done():
-				return SchemaLoadedMsg{Tables: tables, Columns: columns}
+				return SchemaLoadedMsg{Tables: tables, Columns: columns, ForeignKeys: foreignKeys, Partial: partial, Version: version}
 			default:
 			}
 
@@ -343,16 +458,24 @@ func (m *BrowserModel) loadSchemaCmd() tea.Cmd {
 			for _, col := range schema.Columns {
 				cols = append(cols, col.Name)
 			}
+			for _, fk := range schema.ForeignKeys {
+				foreignKeys = append(foreignKeys, ForeignKey{
+					Table:      table,
+					Columns:    fk.Columns,
+					RefTable:   fk.RefTable,
+					RefColumns: fk.RefColumns,
+				})
+			}
 			columns[table] = cols
 		}
 
-		return SchemaLoadedMsg{Tables: tables, Columns: columns}
+		return SchemaLoadedMsg{Tables: tables, Columns: columns, ForeignKeys: foreignKeys, Partial: partial, Version: version}
 	}
 }
 
 // Init returns the initial command for the browser screen.
 func (m *BrowserModel) Init() tea.Cmd {
-	return tea.Batch(m.initExplorer(), m.loadSchemaCmd())
+	return tea.Batch(m.initExplorer(), m.loadSchemaCmd(nil), m.scheduleValidation(), m.pollSchemaVersion(), m.waitBufferSaveError())
 }
 
 // Update handles messages for the browser screen.
@@ -369,132 +492,106 @@ func (m *BrowserModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 		m.updateComponentSizes()
 
 	case tea.KeyPressMsg:
-		// Handle preview dialog first
-		if m.showPreview {
-			if msg.String() == "esc" || msg.String() == "q" {
-				m.showPreview = false
-				m.statusMsg = ""
-				return m, nil
-			}
-			return m, nil
-		}
-
-		if m.themeMenu {
-			return m.handleThemeMenuKey(msg)
-		}
-
-		if m.showCopyMenu {
-			return m.handleCopyMenuKey(msg)
-		}
-
-		if m.leaderActive {
-			return m.handleLeaderKey(msg)
-		}
-
-		if m.focusedPane == PaneExplorer {
-			handled, cmd := m.handleExplorerActionKey(msg)
-			if handled {
-				return m, cmd
-			}
-		}
-
-		// When in Query pane with INSERT mode, route all keys directly to query editor
-		// This prevents global shortcuts like 'e', 'q', 'r' from interfering with typing
-		if m.focusedPane == PaneQuery && m.queryMode == QueryModeInsert {
-			// Check if autocomplete is visible and handle its keys
-			if m.autocomplete.Visible {
-				handled, suggestion := m.autocomplete.HandleKey(msg)
-				if handled {
-					if suggestion != "" {
-						// Apply the suggestion
-						m.applyAutocompleteSuggestion(suggestion)
-					}
-					return m, nil
-				}
-			}
-
-			switch msg.String() {
-			case "ctrl+enter":
-				return m, m.executeQuery()
-			case "esc":
-				m.autocomplete.Visible = false
-				m.queryMode = QueryModeNormal
-				m.query.Blur()
-				return m, nil
-			default:
-				var cmd tea.Cmd
-				m.query, cmd = m.query.Update(msg)
-				// Trigger autocomplete after typing (use text length as cursor pos)
-				cursorPos := len(m.query.Value())
-				return m, tea.Batch(cmd, TriggerAutocomplete(m.query.Value(), cursorPos))
-			}
-		}
-
-		// When filtering results, route all keys to filter input/navigation.
-		// This prevents global shortcuts (e/q/r/space...) from hijacking typing.
-		if m.focusedPane == PaneResults && m.resultsFilterActive {
-			return m.handleResultsKey(msg)
-		}
-
-		// Global key bindings (only processed when NOT in INSERT mode)
-		switch msg.String() {
-		case "space":
-			// Show leader menu immediately
-			m.leaderActive = true
-			m.themeMenu = false
-			m.statusMsg = ""
-			return m, nil
-		case "e":
-			m.focusedPane = PaneExplorer
-			m.updateFocus()
-			return m, nil
-		case "q":
-			m.focusedPane = PaneQuery
-			m.updateFocus()
-			return m, nil
-		case "r":
-			m.focusedPane = PaneResults
-			m.updateFocus()
-			return m, nil
-		case "ctrl+enter":
-			if m.focusedPane == PaneQuery {
-				return m, m.executeQuery()
-			}
+		// Track editor changes for undo, whichever handler made them
+		before := m.snapshotEdit()
+		model, cmd := m.handleKeyPress(msg)
+		m.trackEdit(before)
+		if m.query.Value() != before.text {
+			cmd = tea.Batch(cmd, m.scheduleValidation())
 		}
-
-		// Route to focused component
-		return m.routeKeyMsg(msg)
+		return model, cmd
 
 	case components.TableSelectedMsg:
 		// User pressed 's' on a table in explorer
-		m.query.SetValue("SELECT * FROM " + msg.Name + " LIMIT 100;")
+		m.tableQuery = newTableQuery(msg.Name)
+		m.setQueryText(m.tableQuery.SQL())
 		m.focusedPane = PaneQuery
 		m.updateFocus()
+		return m, m.scheduleValidation()
+
+	case ExternalEditMsg:
+		return m, m.handleExternalEdit(msg)
+
+	case validateTickMsg:
+		return m, m.validateCmd(msg.Text)
+
+	case QueryValidatedMsg:
+		m.handleValidated(msg)
 		return m, nil
 
 	case QueryExecutedMsg:
+		if msg.Rerun {
+			m.applyRerun(msg)
+			return m, nil
+		}
+		m.saveActiveTab()
+		replace := m.keepResultsCursor
+		// Results no longer belong to the explorer table once another query runs
+		if m.tableQuery != nil && msg.Query != m.tableQuery.SQL() {
+			m.tableQuery = nil
+		}
 		if msg.Err != nil {
 			m.queryError = msg.Err.Error()
 			m.currentResults = nil
+			m.resultsFilterActive = false
+			m.resultsFilter = ""
+			m.filteredResults = nil
 		} else {
 			m.queryError = ""
 			m.currentResults = msg.Result
-			m.resultsCursorCol = 0        // Reset column cursor for new results
-			m.resultsScrollCol = 0        // Reset horizontal scroll
+			if !m.keepResultsCursor {
+				m.resultsCursorCol = 0 // Reset column cursor for new results
+				m.resultsScrollCol = 0 // Reset horizontal scroll
+			}
 			m.resultsFilterActive = false // Exit filter mode
 			m.resultsFilter = ""
 			m.filteredResults = nil
 			m.updateResultsTable()
 		}
+		m.keepResultsCursor = false
+		m.dbChanged = false
+		m.recordResult(msg.Query, replace)
 		m.focusedPane = PaneResults
 		m.updateFocus()
+		return m, m.refreshSchema(msg.Schema)
+
+	case ColumnProfileMsg:
+		m.applyColumnProfile(msg)
+		return m, nil
+
+	case ResultDiffKeyMsg:
+		m.applyDiffKey(msg)
+		return m, nil
+
+	case ReadOnlyMsg:
+		if msg.Err != nil {
+			m.statusMsg = optionState("readonly", msg.On) + ": database refused it: " + msg.Err.Error()
+		}
 		return m, nil
 
+	case BufferSaveErrorMsg:
+		m.statusMsg = "Saving buffers failed: " + msg.Err.Error()
+		return m, m.waitBufferSaveError()
+
 	case SchemaLoadedMsg:
-		m.tables = msg.Tables
-		m.columns = msg.Columns
+		m.applySchema(msg)
 		return m, nil
 
+	case queryWatchTickMsg:
+		return m, m.handleQueryWatchTick(msg)
+
+	case watchTickMsg:
+		return m, m.checkDataVersion(msg.Gen)
+
+	case DataVersionMsg:
+		return m, m.handleDataVersion(msg)
+
+	case schemaPollMsg:
+		return m, m.checkSchemaVersion()
+
+	case SchemaVersionMsg:
+		return m, m.handleSchemaVersion(msg)
+
 	case ExplorerInitMsg:
 		m.explorer = msg.Explorer
 		return m, func() tea.Msg { return msg.InnerMsg }
@@ -509,14 +606,15 @@ func (m *BrowserModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 			return m, nil
 		}
 
-		ctx := getQueryContext(msg.QueryText, msg.CursorPos)
-		suggestions := getSuggestions(ctx, m.tables, m.columns)
+		ctx := sqlparse.Analyze(msg.QueryText, msg.CursorPos)
+		suggestions := getSuggestions(ctx, m.dialect, m.tables, m.columns, m.foreignKeys)
 
 		if len(suggestions) > 0 {
 			m.autocomplete.Suggestions = suggestions
 			m.autocomplete.Selected = 0
 			m.autocomplete.Visible = true
 			m.autocomplete.TriggerPos = msg.CursorPos
+			m.autocomplete.ReplaceStart = ctx.WordStart
 		} else {
 			m.autocomplete.Visible = false
 		}
@@ -532,6 +630,141 @@ func (m *BrowserModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 	return m, nil
 }
 
+// handleKeyPress routes a key press to the active overlay, mode or pane.
+func (m *BrowserModel) handleKeyPress(msg tea.KeyPressMsg) (tea.Model, tea.Cmd) {
+	// Handle preview dialog first
+	if m.showPreview {
+		if m.jsonView != nil {
+			return m.handleJSONViewKey(msg)
+		}
+		if m.hexView != nil {
+			return m.handleHexViewKey(msg)
+		}
+		if m.profileView != nil {
+			return m.handleProfileViewKey(msg)
+		}
+		if msg.String() == "esc" || msg.String() == "q" {
+			m.showPreview = false
+			m.statusMsg = ""
+			return m, nil
+		}
+		return m, nil
+	}
+
+	if m.themeMenu {
+		return m.handleThemeMenuKey(msg)
+	}
+
+	if m.showCopyMenu {
+		return m.handleCopyMenuKey(msg)
+	}
+
+	if m.leaderActive {
+		return m.handleLeaderKey(msg)
+	}
+
+	if m.bufferRenameActive {
+		return m.handleBufferRenameKey(msg)
+	}
+
+	if m.commandLineActive {
+		return m.handleCommandLineKey(msg)
+	}
+
+	if m.vim.searching {
+		return m.handleSearchKey(msg)
+	}
+
+	if m.focusedPane == PaneExplorer {
+		handled, cmd := m.handleExplorerActionKey(msg)
+		if handled {
+			return m, cmd
+		}
+	}
+
+	// When in Query pane with INSERT mode, route all keys directly to query editor
+	// This prevents global shortcuts like 'e', 'q', 'r' from interfering with typing
+	if m.focusedPane == PaneQuery && m.queryMode == QueryModeInsert {
+		// Check if autocomplete is visible and handle its keys
+		if m.autocomplete.Visible {
+			handled, suggestion := m.autocomplete.HandleKey(msg)
+			if handled {
+				if suggestion != "" {
+					// Apply the suggestion
+					m.applyAutocompleteSuggestion(suggestion)
+				}
+				return m, nil
+			}
+		}
+
+		switch msg.String() {
+		case "ctrl+enter":
+			return m, m.executeQuery()
+		case "esc":
+			m.autocomplete.Visible = false
+			m.signature = nil
+			m.finishInsert()
+			m.queryMode = QueryModeNormal
+			m.query.Blur()
+			m.stepBackFromInsert()
+			m.saveBuffers(false)
+			return m, nil
+		default:
+			var cmd tea.Cmd
+			m.query, cmd = m.query.Update(msg)
+			m.updateSignatureHint()
+			// Trigger autocomplete after typing
+			text := m.query.Value()
+			cursorPos := lineColToIndex(text, m.query.Line(), m.query.Column())
+			return m, tea.Batch(cmd, TriggerAutocomplete(text, cursorPos))
+		}
+	}
+
+	// When filtering results, route all keys to filter input/navigation.
+	// This prevents global shortcuts (e/q/r/space...) from hijacking typing.
+	if m.focusedPane == PaneResults && (m.resultsFilterActive || m.whereFilterActive) {
+		return m.handleResultsKey(msg)
+	}
+
+	// A partly typed editor command takes the next key, even one that is
+	// normally a global shortcut (de, 2e, rq)
+	if m.focusedPane == PaneQuery && m.vim.isPending() {
+		return m.routeKeyMsg(msg)
+	}
+
+	// Global key bindings (only processed when NOT in INSERT mode)
+	switch msg.String() {
+	case ":":
+		m.openCommandLine()
+		return m, nil
+	case "space":
+		// Show leader menu immediately
+		m.leaderActive = true
+		m.themeMenu = false
+		m.statusMsg = ""
+		return m, nil
+	case "e":
+		m.focusedPane = PaneExplorer
+		m.updateFocus()
+		return m, nil
+	case "q":
+		m.focusedPane = PaneQuery
+		m.updateFocus()
+		return m, nil
+	case "r":
+		m.focusedPane = PaneResults
+		m.updateFocus()
+		return m, nil
+	case "ctrl+enter":
+		if m.focusedPane == PaneQuery {
+			return m, m.executeQuery()
+		}
+	}
+
+	// Route to focused component
+	return m.routeKeyMsg(msg)
+}
+
 // renderHighlightedQuery renders the query editor content with SQL syntax highlighting
 // and a visible cursor, replacing the textarea's default View().
 func (m *BrowserModel) renderHighlightedQuery(width, height int) string {
@@ -580,6 +813,9 @@ func (m *BrowserModel) renderHighlightedQuery(width, height int) string {
 		lineStartIdx += len([]rune(lines[i])) + 1 // +1 for \n
 	}
 
+	// Validation error underline, as a rune range on one line
+	markRow, markFrom, markTo := m.validationMark()
+
 	for i := scrollY; i < visibleEnd; i++ {
 		line := lines[i]
 		lineRunes := []rune(line)
@@ -587,7 +823,15 @@ func (m *BrowserModel) renderHighlightedQuery(width, height int) string {
 		isCursorLine := showCursor && i == cursorRow
 		preLineComment := inBlockComment
 
-		highlighted, stillInComment := components.HighlightSQL(line, inBlockComment)
+		// highlight renders lineRunes[from:to], underlining the validation error
+		highlight := func(from, to int, bc bool) (string, bool) {
+			if i != markRow {
+				return components.HighlightSQL(string(lineRunes[from:to]), bc)
+			}
+			return highlightWithMark(lineRunes, from, to, bc, markFrom, markTo)
+		}
+
+		highlighted, stillInComment := highlight(0, lineLen, inBlockComment)
 		inBlockComment = stillInComment
 
 		// Check if this line has any visual selection
@@ -622,7 +866,7 @@ func (m *BrowserModel) renderHighlightedQuery(width, height int) string {
 			bc := preLineComment
 			if selColStart > 0 {
 				var seg string
-				seg, bc = components.HighlightSQL(string(lineRunes[:selColStart]), bc)
+				seg, bc = highlight(0, selColStart, bc)
 				parts += seg
 			}
 			if selColEnd > selColStart {
@@ -631,7 +875,7 @@ func (m *BrowserModel) renderHighlightedQuery(width, height int) string {
 				_, bc = components.HighlightSQL(selText, bc)
 			}
 			if selColEnd < lineLen {
-				seg, _ := components.HighlightSQL(string(lineRunes[selColEnd:]), bc)
+				seg, _ := highlight(selColEnd, lineLen, bc)
 				parts += seg
 			}
 			if lineLen == 0 {
@@ -661,11 +905,11 @@ func (m *BrowserModel) renderHighlightedQuery(width, height int) string {
 				var beforeHL, afterHL string
 				bc := preLineComment
 				if col > 0 {
-					beforeHL, bc = components.HighlightSQL(string(lineRunes[:col]), bc)
+					beforeHL, bc = highlight(0, col, bc)
 				}
 				bar := insertCursorStyle.Render("│")
 				if col < lineLen {
-					afterHL, _ = components.HighlightSQL(string(lineRunes[col:]), bc)
+					afterHL, _ = highlight(col, lineLen, bc)
 				}
 				fullLine := padToWidth(beforeHL+bar+afterHL, width)
 				rendered = append(rendered, cursorLineBg.Render(fullLine))
@@ -697,11 +941,11 @@ func (m *BrowserModel) renderHighlightedQuery(width, height int) string {
 		var beforeHL, afterHL string
 		bc := preLineComment
 		if col > 0 {
-			beforeHL, bc = components.HighlightSQL(string(lineRunes[:col]), bc)
+			beforeHL, bc = highlight(0, col, bc)
 		}
 		_, bc2 := components.HighlightSQL(string(lineRunes[col:col+1]), bc)
 		if col+1 < lineLen {
-			afterHL, _ = components.HighlightSQL(string(lineRunes[col+1:]), bc2)
+			afterHL, _ = highlight(col+1, lineLen, bc2)
 		}
 
 		cursorRendered := blockCursorStyle.Background(styles.BgLight).Render(string(lineRunes[col : col+1]))
@@ -748,6 +992,10 @@ func (m *BrowserModel) View() tea.View {
 	case QueryModeVisualLine:
 		queryTitle = "Query [VISUAL LINE]"
 	}
+	queryTitle += " " + m.bufferTitle()
+	if m.readOnly {
+		queryTitle += " [RO]"
+	}
 
 	// Render query with SQL syntax highlighting
 	queryInnerW := maxInt(1, qw-4)
@@ -757,7 +1005,7 @@ func (m *BrowserModel) View() tea.View {
 
 	// Render results
 	resultsContent := m.renderResults()
-	resultsPane := m.renderPane("Results", "r", resultsContent, rw, rh, m.focusedPane == PaneResults, styles.BgDefault)
+	resultsPane := m.renderPane(m.resultsTitle(), "r", resultsContent, rw, rh, m.focusedPane == PaneResults, styles.BgDefault)
 
 	// Combine right side panes vertically
 	rightSide := lipgloss.JoinVertical(
@@ -781,7 +1029,7 @@ func (m *BrowserModel) View() tea.View {
 			maxQueryView := m.renderHighlightedQuery(maxW, maxH)
 			main = m.renderPane(queryTitle, "q", maxQueryView, m.width, m.mainHeight(), m.focusedPane == PaneQuery, styles.BgDark)
 		case PaneResults:
-			main = m.renderPane("Results", "r", resultsContent, m.width, m.mainHeight(), m.focusedPane == PaneResults, styles.BgDefault)
+			main = m.renderPane(m.resultsTitle(), "r", resultsContent, m.width, m.mainHeight(), m.focusedPane == PaneResults, styles.BgDefault)
 		}
 	}
 
@@ -906,11 +1154,19 @@ func (m *BrowserModel) paneDimensions() (ew, eh, qw, qh, rw, rh int) {
 
 func (m *BrowserModel) renderContextFooter() string {
 	if m.leaderActive {
-		line := truncateToWidth("COMMANDS: e Explorer  f Maximize  c Connect  x Disconnect  t Theme  h Help  / Search  q Quit", m.width)
+		line := truncateToWidth("COMMANDS: e Explorer  f Maximize  n/b/B/d/R Buffers  E $EDITOR  c Connect  x Disconnect  t Theme  h Help  / Search  q Quit", m.width)
 		line = padToWidth(line, m.width)
 		return m.styles.StatusBar.Render(line)
 	}
 
+	if m.commandLineActive {
+		line := truncateToWidth(fmt.Sprintf(":%s_%s", m.commandLineInput, m.commandLineHint()), m.width)
+		if m.statusMsg != "" {
+			line = truncateToWidth(line+" | "+m.statusMsg, m.width)
+		}
+		return m.styles.StatusBar.Render(padToWidth(line, m.width))
+	}
+
 	var text string
 	switch m.focusedPane {
 	case PaneExplorer:
@@ -919,15 +1175,33 @@ func (m *BrowserModel) renderContextFooter() string {
 		switch m.queryMode {
 		case QueryModeInsert:
 			text = "Query INSERT: Esc→Normal  Enter Newline  Ctrl+Enter Execute"
+			if m.signature != nil {
+				text = renderSignatureHint(*m.signature, m.signatureArg)
+			}
 		case QueryModeVisual:
-			text = "Query VISUAL: y Yank  d Delete  c Change  >/< Indent  Esc→Normal"
+			text = "Query VISUAL: y Yank  d Delete  c Change  iw/i(/is Select  >/< Indent  = Format  ~/u/U Case  Esc→Normal"
 		case QueryModeVisualLine:
-			text = "Query VISUAL LINE: y Yank  d Delete  c Change  >/< Indent  Esc→Normal"
+			text = "Query VISUAL LINE: y Yank  d Delete  c Change  iw/i(/is Select  >/< Indent  = Format  ~/u/U Case  Esc→Normal"
 		default:
-			text = "Query NORMAL: i/a Insert  o Open  h/j/k/l Move  w/b Word  dd Del  yy Yank  p Paste  u Undo"
+			text = "Query NORMAL: i/a Insert  d/c/y{motion} Edit  ciw/das Objects  p Paste  / Search  . Repeat  u Undo  ^R Redo  =is Format  gt/gT Buffer  : Command"
+		}
+		if m.vim.searching {
+			prompt := "/"
+			if m.vim.searchBackward {
+				prompt = "?"
+			}
+			text = prompt + m.vim.searchInput + "_"
+		}
+		if errText := m.validationFooter(); errText != "" && m.signature == nil {
+			text = errText + " | " + text
+		}
+		if m.bufferRenameActive {
+			text = fmt.Sprintf("Rename buffer: %s_ | Enter save  Esc cancel", m.bufferRenameInput)
 		}
 	case PaneResults:
-		if m.resultsFilterActive {
+		if m.whereFilterActive {
+			text = fmt.Sprintf("WHERE %s_ | Enter apply  Esc cancel  (e.g. age > 30, name like %%ann%%, is null)", m.whereFilterInput)
+		} else if m.resultsFilterActive {
 			text = fmt.Sprintf("Filter: %s_ | Esc to clear", m.resultsFilter)
 		} else if m.resultsFilter != "" {
 			text = fmt.Sprintf("Filter: '%s' | Esc to clear", m.resultsFilter)
@@ -935,8 +1209,25 @@ func (m *BrowserModel) renderContextFooter() string {
 			text = "Preview: Esc or q to close"
 		} else if m.showCopyMenu {
 			text = "Copy Menu: c Cell, y Row, a All, e Export, Esc Cancel"
+			if m.selection != nil {
+				text = "Copy Block: t TSV, c CSV, m Markdown, Esc Cancel"
+			}
+		} else if m.selection != nil {
+			text = m.selectionStatus()
+		} else if m.recordView {
+			text = "Record: j/k Row  g/G First/Last  ^D/^U Scroll  v Preview  y Copy  esc Grid"
+		} else if m.chartView != nil {
+			text = "Chart: tab Bar/Line/Spark  x X column  1-9 Toggle Y  esc Grid"
+		} else if m.diffView != nil {
+			text = "Diff: j/k Row  h/l Col  K Key column  esc Grid  (:diff <col,...> sets the key)"
 		} else {
-			text = "Results: h/l Col  j/k Row  v Preview  d Delete  y Copy  / Filter  x Clear"
+			text = "Results: h/l Col  j/k Row  ⏎ Record  </> Width  - Hide  {/} Move  | Freeze  i Profile  c Chart  b/B Baseline/Diff  V Block  [/] Tab  p Pin  v Preview  d Delete  y Copy  / Filter  x Close"
+			if m.tableQuery != nil {
+				text = "Results: h/l Col  j/k Row  ⏎ Record  s Sort  S Add Sort  f Where  F Reset  i Profile  c Chart  b/B Baseline/Diff  [/] Tab  p Pin  v Preview  d Delete  y Copy  x Close"
+			}
+			if m.queryWatch != nil {
+				text += "  P Pause watch"
+			}
 		}
 	}
 
@@ -1002,7 +1293,7 @@ func (m *BrowserModel) handleCopyCommand(key string) (tea.Model, tea.Cmd) {
 		return m.copyAll()
 	case "e":
 		// Export placeholder
-		m.statusMsg = "Export: not implemented yet"
+		m.statusMsg = "Export: use :export <csv|tsv|json> <file>"
 		return m, nil
 	default:
 		m.statusMsg = ""
@@ -1043,7 +1334,7 @@ func (m *BrowserModel) copyCell() (tea.Model, tea.Cmd) {
 	}
 
 	value := fmt.Sprintf("%v", row[colIdx])
-	m.yankBuffer = value
+	m.setUnnamedRegister(value)
 	if !m.writeClipboard(value) {
 		return m, nil
 	}
@@ -1068,7 +1359,7 @@ func (m *BrowserModel) copyRow() (tea.Model, tea.Cmd) {
 		values = append(values, fmt.Sprintf("%v", cell))
 	}
 	value := strings.Join(values, "\t")
-	m.yankBuffer = value
+	m.setUnnamedRegister(value)
 	if !m.writeClipboard(value) {
 		return m, nil
 	}
@@ -1099,7 +1390,7 @@ func (m *BrowserModel) copyAll() (tea.Model, tea.Cmd) {
 	}
 
 	value := strings.Join(lines, "\n")
-	m.yankBuffer = value
+	m.setUnnamedRegister(value)
 	if !m.writeClipboard(value) {
 		return m, nil
 	}
@@ -1117,6 +1408,19 @@ func (m *BrowserModel) handleCopyMenuKey(msg tea.KeyPressMsg) (tea.Model, tea.Cm
 
 	m.showCopyMenu = false
 
+	if m.selection != nil {
+		switch msg.String() {
+		case "t", "y":
+			return m.copySelection("tsv")
+		case "c":
+			return m.copySelection("csv")
+		case "m":
+			return m.copySelection("markdown")
+		}
+		m.statusMsg = ""
+		return m, nil
+	}
+
 	switch msg.String() {
 	case "c":
 		return m.copyCell()
@@ -1125,7 +1429,7 @@ func (m *BrowserModel) handleCopyMenuKey(msg tea.KeyPressMsg) (tea.Model, tea.Cm
 	case "a":
 		return m.copyAll()
 	case "e":
-		m.statusMsg = "Export: not implemented yet"
+		m.statusMsg = "Export: use :export <csv|tsv|json> <file>"
 		return m, nil
 	default:
 		m.statusMsg = ""
@@ -1173,12 +1477,33 @@ func (m *BrowserModel) executeLeaderCommand(key string) (tea.Model, tea.Cmd) {
 		m.statusMsg = "Preview with j/k, Enter to save, Esc to cancel"
 		return m, nil
 	case "h", "?":
-		m.statusMsg = "Help: e/q/r focus, space command menu, enter run query in NORMAL"
+		m.statusMsg = "Help: e/q/r focus, space command menu, : command line (:help), enter run query in NORMAL"
 		return m, nil
 	case "/":
 		m.statusMsg = "Search: not implemented yet"
 		return m, nil
+	case "n":
+		m.newBuffer()
+		return m, nil
+	case "b":
+		m.switchBuffer(1)
+		return m, nil
+	case "B":
+		m.switchBuffer(-1)
+		return m, nil
+	case "d":
+		m.closeBuffer()
+		return m, nil
+	case "E":
+		return m, m.openExternalEditor(false)
+	case "R":
+		m.bufferRenameActive = true
+		m.bufferRenameInput = m.currentBuffer().Name
+		m.focusedPane = PaneQuery
+		m.updateFocus()
+		return m, nil
 	case "q":
+		m.saveBuffers(true)
 		return m, tea.Quit
 	default:
 		m.statusMsg = ""
@@ -1411,8 +1736,21 @@ func (m *BrowserModel) renderWithAutocomplete(base string) string {
 
 	baseLayer := lipgloss.NewLayer(base)
 	menuLayer := lipgloss.NewLayer(menu).X(x).Y(y).Z(1)
+	layers := []*lipgloss.Layer{baseLayer, menuLayer}
 
-	comp := lipgloss.NewCompositor(baseLayer, menuLayer)
+	// Documentation for the selected suggestion goes to the right of the list,
+	// or to the left when there is no room
+	if doc := m.autocomplete.RenderDoc(); doc != "" {
+		docX := x + lipgloss.Width(menu)
+		if docX+lipgloss.Width(doc) > m.width {
+			docX = x - lipgloss.Width(doc)
+		}
+		if docX >= 0 {
+			layers = append(layers, lipgloss.NewLayer(doc).X(docX).Y(y).Z(1))
+		}
+	}
+
+	comp := lipgloss.NewCompositor(layers...)
 	return comp.Render()
 }
 
@@ -1465,6 +1803,14 @@ func buildLeaderMenuLines() []string {
 		textStyle.Render("  " + keyStyle.Render("e") + textStyle.Render("  Toggle Explorer")),
 		textStyle.Render("  " + keyStyle.Render("f") + textStyle.Render("  Toggle Maximize")),
 		textStyle.Render(""),
+		headStyle.Render("Buffers"),
+		textStyle.Render("  " + keyStyle.Render("n") + textStyle.Render("  New Buffer")),
+		textStyle.Render("  " + keyStyle.Render("b") + textStyle.Render("  Next Buffer")),
+		textStyle.Render("  " + keyStyle.Render("B") + textStyle.Render("  Previous Buffer")),
+		textStyle.Render("  " + keyStyle.Render("d") + textStyle.Render("  Close Buffer")),
+		textStyle.Render("  " + keyStyle.Render("R") + textStyle.Render("  Rename Buffer")),
+		textStyle.Render("  " + keyStyle.Render("E") + textStyle.Render("  Edit in $EDITOR")),
+		textStyle.Render(""),
 		headStyle.Render("Connection"),
 		textStyle.Render("  " + keyStyle.Render("c") + textStyle.Render("  Connect")),
 		textStyle.Render("  " + keyStyle.Render("x") + textStyle.Render("  Disconnect")),
@@ -1546,11 +1892,20 @@ func (m *BrowserModel) renderWithPreview(base string) string {
 		return base
 	}
 
-	// Format content with word wrapping
-	boxWidth := minInt(60, m.width-10)
-	contentLines := wrapText(m.previewContent, boxWidth-4)
-
-	preview := renderDialogBox(m.previewTitle, contentLines, "esc Close", boxWidth)
+	var preview string
+	var boxWidth int
+	if m.jsonView != nil {
+		preview, boxWidth = m.renderJSONView()
+	} else if m.hexView != nil {
+		preview, boxWidth = m.renderHexView()
+	} else if m.profileView != nil {
+		preview, boxWidth = m.renderProfileView()
+	} else {
+		// Format content with word wrapping
+		boxWidth = minInt(60, m.width-10)
+		contentLines := wrapText(m.previewContent, boxWidth-4)
+		preview = renderDialogBox(m.previewTitle, contentLines, "esc Close", boxWidth)
+	}
 
 	// Center position
 	boxH := len(strings.Split(preview, "\n"))
@@ -1596,7 +1951,10 @@ func wrapText(text string, maxWidth int) []string {
 	return lines
 }
 
-type RequestConnectMsg struct{}
+type RequestConnectMsg struct {
+	// Path, when set, is connected to directly instead of showing the form
+	Path string
+}
 
 func clipText(content string, width, height int) string {
 	lines := strings.Split(content, "\n")
@@ -1730,551 +2088,44 @@ func (m *BrowserModel) handleQueryKey(msg tea.KeyPressMsg) (tea.Model, tea.Cmd)
 	return m, nil
 }
 
-// handleQueryNormalMode handles keys in NORMAL mode
-func (m *BrowserModel) handleQueryNormalMode(msg tea.KeyPressMsg) (tea.Model, tea.Cmd) {
-	k := msg.String()
-
-	// Handle pending multi-key commands (gg, dd, yy)
-	if m.pendingNormalKey != "" {
-		pending := m.pendingNormalKey
-		m.pendingNormalKey = ""
-		m.statusMsg = ""
-		switch pending + k {
-		case "gg":
-			m.query.MoveToBegin()
-			return m, nil
-		case "dd":
-			m.deleteCurrentLine()
-			return m, nil
-		case "yy":
-			m.yankCurrentLine()
-			return m, nil
-		default:
-			// Invalid combo, ignore
-			return m, nil
+func (m *BrowserModel) selectedQueryRange() (start, end int, ok bool) {
+	text := m.query.Value()
+	if text == "" {
+		return 0, 0, false
+	}
+	start = lineColToIndex(text, m.visualStart.row, m.visualStart.col)
+	end = lineColToIndex(text, m.visualEnd.row, m.visualEnd.col)
+	if m.queryMode == QueryModeVisualLine {
+		start = lineColToIndex(text, minInt(m.visualStart.row, m.visualEnd.row), 0)
+		endLine := maxInt(m.visualStart.row, m.visualEnd.row)
+		lines := strings.Split(text, "\n")
+		if endLine >= len(lines) {
+			endLine = len(lines) - 1
 		}
+		end = lineColToIndex(text, endLine, len(lines[endLine]))
 	}
+	if start > end {
+		start, end = end, start
+	}
+	// Visual selection is inclusive of the character under the cursor
+	if m.queryMode == QueryModeVisual && end < len([]rune(text)) {
+		end++
+	}
+	if start == end {
+		return 0, 0, false
+	}
+	return start, end, true
+}
 
-	switch k {
-	// === Mode switching ===
-	case "i":
-		m.queryMode = QueryModeInsert
-		m.query.Focus()
-		return m, nil
-	case "a":
-		// Append: move cursor one right, then insert
-		col := m.query.Column()
-		lines := strings.Split(m.query.Value(), "\n")
-		row := m.query.Line()
-		if row < len(lines) && col < len([]rune(lines[row])) {
-			m.query.SetCursorColumn(col + 1)
-		}
-		m.queryMode = QueryModeInsert
-		m.query.Focus()
-		return m, nil
-	case "I":
-		// Insert at beginning of line
-		m.query.CursorStart()
-		m.queryMode = QueryModeInsert
-		m.query.Focus()
-		return m, nil
-	case "A":
-		// Append at end of line
-		m.query.CursorEnd()
-		m.queryMode = QueryModeInsert
-		m.query.Focus()
-		return m, nil
-	case "o":
-		// Open line below
-		m.query.CursorEnd()
-		m.query.InsertString("\n")
-		m.queryMode = QueryModeInsert
-		m.query.Focus()
-		return m, nil
-	case "O":
-		// Open line above
-		m.query.CursorStart()
-		m.query.InsertString("\n")
-		m.query.CursorUp()
-		m.queryMode = QueryModeInsert
-		m.query.Focus()
-		return m, nil
-	case "v":
-		m.queryMode = QueryModeVisual
-		m.visualStart.row = m.query.Line()
-		m.visualStart.col = m.query.Column()
-		m.visualEnd = m.visualStart
-		m.statusMsg = "-- VISUAL --"
-		return m, nil
-	case "V":
-		m.queryMode = QueryModeVisualLine
-		m.visualStart.row = m.query.Line()
-		m.visualStart.col = 0
-		m.visualEnd = m.visualStart
-		m.statusMsg = "-- VISUAL LINE --"
-		return m, nil
-
-	// === Navigation ===
-	case "h", "left":
-		col := m.query.Column()
-		if col > 0 {
-			m.query.SetCursorColumn(col - 1)
-		}
-		return m, nil
-	case "j", "down":
-		m.query.CursorDown()
-		return m, nil
-	case "k", "up":
-		m.query.CursorUp()
-		return m, nil
-	case "l", "right":
-		col := m.query.Column()
-		lines := strings.Split(m.query.Value(), "\n")
-		row := m.query.Line()
-		if row < len(lines) && col < len([]rune(lines[row]))-1 {
-			m.query.SetCursorColumn(col + 1)
-		}
-		return m, nil
-	case "w":
-		m.wordForward()
-		return m, nil
-	case "b":
-		m.wordBackward()
-		return m, nil
-	case "0", "home":
-		m.query.CursorStart()
-		return m, nil
-	case "$", "end":
-		m.query.CursorEnd()
-		return m, nil
-	case "G":
-		m.query.MoveToEnd()
-		return m, nil
-	case "pgup":
-		m.query.PageUp()
-		return m, nil
-	case "pgdown":
-		m.query.PageDown()
-		return m, nil
-
-	// === Multi-key command starters ===
-	case "g":
-		m.pendingNormalKey = "g"
-		m.statusMsg = "g"
-		return m, nil
-	case "d":
-		m.pendingNormalKey = "d"
-		m.statusMsg = "d"
-		return m, nil
-	case "y":
-		m.pendingNormalKey = "y"
-		m.statusMsg = "y"
-		return m, nil
-
-	// === Editing ===
-	case "x":
-		m.deleteCharAtCursor()
-		return m, nil
-	case "p":
-		if m.yankBuffer != "" {
-			m.pasteAfter()
-		}
-		return m, nil
-	case "P":
-		if m.yankBuffer != "" {
-			m.pasteBefore()
-		}
-		return m, nil
-	case "u":
-		// Undo: pass ctrl+z to textarea
-		undoMsg := tea.KeyPressMsg(tea.KeyPressMsg{Mod: tea.ModCtrl, Code: 'z'})
-		m.query.Focus()
-		m.query, _ = m.query.Update(undoMsg)
-		m.query.Blur()
-		return m, nil
-
-	// === Execute query ===
-	case "enter":
-		return m, m.executeQuery()
-
-	default:
-		return m, nil
-	}
-}
-
-// wordForward moves the cursor to the start of the next word.
-func (m *BrowserModel) wordForward() {
-	text := m.query.Value()
-	if text == "" {
-		return
-	}
-	runes := []rune(text)
-	idx := lineColToIndex(text, m.query.Line(), m.query.Column())
-	n := len(runes)
-
-	// Skip current word characters
-	for idx < n && !isWordBoundary(runes[idx]) {
-		idx++
-	}
-	// Skip whitespace/punctuation
-	for idx < n && isWordBoundary(runes[idx]) {
-		idx++
-	}
-
-	row, col := indexToLineCol(text, idx)
-	m.setQueryCursor(row, col)
-}
-
-// wordBackward moves the cursor to the start of the previous word.
-func (m *BrowserModel) wordBackward() {
-	text := m.query.Value()
-	if text == "" {
-		return
-	}
-	runes := []rune(text)
-	idx := lineColToIndex(text, m.query.Line(), m.query.Column())
-
-	if idx > len(runes) {
-		idx = len(runes)
-	}
-	// Move back past any whitespace/punctuation
-	for idx > 0 && isWordBoundary(runes[idx-1]) {
-		idx--
-	}
-	// Move back through word characters
-	for idx > 0 && !isWordBoundary(runes[idx-1]) {
-		idx--
-	}
-
-	row, col := indexToLineCol(text, idx)
-	m.setQueryCursor(row, col)
-}
-
-func isWordBoundary(r rune) bool {
-	return r == ' ' || r == '\t' || r == '\n' || r == '\r' ||
-		r == ',' || r == ';' || r == '(' || r == ')' ||
-		r == '.' || r == '=' || r == '<' || r == '>' ||
-		r == '+' || r == '-' || r == '*' || r == '/' ||
-		r == '\'' || r == '"' || r == '[' || r == ']'
-}
-
-// deleteCharAtCursor deletes the character at the cursor (like vim 'x').
-func (m *BrowserModel) deleteCharAtCursor() {
-	text := m.query.Value()
-	if text == "" {
-		return
-	}
-	runes := []rune(text)
-	idx := lineColToIndex(text, m.query.Line(), m.query.Column())
-	if idx >= len(runes) {
-		return
-	}
-	newText := string(runes[:idx]) + string(runes[idx+1:])
-	m.query.SetValue(newText)
-	row, col := indexToLineCol(newText, idx)
-	m.setQueryCursor(row, col)
-}
-
-// deleteCurrentLine deletes the current line (like vim 'dd').
-func (m *BrowserModel) deleteCurrentLine() {
-	text := m.query.Value()
-	if text == "" {
-		return
-	}
-	lines := strings.Split(text, "\n")
-	row := m.query.Line()
-	if row >= len(lines) {
-		return
-	}
-
-	m.yankBuffer = lines[row] + "\n"
-
-	newLines := make([]string, 0, len(lines)-1)
-	newLines = append(newLines, lines[:row]...)
-	if row+1 < len(lines) {
-		newLines = append(newLines, lines[row+1:]...)
-	}
-
-	if len(newLines) == 0 {
-		m.query.SetValue("")
-		m.query.MoveToBegin()
-		return
-	}
-	m.query.SetValue(strings.Join(newLines, "\n"))
-	if row >= len(newLines) {
-		row = len(newLines) - 1
-	}
-	m.setQueryCursor(row, 0)
-}
-
-// yankCurrentLine copies the current line to the yank buffer (like vim 'yy').
-func (m *BrowserModel) yankCurrentLine() {
-	text := m.query.Value()
-	if text == "" {
-		return
-	}
-	lines := strings.Split(text, "\n")
-	row := m.query.Line()
-	if row >= len(lines) {
-		return
-	}
-	m.yankBuffer = lines[row] + "\n"
-	m.statusMsg = "1 line yanked"
-}
-
-// pasteAfter pastes the yank buffer after the cursor (like vim 'p').
-func (m *BrowserModel) pasteAfter() {
-	if m.yankBuffer == "" {
-		return
-	}
-	// If yank buffer ends with \n, it's a line yank — paste on line below
-	if strings.HasSuffix(m.yankBuffer, "\n") {
-		text := m.query.Value()
-		lines := strings.Split(text, "\n")
-		row := m.query.Line()
-		content := strings.TrimSuffix(m.yankBuffer, "\n")
-
-		newLines := make([]string, 0, len(lines)+1)
-		newLines = append(newLines, lines[:row+1]...)
-		newLines = append(newLines, content)
-		if row+1 < len(lines) {
-			newLines = append(newLines, lines[row+1:]...)
-		}
-		m.query.SetValue(strings.Join(newLines, "\n"))
-		m.setQueryCursor(row+1, 0)
-	} else {
-		// Character-wise paste after cursor
-		text := m.query.Value()
-		idx := lineColToIndex(text, m.query.Line(), m.query.Column())
-		if idx < len(text) {
-			idx++ // paste after
-		}
-		newText := text[:idx] + m.yankBuffer + text[idx:]
-		m.query.SetValue(newText)
-		row, col := indexToLineCol(newText, idx+len(m.yankBuffer)-1)
-		m.setQueryCursor(row, col)
-	}
-}
-
-// pasteBefore pastes the yank buffer before the cursor (like vim 'P').
-func (m *BrowserModel) pasteBefore() {
-	if m.yankBuffer == "" {
-		return
-	}
-	if strings.HasSuffix(m.yankBuffer, "\n") {
-		text := m.query.Value()
-		lines := strings.Split(text, "\n")
-		row := m.query.Line()
-		content := strings.TrimSuffix(m.yankBuffer, "\n")
-
-		newLines := make([]string, 0, len(lines)+1)
-		newLines = append(newLines, lines[:row]...)
-		newLines = append(newLines, content)
-		newLines = append(newLines, lines[row:]...)
-		m.query.SetValue(strings.Join(newLines, "\n"))
-		m.setQueryCursor(row, 0)
-	} else {
-		text := m.query.Value()
-		idx := lineColToIndex(text, m.query.Line(), m.query.Column())
-		newText := text[:idx] + m.yankBuffer + text[idx:]
-		m.query.SetValue(newText)
-		row, col := indexToLineCol(newText, idx+len(m.yankBuffer)-1)
-		m.setQueryCursor(row, col)
-	}
-}
-
-// handleQueryVisualMode handles keys in VISUAL mode
-func (m *BrowserModel) handleQueryVisualMode(msg tea.KeyPressMsg) (tea.Model, tea.Cmd) {
-	// Helper to update visual end after cursor movement
-	updateEnd := func() {
-		m.visualEnd.row = m.query.Line()
-		m.visualEnd.col = m.query.Column()
-	}
-
-	switch msg.String() {
-	case "esc":
-		m.queryMode = QueryModeNormal
-		m.statusMsg = ""
-		return m, nil
-	case "y":
-		selected := m.getSelectedQueryText()
-		if selected != "" {
-			m.yankBuffer = selected
-		}
-		m.queryMode = QueryModeNormal
-		m.statusMsg = "yanked"
-		return m, nil
-	case "d", "x":
-		selected := m.getSelectedQueryText()
-		if selected != "" {
-			m.yankBuffer = selected
-		}
-		m.deleteSelectedQueryText()
-		m.queryMode = QueryModeNormal
-		m.statusMsg = "deleted"
-		return m, nil
-	case ">":
-		m.indentSelectedLines(true)
-		m.queryMode = QueryModeNormal
-		return m, nil
-	case "<":
-		m.indentSelectedLines(false)
-		m.queryMode = QueryModeNormal
-		return m, nil
-	case "p":
-		if m.yankBuffer != "" {
-			m.deleteSelectedQueryText()
-			m.query.InsertString(m.yankBuffer)
-		}
-		m.queryMode = QueryModeNormal
-		return m, nil
-	case "c":
-		m.deleteSelectedQueryText()
-		m.queryMode = QueryModeInsert
-		m.query.Focus()
-		return m, nil
-
-	// Navigation — move cursor explicitly and extend selection
-	case "h", "left":
-		col := m.query.Column()
-		if col > 0 {
-			m.query.SetCursorColumn(col - 1)
-		}
-		updateEnd()
-		return m, nil
-	case "j", "down":
-		m.query.CursorDown()
-		updateEnd()
-		return m, nil
-	case "k", "up":
-		m.query.CursorUp()
-		updateEnd()
-		return m, nil
-	case "l", "right":
-		col := m.query.Column()
-		lines := strings.Split(m.query.Value(), "\n")
-		row := m.query.Line()
-		if row < len(lines) && col < len([]rune(lines[row]))-1 {
-			m.query.SetCursorColumn(col + 1)
-		}
-		updateEnd()
-		return m, nil
-	case "w":
-		m.wordForward()
-		updateEnd()
-		return m, nil
-	case "b":
-		m.wordBackward()
-		updateEnd()
-		return m, nil
-	case "0", "home":
-		m.query.CursorStart()
-		updateEnd()
-		return m, nil
-	case "$", "end":
-		m.query.CursorEnd()
-		updateEnd()
-		return m, nil
-	case "G":
-		m.query.MoveToEnd()
-		updateEnd()
-		return m, nil
-	case "g":
-		// gg in visual mode — move to beginning
-		m.query.MoveToBegin()
-		updateEnd()
-		return m, nil
-	default:
-		return m, nil
-	}
-}
-
-func (m *BrowserModel) selectedQueryRange() (start, end int, ok bool) {
-	text := m.query.Value()
-	if text == "" {
-		return 0, 0, false
-	}
-	start = lineColToIndex(text, m.visualStart.row, m.visualStart.col)
-	end = lineColToIndex(text, m.visualEnd.row, m.visualEnd.col)
-	if m.queryMode == QueryModeVisualLine {
-		start = lineColToIndex(text, minInt(m.visualStart.row, m.visualEnd.row), 0)
-		endLine := maxInt(m.visualStart.row, m.visualEnd.row)
-		lines := strings.Split(text, "\n")
-		if endLine >= len(lines) {
-			endLine = len(lines) - 1
-		}
-		end = lineColToIndex(text, endLine, len(lines[endLine]))
-	}
-	if start > end {
-		start, end = end, start
-	}
-	// Visual selection is inclusive of the character under the cursor
-	if m.queryMode == QueryModeVisual && end < len([]rune(text)) {
-		end++
-	}
-	if start == end {
-		return 0, 0, false
-	}
-	return start, end, true
-}
-
-func (m *BrowserModel) getSelectedQueryText() string {
-	text := m.query.Value()
-	start, end, ok := m.selectedQueryRange()
-	if !ok || start < 0 || end > len(text) {
-		return ""
-	}
-	return text[start:end]
-}
-
-func (m *BrowserModel) deleteSelectedQueryText() {
-	text := m.query.Value()
-	start, end, ok := m.selectedQueryRange()
-	if !ok || start < 0 || end > len(text) {
-		return
-	}
-	newText := text[:start] + text[end:]
-	m.query.SetValue(newText)
-	row, col := indexToLineCol(newText, start)
-	m.setQueryCursor(row, col)
-}
-
-func (m *BrowserModel) indentSelectedLines(indent bool) {
-	text := m.query.Value()
-	if text == "" {
-		return
-	}
-	startLine := minInt(m.visualStart.row, m.visualEnd.row)
-	endLine := maxInt(m.visualStart.row, m.visualEnd.row)
-	lines := strings.Split(text, "\n")
-	if startLine < 0 {
-		startLine = 0
-	}
-	if endLine >= len(lines) {
-		endLine = len(lines) - 1
-	}
-	for i := startLine; i <= endLine; i++ {
-		if indent {
-			lines[i] = "  " + lines[i]
-		} else if strings.HasPrefix(lines[i], "  ") {
-			lines[i] = lines[i][2:]
-		} else if strings.HasPrefix(lines[i], " ") {
-			lines[i] = lines[i][1:]
-		}
-	}
-	m.query.SetValue(strings.Join(lines, "\n"))
-	m.setQueryCursor(startLine, 0)
-}
-
-func (m *BrowserModel) setQueryCursor(line, col int) {
-	if line < 0 {
-		line = 0
-	}
-	if col < 0 {
-		col = 0
-	}
-	m.query.MoveToBegin()
-	for i := 0; i < line; i++ {
+func (m *BrowserModel) setQueryCursor(line, col int) {
+	if line < 0 {
+		line = 0
+	}
+	if col < 0 {
+		col = 0
+	}
+	m.query.MoveToBegin()
+	for i := 0; i < line; i++ {
 		m.query.CursorDown()
 	}
 	m.query.SetCursorColumn(col)
@@ -2332,6 +2183,10 @@ func minInt(a, b int) int {
 
 // handleResultsKey handles keys when Results pane is focused
 func (m *BrowserModel) handleResultsKey(msg tea.KeyPressMsg) (tea.Model, tea.Cmd) {
+	if m.whereFilterActive {
+		return m.handleWhereFilterKey(msg)
+	}
+
 	if m.resultsFilterActive {
 		switch msg.String() {
 		case "esc":
@@ -2357,10 +2212,44 @@ func (m *BrowserModel) handleResultsKey(msg tea.KeyPressMsg) (tea.Model, tea.Cmd
 		return m, nil
 	}
 
+	if m.recordView {
+		if handled, cmd := m.handleRecordKey(msg); handled {
+			return m, cmd
+		}
+	}
+	if m.chartView != nil {
+		if handled, cmd := m.handleChartKey(msg); handled {
+			return m, cmd
+		}
+	}
+	if m.diffView != nil {
+		if handled, cmd := m.handleDiffKey(msg); handled {
+			return m, cmd
+		}
+	}
+
 	switch msg.String() {
+	case "enter":
+		// Record: show the selected row as a vertical list
+		m.toggleRecordView()
+		return m, nil
 	case "v":
 		// Preview: show selected cell value
 		return m.showPreviewDialog()
+	case "i":
+		// Profile: statistics for the highlighted column
+		return m, m.profileColumn()
+	case "c":
+		// Chart: plot the results
+		m.openChart("")
+		return m, nil
+	case "b":
+		// Baseline: keep this result to diff later ones against
+		m.markBaseline()
+		return m, nil
+	case "B":
+		// Diff: compare this result with the baseline
+		return m, m.openDiff(nil)
 	case "d":
 		// Delete: create DELETE SQL query
 		return m.createDeleteQuery()
@@ -2368,28 +2257,86 @@ func (m *BrowserModel) handleResultsKey(msg tea.KeyPressMsg) (tea.Model, tea.Cmd
 		// Copy: open copy menu
 		m.showCopyMenu = true
 		m.statusMsg = "COPY: c Cell, y Row, a All, e Export, esc Cancel"
+		if m.selection != nil {
+			m.statusMsg = "COPY block: t TSV, c CSV, m Markdown, esc Cancel"
+		}
+		return m, nil
+	case "V":
+		// Block: select a rectangle of cells
+		m.toggleSelection()
 		return m, nil
 	case "h", "left":
-		// Move left (previous column)
-		if m.resultsCursorCol > 0 {
-			m.resultsCursorCol--
-			// Auto-scroll if needed
-			if m.resultsCursorCol < m.resultsScrollCol {
-				m.resultsScrollCol = m.resultsCursorCol
-			}
-		}
+		// Move left (previous column); the grid scrolls to keep it in view
+		m.moveColumnCursor(-1)
 		return m, nil
 	case "l", "right":
 		// Move right (next column)
-		cols := m.results.Columns()
-		if m.resultsCursorCol < len(cols)-1 {
-			m.resultsCursorCol++
+		m.moveColumnCursor(1)
+		return m, nil
+	case "<", ">":
+		// Narrow or widen the highlighted column
+		if msg.String() == "<" {
+			m.resizeColumn(-columnWidthStep)
+		} else {
+			m.resizeColumn(columnWidthStep)
 		}
 		return m, nil
+	case "=":
+		m.autoFitColumn()
+		return m, nil
+	case "-":
+		m.hideColumn()
+		return m, nil
+	case "+":
+		m.showHiddenColumns()
+		return m, nil
+	case "{":
+		m.moveColumn(-1)
+		return m, nil
+	case "}":
+		m.moveColumn(1)
+		return m, nil
+	case "|":
+		m.freezeColumns()
+		return m, nil
 	case "x":
-		// Clear: clear results
-		m.clearResults()
+		// Close the active result tab
+		m.closeResultTab()
+		return m, nil
+	case "[":
+		m.switchResultTab(-1)
+		return m, nil
+	case "]":
+		m.switchResultTab(1)
+		return m, nil
+	case "p":
+		m.togglePinResultTab()
+		return m, nil
+	case "P":
+		m.toggleQueryWatchPause()
+		return m, nil
+	case "s", "S":
+		// Sort: server-side ORDER BY on the highlighted column
+		return m.sortByCurrentColumn(msg.String() == "S")
+	case "f":
+		// Where: structured server-side filter bar
+		if m.tableQuery == nil {
+			m.statusMsg = "Where filter is only available for tables opened from the explorer"
+			return m, nil
+		}
+		m.whereFilterActive = true
+		m.whereFilterInput = m.tableQuery.FilterText
 		return m, nil
+	case "F":
+		// Reset server-side sort and filter
+		if m.tableQuery == nil || (len(m.tableQuery.Sort) == 0 && len(m.tableQuery.Filters) == 0) {
+			return m, nil
+		}
+		m.tableQuery.Sort = nil
+		m.tableQuery.Filters = nil
+		m.tableQuery.FilterText = ""
+		m.statusMsg = "Sort and filter cleared"
+		return m, m.rerunTableQuery()
 	case "/":
 		// Filter: start filtering
 		m.resultsFilter = ""
@@ -2397,6 +2344,10 @@ func (m *BrowserModel) handleResultsKey(msg tea.KeyPressMsg) (tea.Model, tea.Cmd
 		m.statusMsg = "Filter: type to search, esc to clear"
 		return m, nil
 	case "esc":
+		if m.selection != nil {
+			m.toggleSelection()
+			return m, nil
+		}
 		if m.resultsFilter != "" {
 			m.resultsFilter = ""
 			m.applyFilter()
@@ -2431,6 +2382,78 @@ func (m *BrowserModel) handleResultsKey(msg tea.KeyPressMsg) (tea.Model, tea.Cmd
 	return m, nil
 }
 
+// handleWhereFilterKey handles typing in the structured filter bar.
+func (m *BrowserModel) handleWhereFilterKey(msg tea.KeyPressMsg) (tea.Model, tea.Cmd) {
+	switch msg.String() {
+	case "esc":
+		m.whereFilterActive = false
+		m.whereFilterInput = ""
+		m.statusMsg = ""
+		return m, nil
+	case "enter":
+		active := m.activeResultSet()
+		if m.tableQuery == nil || active == nil {
+			m.whereFilterActive = false
+			return m, nil
+		}
+		column := ""
+		if m.resultsCursorCol < len(active.Columns) {
+			column = active.Columns[m.resultsCursorCol]
+		}
+		filters, err := parseFilterExpr(m.whereFilterInput, active.Columns, column)
+		if err != nil {
+			m.statusMsg = "Filter: " + err.Error()
+			return m, nil
+		}
+		m.whereFilterActive = false
+		m.tableQuery.Filters = filters
+		m.tableQuery.FilterText = strings.TrimSpace(m.whereFilterInput)
+		m.statusMsg = ""
+		return m, m.rerunTableQuery()
+	case "backspace", "ctrl+h":
+		r := []rune(m.whereFilterInput)
+		if len(r) > 0 {
+			m.whereFilterInput = string(r[:len(r)-1])
+		}
+	case "ctrl+u":
+		m.whereFilterInput = ""
+	default:
+		if len(msg.Text) > 0 {
+			m.whereFilterInput += msg.Text
+		}
+	}
+	return m, nil
+}
+
+// sortByCurrentColumn toggles server-side ordering on the highlighted column.
+// With additive set the column is added as a further sort key.
+func (m *BrowserModel) sortByCurrentColumn(additive bool) (tea.Model, tea.Cmd) {
+	active := m.activeResultSet()
+	if active == nil || len(active.Columns) == 0 {
+		return m, nil
+	}
+	if m.tableQuery == nil {
+		m.statusMsg = "Sort is only available for tables opened from the explorer"
+		return m, nil
+	}
+	col := m.resultsCursorCol
+	if col >= len(active.Columns) {
+		col = len(active.Columns) - 1
+	}
+	m.tableQuery.toggleSort(active.Columns[col], additive)
+	return m, m.rerunTableQuery()
+}
+
+// rerunTableQuery rewrites the editor with the current table query and runs it.
+func (m *BrowserModel) rerunTableQuery() tea.Cmd {
+	if m.tableQuery == nil {
+		return nil
+	}
+	m.query.SetValue(m.tableQuery.SQL())
+	m.keepResultsCursor = true
+	return m.executeQuery()
+}
+
 // showPreviewDialog shows a preview of the selected cell value
 func (m *BrowserModel) showPreviewDialog() (tea.Model, tea.Cmd) {
 	active := m.activeResultSet()
@@ -2457,6 +2480,18 @@ func (m *BrowserModel) showPreviewDialog() (tea.Model, tea.Cmd) {
 		colName = cols[colIdx].Title
 	}
 
+	// Binary values open as a hex dump, JSON documents as a foldable tree
+	if raw := active.Rows[cursor]; colIdx < len(raw) {
+		colType := columnType(active, colIdx)
+		if data, ok := blobValue(raw[colIdx], colType); ok {
+			m.openHexViewer("BLOB: "+colName, data)
+			return m, nil
+		}
+		if text, ok := jsonCellText(raw[colIdx], colType); ok && m.openJSONViewer("JSON: "+colName, text) {
+			return m, nil
+		}
+	}
+
 	m.showPreview = true
 	m.previewTitle = fmt.Sprintf("Preview: %s", colName)
 	m.previewContent = value
@@ -2549,6 +2584,8 @@ func (m *BrowserModel) extractTableNameFromQuery(query string) string {
 // clearResults clears the results section
 func (m *BrowserModel) clearResults() {
 	m.currentResults = nil
+	m.queryError = ""
+	m.tableQuery = nil
 	m.filteredResults = nil
 	m.resultsFilter = ""
 	m.resultsFilterActive = false
@@ -2564,6 +2601,7 @@ func (m *BrowserModel) applyFilter() {
 	if m.currentResults == nil {
 		return
 	}
+	m.selection = nil
 
 	if m.resultsFilter == "" {
 		m.filteredResults = nil
@@ -2577,8 +2615,8 @@ func (m *BrowserModel) applyFilter() {
 
 	for _, row := range m.currentResults.Rows {
 		// Check if any cell contains the filter text
-		for _, cell := range row {
-			cellStr := strings.ToLower(fmt.Sprintf("%v", cell))
+		for j, cell := range row {
+			cellStr := strings.ToLower(cellText(cell, columnType(m.currentResults, j)))
 			if strings.Contains(cellStr, filter) {
 				filteredRows = append(filteredRows, row)
 				break
@@ -2599,18 +2637,7 @@ func (m *BrowserModel) applyFilter() {
 	for i, row := range filteredRows {
 		rowData := make([]string, len(row))
 		for j, val := range row {
-			if val == nil {
-				rowData[j] = "NULL"
-			} else {
-				switch v := val.(type) {
-				case string:
-					rowData[j] = v
-				case []byte:
-					rowData[j] = string(v)
-				default:
-					rowData[j] = fmt.Sprintf("%v", val)
-				}
-			}
+			rowData[j] = cellText(val, columnType(m.currentResults, j))
 		}
 		rows[i] = rowData
 	}
@@ -2649,27 +2676,38 @@ func highlightFilterMatch(text, filter string) string {
 	return out.String()
 }
 
+// updateSignatureHint looks up the function call around the cursor for the
+// signature help shown in the status bar.
+func (m *BrowserModel) updateSignatureHint() {
+	m.signature = nil
+	text := m.query.Value()
+	ctx := sqlparse.Analyze(text, lineColToIndex(text, m.query.Line(), m.query.Column()))
+	if ctx.Call == "" {
+		return
+	}
+	if doc, ok := m.dialect.Doc(ctx.Call); ok {
+		m.signature = &doc
+		m.signatureArg = ctx.CallArg
+	}
+}
+
 // applyAutocompleteSuggestion applies the selected autocomplete suggestion
 func (m *BrowserModel) applyAutocompleteSuggestion(suggestion string) {
 	query := m.query.Value()
 	triggerPos := m.autocomplete.TriggerPos
-
-	// Find the start of the current word
-	wordStart := triggerPos
-	for wordStart > 0 {
-		ch := query[wordStart-1]
-		if ch == ' ' || ch == '\t' || ch == '\n' || ch == '\r' ||
-			ch == ',' || ch == ';' || ch == '(' || ch == ')' ||
-			ch == '=' || ch == '<' || ch == '>' || ch == '+' ||
-			ch == '-' || ch == '*' || ch == '/' || ch == '%' {
-			break
-		}
-		wordStart--
+	wordStart := m.autocomplete.ReplaceStart
+	if triggerPos > len(query) {
+		triggerPos = len(query)
+	}
+	if wordStart < 0 || wordStart > triggerPos {
+		wordStart = triggerPos
 	}
 
 	// Replace the current word with the suggestion
 	newQuery := query[:wordStart] + suggestion + query[triggerPos:]
 	m.query.SetValue(newQuery)
+	m.setQueryCursor(indexToLineCol(newQuery, wordStart+len(suggestion)))
+	m.updateSignatureHint()
 
 	// Hide autocomplete
 	m.autocomplete.Visible = false
@@ -2681,6 +2719,7 @@ func (m *BrowserModel) Cleanup() {
 		return
 	}
 	m.cleanedUp = true
+	m.saveBuffers(true)
 	if m.cancel != nil {
 		m.cancel()
 	}
@@ -2691,26 +2730,37 @@ func (m *BrowserModel) executeQuery() tea.Cmd {
 	if query == "" {
 		return nil
 	}
+	m.saveBuffers(false)
 
-	return func() tea.Msg {
-		// Save query to history (async)
-		if cfg := config.Get(); cfg != nil {
-			go cfg.AddQuery(query)
-		}
+	// Save query to history (async)
+	if cfg := config.Get(); cfg != nil {
+		go cfg.AddQuery(query)
+	}
+	return m.executeSQL(query)
+}
+
+// isReadQuery reports whether query starts with SELECT, WITH or EXPLAIN,
+// which are run with Query rather than Exec. It only routes the query;
+// sqlparse.ReadOnly decides whether a script writes.
+func isReadQuery(query string) bool {
+	upperQuery := strings.ToUpper(query)
+	return strings.HasPrefix(upperQuery, "SELECT") ||
+		strings.HasPrefix(upperQuery, "WITH") ||
+		strings.HasPrefix(upperQuery, "EXPLAIN")
+}
 
+// executeSQL runs query in the background and reports a QueryExecutedMsg.
+func (m *BrowserModel) executeSQL(query string) tea.Cmd {
+	readOnly := m.readOnly
+
+	return func() tea.Msg {
 		startTime := time.Now()
 
 		// Try to determine if it's a query or exec
-		upperQuery := strings.ToUpper(query)
+		isQuery := isReadQuery(query)
 
-		// Check if it starts with SELECT, WITH, or EXPLAIN
-		isQuery := false
-		if len(upperQuery) >= 6 && upperQuery[:6] == "SELECT" {
-			isQuery = true
-		} else if len(upperQuery) >= 4 && upperQuery[:4] == "WITH" {
-			isQuery = true
-		} else if len(upperQuery) >= 7 && upperQuery[:7] == "EXPLAIN" {
-			isQuery = true
+		if readOnly && !sqlparse.ReadOnly(query) {
+			return QueryExecutedMsg{Err: fmt.Errorf("read-only mode: only SELECT, WITH and EXPLAIN are allowed (:set noreadonly)"), Query: query}
 		}
 
 		if isQuery {
@@ -2718,11 +2768,13 @@ func (m *BrowserModel) executeQuery() tea.Cmd {
 			if result != nil {
 				result.ExecutionTime = time.Since(startTime)
 			}
-			return QueryExecutedMsg{Result: result, Err: err}
+			return QueryExecutedMsg{Result: result, Err: err, Query: query}
 		} else {
+			// Earlier statements of a failing script may still have changed the schema
+			schema := sqlparse.SchemaChanges(query)
 			_, err := m.db.Exec(query)
 			if err != nil {
-				return QueryExecutedMsg{Err: err}
+				return QueryExecutedMsg{Err: err, Query: query, Schema: schema}
 			}
 			// For exec statements, return empty result
 			return QueryExecutedMsg{
@@ -2732,12 +2784,17 @@ func (m *BrowserModel) executeQuery() tea.Cmd {
 					ExecutionTime: time.Since(startTime),
 					Query:         query,
 				},
+				Query:  query,
+				Schema: schema,
 			}
 		}
 	}
 }
 
 func (m *BrowserModel) updateResultsTable() {
+	m.selection = nil
+	m.refitChart()
+	m.refreshDiff()
 	if m.currentResults == nil {
 		m.results.SetColumns([]table.Column{})
 		m.results.SetRows([]table.Row{})
@@ -2758,19 +2815,7 @@ func (m *BrowserModel) updateResultsTable() {
 	for i, row := range m.currentResults.Rows {
 		rowData := make([]string, len(row))
 		for j, val := range row {
-			if val == nil {
-				rowData[j] = "NULL"
-			} else {
-				// Convert to string
-				switch v := val.(type) {
-				case string:
-					rowData[j] = v
-				case []byte:
-					rowData[j] = string(v)
-				default:
-					rowData[j] = fmt.Sprintf("%v", val)
-				}
-			}
+			rowData[j] = cellText(val, columnType(m.currentResults, j))
 		}
 		rows[i] = rowData
 	}
@@ -2786,9 +2831,18 @@ func (m *BrowserModel) renderResults() string {
 	// Create a filler style that fills the available space
 	fillerStyle := lipgloss.NewStyle().Background(bg)
 
+	_, _, _, _, rw, _ := m.paneDimensions()
+	tabBar := m.renderResultTabs(rw - 2)
+	withTabs := func(content string) string {
+		if tabBar == "" {
+			return content
+		}
+		return lipgloss.JoinVertical(lipgloss.Left, tabBar, content)
+	}
+
 	if m.queryError != "" {
 		errContent := fillerStyle.Render(lipgloss.NewStyle().Foreground(styles.Error).Render("Error: " + m.queryError))
-		return errContent
+		return withTabs(errContent)
 	}
 
 	active := m.activeResultSet()
@@ -2808,6 +2862,9 @@ func (m *BrowserModel) renderResults() string {
 	if m.resultsFilter != "" {
 		infoText = fmt.Sprintf("Showing %d/%d rows in %s (filter: %s)", len(active.Rows), m.currentResults.RowCount, timeStr, m.resultsFilter)
 	}
+	if m.tableQuery != nil && m.tableQuery.FilterText != "" {
+		infoText += fmt.Sprintf(" | where: %s", m.tableQuery.FilterText)
+	}
 	// Make info line fill width with proper background
 	info := lipgloss.NewStyle().
 		Background(bg).
@@ -2815,8 +2872,17 @@ func (m *BrowserModel) renderResults() string {
 		Width(m.width - 4).
 		Render(infoText)
 
-	// Render table with column highlighting
-	tableView := m.renderTableWithColumnHighlight(bg)
+	// Render table with column highlighting, or the selected row as a record
+	var tableView string
+	if m.recordView {
+		tableView = m.renderRecord(bg, maxInt(rw-2, 20), m.results.Height()+1)
+	} else if m.chartView != nil {
+		tableView = m.renderChart(bg, maxInt(rw-2, 20), m.results.Height()+1)
+	} else if m.diffView != nil {
+		tableView = m.renderDiff(bg, maxInt(rw-2, 20), m.results.Height()+1)
+	} else {
+		tableView = m.renderTableWithColumnHighlight(bg)
+	}
 
 	content := lipgloss.JoinVertical(
 		lipgloss.Left,
@@ -2825,7 +2891,7 @@ func (m *BrowserModel) renderResults() string {
 	)
 
 	// Ensure the entire content area has background color
-	return lipgloss.NewStyle().Background(bg).Render(content)
+	return withTabs(lipgloss.NewStyle().Background(bg).Render(content))
 }
 
 // renderTableWithColumnHighlight renders the table with the current column highlighted
@@ -2871,19 +2937,61 @@ func (m *BrowserModel) renderTableWithColumnHighlight(bg color.Color) string {
 		Background(lipgloss.Color("238")).
 		Padding(0, 1)
 
-	// Calculate column widths
+	// Cells inside a visual block
+	blockCellStyle := lipgloss.NewStyle().
+		Foreground(styles.TextBold).
+		Background(styles.PrimaryBg).
+		Padding(0, 1)
+	block := m.selectedBlock()
+
+	// Cells that changed since a watched query's previous run
+	changedCellStyle := cellStyle.Foreground(styles.Warning).Bold(true)
+	addedCellStyle := cellStyle.Foreground(styles.Success)
+
+	// Values styled by type, and the marker on cells cut short
+	nullCellStyle := cellStyle.Foreground(styles.TextMuted).Italic(true)
+	trueCellStyle := cellStyle.Foreground(styles.Success)
+	falseCellStyle := cellStyle.Foreground(styles.Error)
+	moreStyle := lipgloss.NewStyle().Background(bg).Foreground(styles.TextMuted)
+	format := m.cellFormat()
+
+	// Numeric columns that changed get room for the delta beside the value
+	diff := m.activeDiff()
+	deltaWidths := make([]int, len(active.Columns))
+	for _, row := range active.Rows {
+		if change := diff.row(row); change != nil {
+			for c, d := range change.Deltas {
+				if c < len(deltaWidths) {
+					deltaWidths[c] = max(deltaWidths[c], lipgloss.Width(formatDelta(d))+1)
+				}
+			}
+		}
+	}
+
+	// Calculate column widths: set by hand, or fitted to a sample of the
+	// values, plus room for the header's markers
+	layout := m.columnLayout()
+	autoWidths := m.autoColumnWidths(m.currentResults, format)
 	colWidths := make([]int, len(active.Columns))
 	for i, col := range active.Columns {
-		colWidths[i] = lipgloss.Width(col) + 2 // +2 for padding
-		if colWidths[i] < 12 {
-			colWidths[i] = 12 // Minimum width
+		if w, ok := layout.Widths[col]; ok {
+			colWidths[i] = w + 2 // +2 for padding
+			continue
+		}
+		colWidths[i] = autoWidths[i] + 2
+		header := lipgloss.Width(col)
+		if deltaWidths[i] > 0 {
+			header += 2 // " Δ"
+		}
+		if m.tableQuery != nil {
+			if ind := m.tableQuery.sortIndicator(col); ind != "" {
+				header += lipgloss.Width(ind) + 1
+			}
 		}
+		colWidths[i] = max(colWidths[i], header+2)
 	}
-
-	// Calculate total table width
-	totalWidth := 0
-	for _, w := range colWidths {
-		totalWidth += w
+	for i := range colWidths {
+		colWidths[i] += deltaWidths[i]
 	}
 
 	// Get available width from pane dimensions
@@ -2894,36 +3002,71 @@ func (m *BrowserModel) renderTableWithColumnHighlight(bg color.Color) string {
 		availableWidth = 20
 	}
 
+	// Columns in display order. Frozen ones stay at the left, set off by a
+	// separator, and the rest scroll.
+	order := m.visibleColumns()
+	cursorCol = m.resultsCursorCol
+	cursorPos := slices.Index(order, cursorCol)
+	frozen := min(layout.Frozen, len(order))
+	frozenWidth := 0
+	for _, i := range order[:frozen] {
+		frozenWidth += colWidths[i]
+	}
+	if frozen > 0 {
+		frozenWidth++ // Separator
+	}
+	scrollWidth := max(availableWidth-frozenWidth, 1)
+
 	// Auto-scroll horizontally to keep cursor in view
-	if cursorCol >= m.resultsScrollCol {
-		// Check if cursor is beyond visible area
-		visibleWidth := 0
-		for i := m.resultsScrollCol; i <= cursorCol && i < len(colWidths); i++ {
-			visibleWidth += colWidths[i]
+	scroll := max(m.resultsScrollCol, frozen)
+	if cursorPos >= frozen {
+		if cursorPos < scroll {
+			scroll = cursorPos
+		}
+		width := 0
+		for _, i := range order[scroll : cursorPos+1] {
+			width += colWidths[i]
 		}
-		// If cursor column exceeds available width, scroll right
-		for visibleWidth > availableWidth && m.resultsScrollCol < cursorCol {
-			visibleWidth -= colWidths[m.resultsScrollCol]
-			m.resultsScrollCol++
+		for width > scrollWidth && scroll < cursorPos {
+			width -= colWidths[order[scroll]]
+			scroll++
 		}
 	}
+	m.resultsScrollCol = scroll
 
 	// Find end column for visible area
-	endCol := m.resultsScrollCol
+	visible := slices.Clone(order[:frozen])
 	visibleWidth := 0
-	for i := m.resultsScrollCol; i < len(colWidths); i++ {
-		if visibleWidth+colWidths[i] > availableWidth && i > m.resultsScrollCol {
+	for pos := scroll; pos < len(order); pos++ {
+		if visibleWidth+colWidths[order[pos]] > scrollWidth && pos > scroll {
 			break
 		}
-		visibleWidth += colWidths[i]
-		endCol = i + 1
+		visibleWidth += colWidths[order[pos]]
+		visible = append(visible, order[pos])
 	}
+	separator := lipgloss.NewStyle().Background(bg).Foreground(styles.TextMuted).Render("│")
 
 	// Build header - only show visible columns
 	var headerParts []string
-	for i := m.resultsScrollCol; i < endCol && i < len(active.Columns); i++ {
-		colName := truncateString(active.Columns[i], colWidths[i]-2)
-		headerParts = append(headerParts, headerStyle.Width(colWidths[i]).Render(colName))
+	for n, i := range visible {
+		if n == frozen && frozen > 0 {
+			headerParts = append(headerParts, separator)
+		}
+		colName := active.Columns[i]
+		if m.tableQuery != nil {
+			if ind := m.tableQuery.sortIndicator(colName); ind != "" {
+				colName += " " + ind
+			}
+		}
+		if deltaWidths[i] > 0 {
+			colName += " Δ"
+		}
+		colName = truncateString(colName, colWidths[i]-2)
+		style := headerStyle.Width(colWidths[i])
+		if isNumericType(columnType(active, i)) {
+			style = style.Align(lipgloss.Right) // Over the right-aligned numbers
+		}
+		headerParts = append(headerParts, style.Render(colName))
 	}
 	header := lipgloss.NewStyle().Background(bg).Render(strings.Join(headerParts, ""))
 
@@ -2951,33 +3094,71 @@ func (m *BrowserModel) renderTableWithColumnHighlight(bg color.Color) string {
 
 	for rowIdx := startIdx; rowIdx < endIdx && rowIdx < len(active.Rows); rowIdx++ {
 		row := active.Rows[rowIdx]
+		change := diff.row(row)
 		var cellParts []string
 
 		// Only render visible columns
-		for colIdx := m.resultsScrollCol; colIdx < endCol && colIdx < len(active.Columns); colIdx++ {
+		for n, colIdx := range visible {
+			if n == frozen && frozen > 0 {
+				cellParts = append(cellParts, separator)
+			}
 			if colIdx >= len(row) {
 				continue
 			}
-			val := row[colIdx]
-			if val == nil {
-				val = "NULL"
+			text, kind := formatCell(row[colIdx], columnType(active, colIdx), format)
+			valueWidth := colWidths[colIdx] - 2 - deltaWidths[colIdx]
+			cellStr, more := fitCell(text, valueWidth)
+			if kind == cellNumber {
+				cellStr = strings.Repeat(" ", max(valueWidth-lipgloss.Width(cellStr), 0)) + cellStr
 			}
-			cellStr := fmt.Sprintf("%v", val)
-			cellStr = truncateString(cellStr, colWidths[colIdx]-2)
 			cellStr = highlightFilterMatch(cellStr, m.resultsFilter)
-
-			// Apply appropriate style
-			if rowIdx == cursorRow {
-				if colIdx == cursorCol {
-					// Selected cell (intersection of row and column)
-					cellParts = append(cellParts, selectedCellStyle.Width(colWidths[colIdx]).Render(cellStr))
+			// Cells drawn on a highlight take no styled pieces of their own
+			plain := rowIdx == cursorRow || block.contains(rowIdx, colIdx)
+			if more {
+				if plain {
+					cellStr += "…"
+				} else {
+					cellStr += moreStyle.Render("…")
+				}
+			}
+			if d, ok := change.delta(colIdx); ok {
+				cellStr += strings.Repeat(" ", max(valueWidth-lipgloss.Width(cellStr), 0))
+				deltaStyle := lipgloss.NewStyle().Background(bg).Foreground(styles.Success)
+				if d < 0 {
+					deltaStyle = deltaStyle.Foreground(styles.Error)
+				}
+				if plain {
+					cellStr += " " + formatDelta(d)
 				} else {
-					// Other cells in selected row
-					cellParts = append(cellParts, selectedRowStyle.Width(colWidths[colIdx]).Render(cellStr))
+					cellStr += deltaStyle.Render(" " + formatDelta(d))
 				}
+			}
+
+			// Apply appropriate style
+			if rowIdx == cursorRow && colIdx == cursorCol {
+				// Selected cell (intersection of row and column)
+				cellParts = append(cellParts, selectedCellStyle.Width(colWidths[colIdx]).Render(cellStr))
+			} else if block.contains(rowIdx, colIdx) {
+				cellParts = append(cellParts, blockCellStyle.Width(colWidths[colIdx]).Render(cellStr))
+			} else if rowIdx == cursorRow {
+				// Other cells in selected row
+				cellParts = append(cellParts, selectedRowStyle.Width(colWidths[colIdx]).Render(cellStr))
+			} else if change != nil && change.Added {
+				cellParts = append(cellParts, addedCellStyle.Width(colWidths[colIdx]).Render(cellStr))
+			} else if change != nil && colIdx < len(change.Changed) && change.Changed[colIdx] {
+				cellParts = append(cellParts, changedCellStyle.Width(colWidths[colIdx]).Render(cellStr))
 			} else {
 				// Normal cell
-				cellParts = append(cellParts, cellStyle.Width(colWidths[colIdx]).Render(cellStr))
+				style := cellStyle
+				switch {
+				case kind == cellNull:
+					style = nullCellStyle
+				case kind == cellBool && text == formatBool(true):
+					style = trueCellStyle
+				case kind == cellBool:
+					style = falseCellStyle
+				}
+				cellParts = append(cellParts, style.Width(colWidths[colIdx]).Render(cellStr))
 			}
 		}
 
@@ -2990,12 +3171,6 @@ func (m *BrowserModel) renderTableWithColumnHighlight(bg color.Color) string {
 	tableParts = append(tableParts, header)
 	tableParts = append(tableParts, rows...)
 
-	// Calculate total visible width
-	visibleWidth = 0
-	for i := m.resultsScrollCol; i < endCol && i < len(colWidths); i++ {
-		visibleWidth += colWidths[i]
-	}
-
 	// Ensure each row fills the full available width
 	rowStyle := lipgloss.NewStyle().Background(bg)
 	for i := 0; i < len(tableParts); i++ {
@@ -0,0 +1,390 @@
+package screens
+
+import (
+	"fmt"
+	"os"
+	"strings"
+	"sync"
+	"sync/atomic"
+
+	tea "charm.land/bubbletea/v2"
+
+	"github.com/jupiterozeye/tornado/internal/config"
+)
+
+// maxUndoHistory is how many undo steps each buffer keeps.
+const maxUndoHistory = 200
+
+// editSnapshot is the editor text and cursor at one point in a buffer's history.
+type editSnapshot struct {
+	text string
+	line int
+	col  int
+}
+
+// queryBuffer is one named query editor buffer. The active buffer's text and
+// cursor live in the textarea and result tabs in the BrowserModel; they are
+// copied here when another buffer becomes active.
+type queryBuffer struct {
+	Name string
+	Path string // SQL file backing the buffer, empty for scratch buffers
+
+	text string
+	line int
+	col  int
+
+	savedText string // Text as last read from or written to Path
+
+	undo []editSnapshot
+	redo []editSnapshot
+
+	resultTabs []*resultTab
+	activeTab  int
+}
+
+func newQueryBuffer(name string) *queryBuffer {
+	return &queryBuffer{Name: name, activeTab: -1}
+}
+
+// currentBuffer returns the active buffer.
+func (m *BrowserModel) currentBuffer() *queryBuffer {
+	return m.buffers[m.activeBuffer]
+}
+
+// snapshotEdit captures the editor state for the undo history.
+func (m *BrowserModel) snapshotEdit() editSnapshot {
+	return editSnapshot{text: m.query.Value(), line: m.query.Line(), col: m.query.Column()}
+}
+
+// restoreEdit puts a snapshot back into the editor.
+func (m *BrowserModel) restoreEdit(s editSnapshot) {
+	m.query.SetValue(s.text)
+	m.setQueryCursor(s.line, s.col)
+}
+
+// trackEdit records before as an undo step if the editor text has changed
+// since it was taken. Everything typed in one INSERT session is a single step,
+// as in vim.
+func (m *BrowserModel) trackEdit(before editSnapshot) {
+	if m.editTracked {
+		m.editTracked = false
+	} else if m.query.Value() != before.text {
+		buf := m.currentBuffer()
+		if !(m.queryMode == QueryModeInsert && m.insertUndoOpen) {
+			buf.undo = append(buf.undo, before)
+			if len(buf.undo) > maxUndoHistory {
+				buf.undo = buf.undo[len(buf.undo)-maxUndoHistory:]
+			}
+		}
+		buf.redo = nil
+		if m.queryMode == QueryModeInsert {
+			m.insertUndoOpen = true
+		}
+	}
+	if m.queryMode != QueryModeInsert {
+		m.insertUndoOpen = false
+	}
+}
+
+// undoEdit reverts the active buffer to its previous undo step.
+func (m *BrowserModel) undoEdit() {
+	buf := m.currentBuffer()
+	if len(buf.undo) == 0 {
+		m.statusMsg = "Already at oldest change"
+		return
+	}
+	prev := buf.undo[len(buf.undo)-1]
+	buf.undo = buf.undo[:len(buf.undo)-1]
+	buf.redo = append(buf.redo, m.snapshotEdit())
+	m.restoreEdit(prev)
+	m.editTracked = true
+	m.statusMsg = fmt.Sprintf("Undo (%d left)", len(buf.undo))
+}
+
+// redoEdit reapplies the most recently undone change.
+func (m *BrowserModel) redoEdit() {
+	buf := m.currentBuffer()
+	if len(buf.redo) == 0 {
+		m.statusMsg = "Already at newest change"
+		return
+	}
+	next := buf.redo[len(buf.redo)-1]
+	buf.redo = buf.redo[:len(buf.redo)-1]
+	buf.undo = append(buf.undo, m.snapshotEdit())
+	m.restoreEdit(next)
+	m.editTracked = true
+	m.statusMsg = fmt.Sprintf("Redo (%d left)", len(buf.redo))
+}
+
+// setQueryText replaces the editor text as a single undoable change.
+func (m *BrowserModel) setQueryText(text string) {
+	before := m.snapshotEdit()
+	m.query.SetValue(text)
+	m.trackEdit(before)
+}
+
+// stashBuffer copies the editor and results state into the active buffer.
+func (m *BrowserModel) stashBuffer() {
+	buf := m.currentBuffer()
+	buf.text = m.query.Value()
+	buf.line = m.query.Line()
+	buf.col = m.query.Column()
+	m.saveActiveTab()
+	buf.resultTabs = m.resultTabs
+	buf.activeTab = m.activeTab
+}
+
+// loadBuffer makes buffer i active and restores its editor and results state.
+func (m *BrowserModel) loadBuffer(i int) {
+	m.activeBuffer = i
+	buf := m.buffers[i]
+	m.query.SetValue(buf.text)
+	m.setQueryCursor(buf.line, buf.col)
+	m.vim.done()
+	m.insertUndoOpen = false
+	m.editTracked = true
+	m.autocomplete.Visible = false
+
+	m.resultTabs = buf.resultTabs
+	m.activeTab = buf.activeTab
+	if m.activeTab >= 0 && m.activeTab < len(m.resultTabs) {
+		m.loadTab(m.activeTab)
+	} else {
+		m.activeTab = -1
+		m.clearResults()
+	}
+}
+
+// switchBuffer moves to the buffer delta positions away, wrapping around.
+func (m *BrowserModel) switchBuffer(delta int) {
+	if len(m.buffers) < 2 {
+		m.statusMsg = "Only one buffer"
+		return
+	}
+	m.stashBuffer()
+	n := len(m.buffers)
+	m.loadBuffer(((m.activeBuffer+delta)%n + n) % n)
+	m.statusMsg = fmt.Sprintf("Buffer %d/%d: %s", m.activeBuffer+1, n, m.currentBuffer().Name)
+	m.saveBuffers(false)
+}
+
+// newBuffer opens an empty buffer after the active one.
+func (m *BrowserModel) newBuffer() {
+	m.stashBuffer()
+	buf := newQueryBuffer(m.nextBufferName())
+	i := m.activeBuffer + 1
+	m.buffers = append(m.buffers[:i], append([]*queryBuffer{buf}, m.buffers[i:]...)...)
+	m.loadBuffer(i)
+	m.statusMsg = "New buffer: " + buf.Name
+	m.saveBuffers(false)
+}
+
+// closeBuffer closes the active buffer. The last buffer is emptied instead.
+func (m *BrowserModel) closeBuffer() {
+	name := m.currentBuffer().Name
+	if len(m.buffers) == 1 {
+		m.buffers[0] = newQueryBuffer(name)
+		m.loadBuffer(0)
+		m.statusMsg = "Cleared buffer: " + name
+		m.saveBuffers(false)
+		return
+	}
+	m.buffers = append(m.buffers[:m.activeBuffer], m.buffers[m.activeBuffer+1:]...)
+	i := m.activeBuffer
+	if i >= len(m.buffers) {
+		i = len(m.buffers) - 1
+	}
+	m.loadBuffer(i)
+	m.statusMsg = "Closed buffer: " + name
+	m.saveBuffers(false)
+}
+
+// renameBuffer renames the active buffer.
+func (m *BrowserModel) renameBuffer(name string) {
+	name = strings.TrimSpace(name)
+	if name == "" {
+		m.statusMsg = "Buffer name cannot be empty"
+		return
+	}
+	m.currentBuffer().Name = name
+	m.statusMsg = "Renamed buffer to " + name
+	m.saveBuffers(false)
+}
+
+// nextBufferName returns the first unused "scratchN" name.
+func (m *BrowserModel) nextBufferName() string {
+	for n := 1; ; n++ {
+		name := fmt.Sprintf("scratch%d", n)
+		taken := false
+		for _, b := range m.buffers {
+			if b.Name == name {
+				taken = true
+				break
+			}
+		}
+		if !taken {
+			return name
+		}
+	}
+}
+
+// handleBufferRenameKey handles key presses while the rename prompt is open.
+func (m *BrowserModel) handleBufferRenameKey(msg tea.KeyPressMsg) (tea.Model, tea.Cmd) {
+	switch msg.String() {
+	case "esc":
+		m.bufferRenameActive = false
+		m.statusMsg = ""
+	case "enter":
+		m.bufferRenameActive = false
+		m.renameBuffer(m.bufferRenameInput)
+	case "backspace":
+		if len(m.bufferRenameInput) > 0 {
+			runes := []rune(m.bufferRenameInput)
+			m.bufferRenameInput = string(runes[:len(runes)-1])
+		}
+	default:
+		if msg.Text != "" {
+			m.bufferRenameInput += msg.Text
+		}
+	}
+	return m, nil
+}
+
+// restoreBuffers loads the saved buffers for the current connection.
+func (m *BrowserModel) restoreBuffers() {
+	cfg := config.Get()
+	if cfg == nil || m.connKey == "" {
+		return
+	}
+	set, ok := cfg.GetQueryBuffers(m.connKey)
+	if !ok || len(set.Buffers) == 0 {
+		return
+	}
+	m.buffers = m.buffers[:0]
+	for _, e := range set.Buffers {
+		buf := newQueryBuffer(e.Name)
+		buf.Path = e.Path
+		if e.Path != "" {
+			// Compare against the file on disk so the modified flag is accurate
+			if data, err := os.ReadFile(e.Path); err == nil {
+				buf.savedText = string(data)
+			}
+		}
+		buf.text = e.Content
+		buf.line = e.Line
+		buf.col = e.Column
+		m.buffers = append(m.buffers, buf)
+	}
+	active := set.Active
+	if active < 0 || active >= len(m.buffers) {
+		active = 0
+	}
+	m.loadBuffer(active)
+	m.editTracked = false
+	m.statusMsg = fmt.Sprintf("Restored %d buffer(s)", len(m.buffers))
+}
+
+// bufferSaver writes buffer snapshots to the config file. Saves run in the
+// background and may finish in any order, so snapshots are numbered and one
+// is dropped when a newer one has already been written.
+type bufferSaver struct {
+	seq     atomic.Uint64 // Number of the latest snapshot taken
+	mu      sync.Mutex    // Held while writing
+	written uint64        // Number of the latest snapshot written
+	errs    chan error    // Background failures, for the UI
+}
+
+func newBufferSaver() *bufferSaver {
+	return &bufferSaver{errs: make(chan error, 1)}
+}
+
+// save writes snapshot n unless a newer one was written first.
+func (s *bufferSaver) save(cfg *config.Config, key string, set config.BufferSet, n uint64) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if n <= s.written {
+		return nil
+	}
+	s.written = n
+	return cfg.SetQueryBuffers(key, set)
+}
+
+// BufferSaveErrorMsg reports that buffers could not be saved in the background.
+type BufferSaveErrorMsg struct {
+	Err error
+}
+
+// waitBufferSaveError waits for a background buffer save to fail.
+func (m *BrowserModel) waitBufferSaveError() tea.Cmd {
+	errs := m.bufferSaves.errs
+	return func() tea.Msg {
+		return BufferSaveErrorMsg{Err: <-errs}
+	}
+}
+
+// saveBuffers persists the buffers for the current connection. Saving runs in
+// the background unless sync is set, which is used when the app is exiting.
+func (m *BrowserModel) saveBuffers(sync bool) {
+	cfg := config.Get()
+	if cfg == nil || m.connKey == "" {
+		return
+	}
+	buf := m.currentBuffer()
+	buf.text = m.query.Value()
+	buf.line = m.query.Line()
+	buf.col = m.query.Column()
+
+	set := config.BufferSet{Active: m.activeBuffer}
+	for _, b := range m.buffers {
+		set.Buffers = append(set.Buffers, config.BufferEntry{
+			Name:    b.Name,
+			Path:    b.Path,
+			Content: b.text,
+			Line:    b.line,
+			Column:  b.col,
+		})
+	}
+	saver, key := m.bufferSaves, m.connKey
+	n := saver.seq.Add(1)
+	if sync {
+		if err := saver.save(cfg, key, set, n); err != nil {
+			m.statusMsg = "Saving buffers failed: " + err.Error()
+		}
+		return
+	}
+	go func() {
+		if err := saver.save(cfg, key, set, n); err != nil {
+			select {
+			case saver.errs <- err:
+			default: // One failure waiting to be shown is enough
+			}
+		}
+	}()
+}
+
+// bufferModified reports whether a file-backed buffer has unsaved changes.
+// Scratch buffers are never modified since they are saved with the config.
+func (m *BrowserModel) bufferModified(i int) bool {
+	buf := m.buffers[i]
+	if buf.Path == "" {
+		return false
+	}
+	text := buf.text
+	if i == m.activeBuffer {
+		text = m.query.Value()
+	}
+	return text != buf.savedText
+}
+
+// bufferTitle returns the query pane title suffix naming the active buffer,
+// with [+] when it has unsaved changes.
+func (m *BrowserModel) bufferTitle() string {
+	title := m.currentBuffer().Name
+	if len(m.buffers) > 1 {
+		title = fmt.Sprintf("%s %d/%d", title, m.activeBuffer+1, len(m.buffers))
+	}
+	if m.bufferModified(m.activeBuffer) {
+		title += " [+]"
+	}
+	return title
+}
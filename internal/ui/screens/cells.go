@@ -0,0 +1,183 @@
+package screens
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"charm.land/lipgloss/v2"
+
+	"github.com/jupiterozeye/tornado/internal/config"
+	"github.com/jupiterozeye/tornado/internal/models"
+)
+
+// cellKind says how a formatted cell is drawn.
+type cellKind int
+
+const (
+	cellPlain  cellKind = iota
+	cellNumber          // Right-aligned
+	cellNull            // Styled apart from the string "NULL"
+	cellBool
+)
+
+// cellFormat holds the display options for result cells.
+type cellFormat struct {
+	loc            *time.Location
+	timeLayout     string
+	floatPrecision int // -1 for the fewest digits that read back the same
+}
+
+// defaultCellFormat is used when there is no config file.
+var defaultCellFormat = cellFormat{loc: time.Local, timeLayout: "2006-01-02 15:04:05", floatPrecision: -1}
+
+// cellFormat returns the display options from the config file. The time
+// zone is resolved when the config loads; an unknown one falls back to local
+// time.
+func (m *BrowserModel) cellFormat() cellFormat {
+	f := defaultCellFormat
+	cfg := config.Get()
+	if cfg == nil {
+		return f
+	}
+	d := cfg.GetDisplay()
+	if d.TimeFormat != "" {
+		f.timeLayout = d.TimeFormat
+	}
+	f.loc = d.Location()
+	f.floatPrecision = max(d.FloatPrecision, -1)
+	return f
+}
+
+// formatCell returns how a value is drawn in the results grid: numbers in a
+// fixed notation, timestamps in the configured zone and layout, and booleans
+// as ✓ and ✗.
+func formatCell(val any, colType string, f cellFormat) (string, cellKind) {
+	switch v := val.(type) {
+	case nil:
+		return "NULL", cellNull
+	case bool:
+		return formatBool(v), cellBool
+	case float64:
+		return strconv.FormatFloat(v, 'f', f.floatPrecision, 64), cellNumber
+	case float32:
+		return strconv.FormatFloat(float64(v), 'f', f.floatPrecision, 32), cellNumber
+	case int, int8, int16, int32, int64, uint, uint8, uint16, uint32, uint64:
+		if isBoolType(colType) {
+			if n := fmt.Sprint(v); n == "0" || n == "1" {
+				return formatBool(n == "1"), cellBool
+			}
+		}
+		return fmt.Sprint(v), cellNumber
+	case time.Time:
+		if strings.EqualFold(colType, "DATE") {
+			return v.Format(time.DateOnly), cellPlain
+		}
+		return v.In(f.loc).Format(f.timeLayout), cellPlain
+	}
+
+	text := cellText(val, colType)
+	if _, ok := blobValue(val, colType); !ok && isNumericType(colType) {
+		if _, err := strconv.ParseFloat(text, 64); err == nil {
+			return text, cellNumber
+		}
+	}
+	return text, cellPlain
+}
+
+func formatBool(b bool) string {
+	if b {
+		return "✓"
+	}
+	return "✗"
+}
+
+// isBoolType reports whether a declared column type holds booleans, which
+// SQLite stores as the integers 0 and 1.
+func isBoolType(colType string) bool {
+	t := strings.ToUpper(colType)
+	return t == "BOOL" || t == "BOOLEAN"
+}
+
+// isNumericType reports whether a declared column type holds numbers that
+// the driver may return as text, such as NUMERIC and DECIMAL.
+func isNumericType(colType string) bool {
+	t := strings.ToUpper(colType)
+	if strings.Contains(t, "POINT") || strings.Contains(t, "INTERVAL") {
+		return false
+	}
+	for _, name := range []string{"INT", "NUMERIC", "DECIMAL", "REAL", "FLOAT", "DOUBLE", "MONEY"} {
+		if strings.Contains(t, name) {
+			return true
+		}
+	}
+	return false
+}
+
+// fitCell cuts a cell to its first line and to width. When the cell has
+// more than is shown it leaves room for the "…" marker the grid adds.
+func fitCell(s string, width int) (string, bool) {
+	line, _, multiline := strings.Cut(s, "\n")
+	line = strings.ReplaceAll(strings.TrimRight(line, "\r"), "\t", " ")
+	if !multiline && lipgloss.Width(line) <= width {
+		return line, false
+	}
+	return truncateString(line, width-1), true
+}
+
+// columnType returns the database type of column i, or "" when the driver
+// did not report one.
+func columnType(r *models.QueryResult, i int) string {
+	if r == nil || i < 0 || i >= len(r.ColumnTypes) {
+		return ""
+	}
+	return r.ColumnTypes[i]
+}
+
+// cellText returns how a value is shown in the results grid and matched by
+// the results filter. Binary values are summarised rather than written to
+// the terminal.
+func cellText(val any, colType string) string {
+	switch v := val.(type) {
+	case nil:
+		return "NULL"
+	case string:
+		return v
+	case []byte:
+		if isBinary(v, colType) {
+			return fmt.Sprintf("<BLOB %d bytes>", len(v))
+		}
+		return string(v)
+	}
+	return fmt.Sprintf("%v", val)
+}
+
+// blobValue returns a cell's bytes when it holds binary data.
+func blobValue(val any, colType string) ([]byte, bool) {
+	b, ok := val.([]byte)
+	if !ok || !isBinary(b, colType) {
+		return nil, false
+	}
+	return b, true
+}
+
+// isBinary reports whether bytes from the driver are binary data rather
+// than text: the column is declared binary, or the bytes are not printable
+// UTF-8.
+func isBinary(b []byte, colType string) bool {
+	t := strings.ToUpper(colType)
+	if strings.Contains(t, "BLOB") || strings.Contains(t, "BINARY") || t == "BYTEA" {
+		return true
+	}
+	if !utf8.Valid(b) {
+		return true
+	}
+	for _, r := range string(b) {
+		if (r < 0x20 && r != '\n' && r != '\r' && r != '\t') || r == 0x7f {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,64 @@
+package screens
+
+import (
+	"testing"
+	"time"
+)
+
+func TestFormatCell(t *testing.T) {
+	berlin, err := time.LoadLocation("Europe/Berlin")
+	if err != nil {
+		t.Skip("no time zone database")
+	}
+	f := cellFormat{loc: berlin, timeLayout: "2006-01-02 15:04 MST", floatPrecision: -1}
+	ts := time.Date(2024, 7, 1, 12, 30, 0, 0, time.UTC)
+
+	tests := []struct {
+		val      any
+		colType  string
+		f        cellFormat
+		want     string
+		wantKind cellKind
+	}{
+		{nil, "TEXT", f, "NULL", cellNull},
+		{"NULL", "TEXT", f, "NULL", cellPlain},
+		{int64(42), "INTEGER", f, "42", cellNumber},
+		{1e6, "REAL", f, "1000000", cellNumber},
+		{0.1, "REAL", cellFormat{floatPrecision: 3}, "0.100", cellNumber},
+		{"12.50", "NUMERIC", f, "12.50", cellNumber},
+		{"n/a", "NUMERIC", f, "n/a", cellPlain},
+		{true, "", f, "✓", cellBool},
+		{int64(0), "BOOLEAN", f, "✗", cellBool},
+		{int64(2), "BOOLEAN", f, "2", cellNumber},
+		{ts, "TIMESTAMP", f, "2024-07-01 14:30 CEST", cellPlain},
+		{ts, "DATE", f, "2024-07-01", cellPlain},
+		{[]byte{0, 1}, "BLOB", f, "<BLOB 2 bytes>", cellPlain},
+	}
+	for _, tt := range tests {
+		got, kind := formatCell(tt.val, tt.colType, tt.f)
+		if got != tt.want || kind != tt.wantKind {
+			t.Errorf("formatCell(%#v, %q) = %q, %d, want %q, %d", tt.val, tt.colType, got, kind, tt.want, tt.wantKind)
+		}
+	}
+}
+
+func TestFitCell(t *testing.T) {
+	tests := []struct {
+		s        string
+		width    int
+		want     string
+		wantMore bool
+	}{
+		{"short", 10, "short", false},
+		{"exactly10!", 10, "exactly10!", false},
+		{"much too long", 10, "much too ", true},
+		{"first\nsecond", 10, "first", true},
+		{"a\tb", 10, "a b", false},
+	}
+	for _, tt := range tests {
+		got, more := fitCell(tt.s, tt.width)
+		if got != tt.want || more != tt.wantMore {
+			t.Errorf("fitCell(%q, %d) = %q, %v, want %q, %v", tt.s, tt.width, got, more, tt.want, tt.wantMore)
+		}
+	}
+}
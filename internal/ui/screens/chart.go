@@ -0,0 +1,284 @@
+package screens
+
+import (
+	"fmt"
+	"image/color"
+	"math"
+	"slices"
+	"strings"
+
+	tea "charm.land/bubbletea/v2"
+	"charm.land/lipgloss/v2"
+
+	"github.com/jupiterozeye/tornado/internal/models"
+	"github.com/jupiterozeye/tornado/internal/ui/components"
+	"github.com/jupiterozeye/tornado/internal/ui/styles"
+)
+
+// chartKinds are the ways the chart view plots results, in the order tab
+// cycles through them.
+var chartKinds = []string{"bar", "line", "spark"}
+
+// chartColors colour the plotted columns in turn.
+var chartColors = []color.Color{styles.Primary, styles.Accent, styles.Success, styles.Warning, styles.Info, styles.Secondary}
+
+// chartViewer is the state of the chart shown in place of the results grid.
+// Columns are kept by name so the chart survives re-running the query.
+type chartViewer struct {
+	kind string
+	x    string   // Column labelling the points
+	ys   []string // Columns plotted, in the order they were picked
+}
+
+// chartColumns returns the result's numeric columns: those typed as numbers
+// and those whose sampled values all read as numbers.
+func chartColumns(r *models.QueryResult) []int {
+	var cols []int
+	for i := range r.Columns {
+		if isNumericType(columnType(r, i)) {
+			cols = append(cols, i)
+			continue
+		}
+		numbers := 0
+		for _, row := range r.Rows[:min(len(r.Rows), columnSampleRows)] {
+			if i >= len(row) || row[i] == nil {
+				continue
+			}
+			if _, ok := chartValue(r, row, i); !ok {
+				numbers = -1
+				break
+			}
+			numbers++
+		}
+		if numbers > 0 {
+			cols = append(cols, i)
+		}
+	}
+	return cols
+}
+
+// chartValue reads a cell as a number.
+func chartValue(r *models.QueryResult, row []any, col int) (float64, bool) {
+	if col >= len(row) || row[col] == nil {
+		return 0, false
+	}
+	if n, ok := numericValue(row[col]); ok {
+		return n, true
+	}
+	return parseNumber(cellText(row[col], columnType(r, col)))
+}
+
+// openChart shows the results as a chart of the given kind, or the last one
+// used. X defaults to the first column that is not a number and Y to every
+// numeric column.
+func (m *BrowserModel) openChart(kind string) {
+	active := m.activeResultSet()
+	if active == nil || len(active.Rows) == 0 {
+		m.statusMsg = "No results to chart"
+		return
+	}
+	numeric := chartColumns(active)
+	if len(numeric) == 0 {
+		m.statusMsg = "No numeric columns to chart"
+		return
+	}
+	if m.chartView == nil {
+		v := &chartViewer{kind: chartKinds[0]}
+		for i, col := range active.Columns {
+			if !slices.Contains(numeric, i) {
+				v.x = col
+				break
+			}
+		}
+		for _, i := range numeric {
+			if active.Columns[i] != v.x {
+				v.ys = append(v.ys, active.Columns[i])
+			}
+		}
+		if len(v.ys) == 0 {
+			v.ys = []string{active.Columns[numeric[0]]}
+		}
+		m.chartView = v
+	}
+	if kind != "" {
+		m.chartView.kind = kind
+	}
+	m.recordView = false
+	m.diffView = nil
+	m.selection = nil
+	m.statusMsg = ""
+}
+
+// chartCommand handles ":chart [bar|line|spark|off]".
+func (m *BrowserModel) chartCommand(arg string) {
+	arg = strings.TrimSpace(arg)
+	switch {
+	case arg == "off":
+		m.chartView = nil
+	case arg == "" || slices.Contains(chartKinds, arg):
+		m.openChart(arg)
+	default:
+		m.statusMsg = "Usage: chart [bar|line|spark|off]"
+	}
+}
+
+// handleChartKey handles keys while the chart is shown: tab changes the kind
+// of chart, x the X column, and 1-9 add or remove the numbered Y columns.
+// Keys it does not use fall through to the results pane.
+func (m *BrowserModel) handleChartKey(msg tea.KeyPressMsg) (bool, tea.Cmd) {
+	v := m.chartView
+	active := m.activeResultSet()
+	switch key := msg.String(); key {
+	case "esc", "q", "c":
+		m.chartView = nil
+	case "tab":
+		v.kind = chartKinds[(slices.Index(chartKinds, v.kind)+1)%len(chartKinds)]
+	case "shift+tab":
+		v.kind = chartKinds[(slices.Index(chartKinds, v.kind)+len(chartKinds)-1)%len(chartKinds)]
+	case "x":
+		if active != nil && len(active.Columns) > 0 {
+			next := (slices.Index(active.Columns, v.x) + 1) % len(active.Columns)
+			v.x = active.Columns[next]
+			m.statusMsg = "X: " + v.x
+		}
+	case "1", "2", "3", "4", "5", "6", "7", "8", "9":
+		if active == nil {
+			return true, nil
+		}
+		numeric := chartColumns(active)
+		n := int(key[0] - '1')
+		if n >= len(numeric) {
+			return true, nil
+		}
+		name := active.Columns[numeric[n]]
+		if i := slices.Index(v.ys, name); i >= 0 {
+			if len(v.ys) == 1 {
+				m.statusMsg = "Chart needs a Y column"
+				return true, nil
+			}
+			v.ys = slices.Delete(v.ys, i, i+1)
+		} else {
+			v.ys = append(v.ys, name)
+		}
+	default:
+		return false, nil
+	}
+	return true, nil
+}
+
+// renderChart draws the chart view: a legend numbering the numeric columns,
+// then the chart.
+func (m *BrowserModel) renderChart(bg color.Color, width, height int) string {
+	active := m.activeResultSet()
+	v := m.chartView
+	if active == nil {
+		return ""
+	}
+	muted := lipgloss.NewStyle().Background(bg).Foreground(styles.TextMuted)
+	text := lipgloss.NewStyle().Background(bg).Foreground(styles.Text)
+
+	xCol := slices.Index(active.Columns, v.x)
+	labels := make([]string, len(active.Rows))
+	for i, row := range active.Rows {
+		if xCol >= 0 && xCol < len(row) {
+			labels[i], _ = formatCell(row[xCol], columnType(active, xCol), m.cellFormat())
+			labels[i], _, _ = strings.Cut(labels[i], "\n")
+		} else {
+			labels[i] = fmt.Sprint(i + 1)
+		}
+	}
+
+	var series []components.Series
+	legend := []string{text.Render(v.kind), muted.Render("  X: "), text.Render(v.x), muted.Render("  Y:")}
+	for n, i := range chartColumns(active) {
+		name := active.Columns[i]
+		pick := slices.Index(v.ys, name)
+		if pick < 0 {
+			legend = append(legend, muted.Render(fmt.Sprintf(" %d %s", n+1, name)))
+			continue
+		}
+		c := chartColors[pick%len(chartColors)]
+		legend = append(legend, muted.Render(fmt.Sprintf(" %d ", n+1)),
+			lipgloss.NewStyle().Background(bg).Foreground(c).Render("■ "+name))
+		values := make([]float64, len(active.Rows))
+		for r, row := range active.Rows {
+			values[r] = math.NaN()
+			if f, ok := chartValue(active, row, i); ok {
+				values[r] = f
+			}
+		}
+		series = append(series, components.Series{Name: name, Values: values, Color: c})
+	}
+	// Plot in the order the columns were picked so colours stay put
+	slices.SortStableFunc(series, func(a, b components.Series) int {
+		return slices.Index(v.ys, a.Name) - slices.Index(v.ys, b.Name)
+	})
+
+	var body string
+	switch v.kind {
+	case "line":
+		body = components.LineChart(series, labels, width, height-1, styles.TextMuted, bg)
+	case "spark":
+		nameW := 0
+		for _, s := range series {
+			nameW = max(nameW, lipgloss.Width(s.Name))
+		}
+		nameW = min(nameW, max(width/4, 1))
+		lines := make([]string, 0, len(series))
+		for _, s := range series {
+			lo, hi := math.Inf(1), math.Inf(-1)
+			for _, n := range s.Values {
+				if finite(n) {
+					lo, hi = min(lo, n), max(hi, n)
+				}
+			}
+			bounds := ""
+			if !math.IsInf(lo, 0) {
+				bounds = fmt.Sprintf(" %s..%s", components.FormatValue(lo), components.FormatValue(hi))
+			}
+			sparkW := max(width-nameW-1-len(bounds), 1)
+			lines = append(lines, lipgloss.NewStyle().Background(bg).Foreground(s.Color).Render(padRight(truncateString(s.Name, nameW), nameW)+" ")+
+				components.Sparkline(s.Values, sparkW, s.Color, bg)+muted.Render(bounds))
+		}
+		body = strings.Join(lines[:min(len(lines), height-1)], "\n")
+	default:
+		// One line per column per row: show the rows that fit
+		rows := max((height-1)/max(len(series), 1), 1)
+		if rows < len(labels) {
+			legend = append(legend, muted.Render(fmt.Sprintf("  (first %d of %d rows)", rows, len(labels))))
+			labels = labels[:rows]
+			for i := range series {
+				series[i].Values = series[i].Values[:rows]
+			}
+		}
+		body = components.BarChart(labels, series, width, styles.Text, bg)
+	}
+
+	lines := []string{truncateToWidth(strings.Join(legend, ""), width)}
+	if body != "" {
+		lines = append(lines, strings.Split(body, "\n")...)
+	}
+	for i, line := range lines {
+		lines[i] = line + muted.Render(strings.Repeat(" ", max(width-lipgloss.Width(line), 0)))
+	}
+	return strings.Join(lines, "\n")
+}
+
+// refitChart closes the chart when a new result no longer has its columns.
+func (m *BrowserModel) refitChart() {
+	v := m.chartView
+	active := m.activeResultSet()
+	if v == nil {
+		return
+	}
+	if active == nil || !slices.Contains(active.Columns, v.x) {
+		m.chartView = nil
+		return
+	}
+	for _, y := range v.ys {
+		if !slices.Contains(active.Columns, y) {
+			m.chartView = nil
+			return
+		}
+	}
+}
@@ -0,0 +1,82 @@
+package screens
+
+import (
+	"math"
+	"reflect"
+	"strings"
+	"testing"
+
+	xansi "github.com/charmbracelet/x/ansi"
+
+	"github.com/jupiterozeye/tornado/internal/models"
+	"github.com/jupiterozeye/tornado/internal/ui/components"
+)
+
+func TestChartColumns(t *testing.T) {
+	r := &models.QueryResult{
+		Columns:     []string{"day", "total", "avg", "note"},
+		ColumnTypes: []string{"TEXT", "INTEGER", "", ""},
+		Rows: [][]any{
+			{"mon", int64(3), "1.5", "x"},
+			{"tue", nil, nil, "2"},
+		},
+	}
+	if got, want := chartColumns(r), []int{1, 2}; !reflect.DeepEqual(got, want) {
+		t.Errorf("chartColumns = %v, want %v", got, want)
+	}
+}
+
+func TestSparkline(t *testing.T) {
+	got := xansi.Strip(components.Sparkline([]float64{0, 7, math.NaN(), 14}, 10, nil, nil))
+	if got != "▁▄ █" {
+		t.Errorf("Sparkline = %q", got)
+	}
+	// More values than cells are averaged
+	got = xansi.Strip(components.Sparkline([]float64{0, 0, 10, 10}, 2, nil, nil))
+	if got != "▁█" {
+		t.Errorf("resampled Sparkline = %q", got)
+	}
+}
+
+func TestBarChart(t *testing.T) {
+	got := xansi.Strip(components.BarChart([]string{"a", "b"}, []components.Series{{Values: []float64{-2, 4}}}, 14, nil, nil))
+	want := "a ███ -2\nb    █████ 4"
+	if got != want {
+		t.Errorf("BarChart =\n%s\nwant\n%s", got, want)
+	}
+}
+
+func TestCharts_infinity(t *testing.T) {
+	values := []float64{math.Inf(-1), 0, 7, 14, math.Inf(1)}
+	if got := xansi.Strip(components.Sparkline(values, 10, nil, nil)); got != " ▁▄█ " {
+		t.Errorf("Sparkline = %q", got)
+	}
+	labels := []string{"a", "b", "c", "d", "e"}
+	got := xansi.Strip(components.BarChart(labels, []components.Series{{Values: []float64{math.Inf(1), -2, 4, 0, math.Inf(-1)}}}, 14, nil, nil))
+	want := "a \nb ███ -2\nc    █████ 4\nd     0\ne "
+	if got != want {
+		t.Errorf("BarChart =\n%s\nwant\n%s", got, want)
+	}
+	if got := components.LineChart([]components.Series{{Values: []float64{math.Inf(1), math.Inf(-1)}}}, nil, 20, 4, nil, nil); got != "" {
+		t.Errorf("LineChart of only infinities = %q", got)
+	}
+	if got := components.LineChart([]components.Series{{Values: values}}, labels, 20, 4, nil, nil); got == "" {
+		t.Error("LineChart dropped the finite values")
+	}
+}
+
+func TestCharts_hugeRange(t *testing.T) {
+	values := []float64{-1e308, 0, 1e308}
+	if got := xansi.Strip(components.Sparkline(values, 10, nil, nil)); got != "▁▄█" {
+		t.Errorf("Sparkline = %q", got)
+	}
+	got := xansi.Strip(components.BarChart([]string{"a", "b", "c"}, []components.Series{{Values: values}}, 20, nil, nil))
+	want := "a █████ -1e+308\nb       0\nc      ████ 1e+308"
+	if got != want {
+		t.Errorf("BarChart =\n%s\nwant\n%s", got, want)
+	}
+	lines := strings.Split(xansi.Strip(components.LineChart([]components.Series{{Values: values}}, nil, 20, 4, nil, nil)), "\n")
+	if len(lines) != 4 || !strings.HasPrefix(lines[0], " 1e+308┤") {
+		t.Errorf("LineChart = %q", lines)
+	}
+}
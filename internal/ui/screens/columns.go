@@ -0,0 +1,308 @@
+package screens
+
+import (
+	"fmt"
+	"slices"
+	"strconv"
+	"strings"
+
+	"charm.land/lipgloss/v2"
+
+	"github.com/jupiterozeye/tornado/internal/config"
+	"github.com/jupiterozeye/tornado/internal/models"
+)
+
+const (
+	minColumnWidth     = 4  // Narrowest a column's content gets, enough for NULL
+	maxAutoColumnWidth = 40 // Widest auto-fit makes a column; wider has to be asked for
+	columnSampleRows   = 200
+	columnWidthStep    = 2
+)
+
+// layoutKey names the active result for its saved column layout: the table
+// for results opened from the explorer, otherwise the query that produced
+// them.
+func (m *BrowserModel) layoutKey() string {
+	if m.tableQuery != nil {
+		return "table:" + m.tableQuery.Table
+	}
+	if m.activeTab < 0 || m.activeTab >= len(m.resultTabs) {
+		return ""
+	}
+	query := strings.Join(strings.Fields(m.resultTabs[m.activeTab].Query), " ")
+	if query == "" {
+		return ""
+	}
+	return "query:" + query
+}
+
+// columnLayout returns the active result's column layout, loading the saved
+// one the first time the table or query is shown.
+func (m *BrowserModel) columnLayout() *config.ColumnLayout {
+	key := m.layoutKey()
+	if l, ok := m.columnLayouts[key]; ok {
+		return l
+	}
+	l := &config.ColumnLayout{}
+	if cfg := config.Get(); cfg != nil && key != "" {
+		if saved, ok := cfg.GetColumnLayout(m.connKey, key); ok {
+			*l = saved
+		}
+	}
+	if m.columnLayouts == nil {
+		m.columnLayouts = make(map[string]*config.ColumnLayout)
+	}
+	m.columnLayouts[key] = l
+	return l
+}
+
+// saveColumnLayout persists the active result's column layout in the
+// background.
+func (m *BrowserModel) saveColumnLayout() {
+	cfg := config.Get()
+	key := m.layoutKey()
+	if cfg == nil || m.connKey == "" || key == "" {
+		return
+	}
+	l := *m.columnLayout()
+	widths := make(map[string]int, len(l.Widths))
+	for name, w := range l.Widths {
+		widths[name] = w
+	}
+	l.Widths = widths
+	l.Hidden = slices.Clone(l.Hidden)
+	l.Order = slices.Clone(l.Order)
+	go cfg.SetColumnLayout(m.connKey, key, l)
+}
+
+// displayColumns returns the indexes of the columns the grid shows, in the
+// order it shows them: columns named in the layout's order first, then the
+// rest as the query returned them, leaving out hidden ones.
+func displayColumns(columns []string, l *config.ColumnLayout) []int {
+	used := make([]bool, len(columns))
+	order := make([]int, 0, len(columns))
+	add := func(i int) {
+		used[i] = true
+		if !slices.Contains(l.Hidden, columns[i]) {
+			order = append(order, i)
+		}
+	}
+	for _, name := range l.Order {
+		if i := slices.Index(columns, name); i >= 0 && !used[i] {
+			add(i)
+		}
+	}
+	for i := range columns {
+		if !used[i] {
+			add(i)
+		}
+	}
+	return order
+}
+
+// autoColumnWidths sizes each column to fit its name and the values in a
+// sample of its rows, within minColumnWidth and maxAutoColumnWidth. Widths
+// are worked out once per result so they do not shift while filtering.
+func (m *BrowserModel) autoColumnWidths(r *models.QueryResult, format cellFormat) []int {
+	if r == m.autoWidthsOf && len(m.autoWidths) == len(r.Columns) {
+		return m.autoWidths
+	}
+	widths := make([]int, len(r.Columns))
+	for i, col := range r.Columns {
+		widths[i] = lipgloss.Width(col)
+	}
+	for _, row := range r.Rows[:min(len(r.Rows), columnSampleRows)] {
+		for i := range min(len(row), len(widths)) {
+			text, _ := formatCell(row[i], columnType(r, i), format)
+			line, _, _ := strings.Cut(text, "\n")
+			widths[i] = max(widths[i], lipgloss.Width(line))
+		}
+	}
+	for i := range widths {
+		widths[i] = min(max(widths[i], minColumnWidth), maxAutoColumnWidth)
+	}
+	m.autoWidths, m.autoWidthsOf = widths, r
+	return widths
+}
+
+// visibleColumns returns the columns the grid shows and makes sure the
+// column cursor is on one of them.
+func (m *BrowserModel) visibleColumns() []int {
+	active := m.activeResultSet()
+	if active == nil {
+		return nil
+	}
+	order := displayColumns(active.Columns, m.columnLayout())
+	if len(order) > 0 && !slices.Contains(order, m.resultsCursorCol) {
+		// Keep to the nearest shown column after the hidden one
+		next := order[len(order)-1]
+		for _, i := range order {
+			if i > m.resultsCursorCol {
+				next = i
+				break
+			}
+		}
+		m.resultsCursorCol = next
+	}
+	return order
+}
+
+// moveColumnCursor moves the column cursor delta columns along the grid.
+func (m *BrowserModel) moveColumnCursor(delta int) {
+	order := m.visibleColumns()
+	if len(order) == 0 {
+		return
+	}
+	pos := slices.Index(order, m.resultsCursorCol)
+	m.resultsCursorCol = order[min(max(pos+delta, 0), len(order)-1)]
+}
+
+// resizeColumn widens or narrows the highlighted column by delta cells.
+func (m *BrowserModel) resizeColumn(delta int) {
+	active := m.activeResultSet()
+	order := m.visibleColumns()
+	if active == nil || len(order) == 0 {
+		return
+	}
+	name := active.Columns[m.resultsCursorCol]
+	l := m.columnLayout()
+	width, ok := l.Widths[name]
+	if !ok {
+		width = m.autoColumnWidths(m.currentResults, m.cellFormat())[m.resultsCursorCol]
+	}
+	width = max(width+delta, minColumnWidth)
+	if l.Widths == nil {
+		l.Widths = make(map[string]int)
+	}
+	l.Widths[name] = width
+	m.statusMsg = fmt.Sprintf("%s: width %d", name, width)
+	m.saveColumnLayout()
+}
+
+// autoFitColumn returns the highlighted column to its auto-fit width.
+func (m *BrowserModel) autoFitColumn() {
+	active := m.activeResultSet()
+	if active == nil || len(m.visibleColumns()) == 0 {
+		return
+	}
+	name := active.Columns[m.resultsCursorCol]
+	delete(m.columnLayout().Widths, name)
+	m.statusMsg = name + ": auto width"
+	m.saveColumnLayout()
+}
+
+// hideColumn hides the highlighted column. The last shown column stays.
+func (m *BrowserModel) hideColumn() {
+	active := m.activeResultSet()
+	order := m.visibleColumns()
+	if active == nil || len(order) == 0 {
+		return
+	}
+	if len(order) == 1 {
+		m.statusMsg = "Cannot hide the only column"
+		return
+	}
+	name := active.Columns[m.resultsCursorCol]
+	l := m.columnLayout()
+	l.Hidden = append(l.Hidden, name)
+	m.visibleColumns()
+	m.statusMsg = fmt.Sprintf("Hid %s (%d hidden, + shows all)", name, len(l.Hidden))
+	m.saveColumnLayout()
+}
+
+// showHiddenColumns brings back every hidden column.
+func (m *BrowserModel) showHiddenColumns() {
+	l := m.columnLayout()
+	if len(l.Hidden) == 0 {
+		m.statusMsg = "No hidden columns"
+		return
+	}
+	m.statusMsg = fmt.Sprintf("Showing %d hidden column(s)", len(l.Hidden))
+	l.Hidden = nil
+	m.saveColumnLayout()
+}
+
+// moveColumn moves the highlighted column delta places along the grid.
+func (m *BrowserModel) moveColumn(delta int) {
+	active := m.activeResultSet()
+	order := m.visibleColumns()
+	if active == nil || len(order) == 0 {
+		return
+	}
+	pos := slices.Index(order, m.resultsCursorCol)
+	target := pos + delta
+	if target < 0 || target >= len(order) {
+		return
+	}
+	order[pos], order[target] = order[target], order[pos]
+
+	// Store every column's name, hidden ones after the shown ones
+	l := m.columnLayout()
+	names := make([]string, 0, len(active.Columns))
+	for _, i := range order {
+		names = append(names, active.Columns[i])
+	}
+	for _, name := range active.Columns {
+		if !slices.Contains(names, name) {
+			names = append(names, name)
+		}
+	}
+	l.Order = names
+	m.saveColumnLayout()
+}
+
+// freezeColumns keeps the columns up to and including the highlighted one in
+// view while scrolling. Freezing at the same column again unfreezes.
+func (m *BrowserModel) freezeColumns() {
+	order := m.visibleColumns()
+	if len(order) == 0 {
+		return
+	}
+	n := slices.Index(order, m.resultsCursorCol) + 1
+	l := m.columnLayout()
+	if l.Frozen == n {
+		n = 0
+	}
+	m.setFrozenColumns(n)
+}
+
+// setFrozenColumns freezes the first n shown columns.
+func (m *BrowserModel) setFrozenColumns(n int) {
+	l := m.columnLayout()
+	l.Frozen = max(n, 0)
+	m.resultsScrollCol = 0
+	if l.Frozen == 0 {
+		m.statusMsg = "Columns unfrozen"
+	} else {
+		m.statusMsg = fmt.Sprintf("Froze %d column(s)", l.Frozen)
+	}
+	m.saveColumnLayout()
+}
+
+// columnsCommand handles ":columns": "reset" forgets the layout, "freeze N"
+// freezes the first N columns and "show" brings back hidden columns.
+func (m *BrowserModel) columnsCommand(arg string) {
+	if m.activeResultSet() == nil {
+		m.statusMsg = "No results"
+		return
+	}
+	verb, rest, _ := strings.Cut(strings.TrimSpace(arg), " ")
+	switch verb {
+	case "reset":
+		*m.columnLayout() = config.ColumnLayout{}
+		m.resultsScrollCol = 0
+		m.statusMsg = "Column layout reset"
+		m.saveColumnLayout()
+	case "freeze":
+		n, err := strconv.Atoi(strings.TrimSpace(rest))
+		if err != nil || n < 0 {
+			m.statusMsg = "Usage: columns freeze <n>"
+			return
+		}
+		m.setFrozenColumns(n)
+	case "show":
+		m.showHiddenColumns()
+	default:
+		m.statusMsg = "Usage: columns reset|freeze <n>|show"
+	}
+}
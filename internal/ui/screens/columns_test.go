@@ -0,0 +1,28 @@
+package screens
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/jupiterozeye/tornado/internal/config"
+)
+
+func TestDisplayColumns(t *testing.T) {
+	columns := []string{"id", "name", "email", "created"}
+	tests := []struct {
+		layout config.ColumnLayout
+		want   []int
+	}{
+		{config.ColumnLayout{}, []int{0, 1, 2, 3}},
+		{config.ColumnLayout{Hidden: []string{"email"}}, []int{0, 1, 3}},
+		// Columns the order does not name keep their place after it
+		{config.ColumnLayout{Order: []string{"created", "id"}}, []int{3, 0, 1, 2}},
+		// Names no longer in the result are skipped
+		{config.ColumnLayout{Order: []string{"gone", "name"}, Hidden: []string{"id"}}, []int{1, 2, 3}},
+	}
+	for _, tt := range tests {
+		if got := displayColumns(columns, &tt.layout); !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("displayColumns(%+v) = %v, want %v", tt.layout, got, tt.want)
+		}
+	}
+}
@@ -1151,11 +1151,18 @@ func (m *ConnectModel) startConnection() tea.Cmd {
 			if err != nil {
 				return ConnectErrorMsg{Err: err.Error()}
 			}
-			return ConnectSuccessMsg{DB: database}
+			return ConnectSuccessMsg{DB: database, Config: config}
 		},
 	)
 }
 
+// ConnectTo starts connecting to the SQLite database at path, as if it had
+// been entered in the form.
+func (m *ConnectModel) ConnectTo(path string) tea.Cmd {
+	m.pathInput.SetValue(path)
+	return m.startConnection()
+}
+
 func (m *ConnectModel) getConfig() models.ConnectionConfig {
 	return models.ConnectionConfig{
 		Type: "sqlite",
@@ -1186,7 +1193,8 @@ func (m *ConnectModel) truncateError(s string, width int) string {
 
 // Message types
 type ConnectSuccessMsg struct {
-	DB db.Database
+	DB     db.Database
+	Config models.ConnectionConfig
 }
 
 type ConnectErrorMsg struct {
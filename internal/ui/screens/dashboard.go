@@ -31,9 +31,9 @@
 // TODO: Implement the dashboard screen:
 //   - [ ] Define DashboardModel struct
 //   - [ ] Implement real-time metrics subscription
-//   - [ ] Implement line chart for queries/sec (ntcharts)
-//   - [ ] Implement bar chart for query distribution (ntcharts)
-//   - [ ] Implement sparklines for mini-stats
+//   - [ ] Implement line chart for queries/sec (components.LineChart)
+//   - [ ] Implement bar chart for query distribution (components.BarChart)
+//   - [ ] Implement sparklines for mini-stats (components.Sparkline)
 //   - [ ] Add stats summary display
 //   - [ ] Add slow query list
 //   - [ ] Add error log
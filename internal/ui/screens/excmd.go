@@ -0,0 +1,797 @@
+package screens
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+	"sort"
+	"strings"
+
+	tea "charm.land/bubbletea/v2"
+
+	"github.com/jupiterozeye/tornado/internal/config"
+	"github.com/jupiterozeye/tornado/internal/models"
+	"github.com/jupiterozeye/tornado/internal/ui/styles"
+)
+
+// exCommand is a command available on the ":" command line.
+type exCommand struct {
+	Name    string
+	Aliases []string
+	Usage   string
+	Help    string
+	// Complete returns candidates for the last word of args, or nil.
+	Complete func(m *BrowserModel, args []string) []string
+	Run      func(m *BrowserModel, arg string, force bool) tea.Cmd
+}
+
+// exCommands lists every command line command. It is filled in by init
+// because some commands (help) refer back to the list.
+var exCommands []exCommand
+
+func init() {
+	exCommands = []exCommand{
+		{Name: "edit", Aliases: []string{"e"}, Usage: "edit[!] [file]", Help: "Open a SQL file, or reload the current one",
+			Complete: completeFileArg, Run: func(m *BrowserModel, arg string, force bool) tea.Cmd {
+				m.editFile(arg, force)
+				return nil
+			}},
+		{Name: "write", Aliases: []string{"w"}, Usage: "write[!] [file]", Help: "Write the buffer to its file or to file; ! overwrites another file",
+			Complete: completeFileArg, Run: func(m *BrowserModel, arg string, force bool) tea.Cmd {
+				m.writeFile(arg, force)
+				return nil
+			}},
+		{Name: "quit", Aliases: []string{"q"}, Usage: "quit[!]", Help: "Quit tornado",
+			Run: func(m *BrowserModel, _ string, force bool) tea.Cmd {
+				return m.quit(force)
+			}},
+		{Name: "wq", Aliases: []string{"x"}, Usage: "wq", Help: "Write the buffer, then quit",
+			Run: func(m *BrowserModel, _ string, _ bool) tea.Cmd {
+				if !m.writeFile("", false) {
+					return nil
+				}
+				return m.quit(false)
+			}},
+		{Name: "editor", Usage: "editor[!]", Help: "Edit the query in $VISUAL or $EDITOR; ! runs it afterwards",
+			Run: func(m *BrowserModel, _ string, force bool) tea.Cmd {
+				return m.openExternalEditor(force)
+			}},
+		{Name: "run", Usage: "run", Help: "Execute the query buffer",
+			Run: func(m *BrowserModel, _ string, _ bool) tea.Cmd {
+				return m.executeQuery()
+			}},
+		{Name: "watch", Usage: "watch [seconds|pause|off]", Help: "Re-run the query every few seconds, highlighting changed cells",
+			Complete: func(_ *BrowserModel, args []string) []string {
+				if len(args) > 1 {
+					return nil
+				}
+				return []string{"pause", "off"}
+			}, Run: func(m *BrowserModel, arg string, _ bool) tea.Cmd {
+				return m.watchQuery(arg)
+			}},
+		{Name: "connect", Usage: "connect <name|path>", Help: "Connect to a saved SQLite connection or file",
+			Complete: completeConnectArg, Run: func(m *BrowserModel, arg string, _ bool) tea.Cmd {
+				return m.connectTo(arg)
+			}},
+		{Name: "disconnect", Usage: "disconnect", Help: "Return to the connect screen",
+			Run: func(m *BrowserModel, _ string, _ bool) tea.Cmd {
+				return func() tea.Msg { return RequestConnectMsg{} }
+			}},
+		{Name: "theme", Usage: "theme <name>", Help: "Switch the color theme",
+			Complete: func(_ *BrowserModel, args []string) []string {
+				if len(args) > 1 {
+					return nil
+				}
+				return styles.AvailableThemes()
+			}, Run: func(m *BrowserModel, arg string, _ bool) tea.Cmd {
+				m.setTheme(arg)
+				return nil
+			}},
+		{Name: "export", Usage: "export <csv|tsv|json> <file>", Help: "Write the current result set to a file",
+			Complete: func(m *BrowserModel, args []string) []string {
+				if len(args) <= 1 {
+					return []string{"csv", "tsv", "json"}
+				}
+				return completeFileArg(m, args[1:])
+			}, Run: func(m *BrowserModel, arg string, _ bool) tea.Cmd {
+				m.exportResults(arg)
+				return nil
+			}},
+		{Name: "columns", Aliases: []string{"cols"}, Usage: "columns reset|freeze <n>|show", Help: "Reset the results column layout, freeze the first n columns or show hidden ones",
+			Complete: func(_ *BrowserModel, args []string) []string {
+				if len(args) > 1 {
+					return nil
+				}
+				return []string{"reset", "freeze", "show"}
+			}, Run: func(m *BrowserModel, arg string, _ bool) tea.Cmd {
+				m.columnsCommand(arg)
+				return nil
+			}},
+		{Name: "chart", Usage: "chart [bar|line|spark|off]", Help: "Plot the results as a bar chart, line chart or sparklines",
+			Complete: func(_ *BrowserModel, args []string) []string {
+				if len(args) > 1 {
+					return nil
+				}
+				return []string{"bar", "line", "spark", "off"}
+			}, Run: func(m *BrowserModel, arg string, _ bool) tea.Cmd {
+				m.chartCommand(arg)
+				return nil
+			}},
+		{Name: "diff", Usage: "diff [base|off|<key,...>]", Help: "Mark the baseline result, or diff the current result against it by key columns",
+			Complete: func(m *BrowserModel, args []string) []string {
+				if len(args) > 1 {
+					return nil
+				}
+				options := []string{"base", "off"}
+				if m.currentResults != nil {
+					options = append(options, m.currentResults.Columns...)
+				}
+				return options
+			}, Run: func(m *BrowserModel, arg string, _ bool) tea.Cmd {
+				return m.diffCommand(arg)
+			}},
+		{Name: "set", Usage: "set [no]readonly|[no]watch|[no]autorerun", Help: "Set readonly, watch (flag external database changes) or autorerun (re-run results on change)",
+			Complete: func(_ *BrowserModel, args []string) []string {
+				if len(args) > 1 {
+					return nil
+				}
+				return []string{"readonly", "noreadonly", "watch", "nowatch", "autorerun", "noautorerun"}
+			}, Run: func(m *BrowserModel, arg string, _ bool) tea.Cmd {
+				return m.setOption(arg)
+			}},
+		{Name: "describe", Aliases: []string{"desc"}, Usage: "describe <table>", Help: "Show a table's columns in the results pane",
+			Complete: func(m *BrowserModel, args []string) []string {
+				if len(args) > 1 {
+					return nil
+				}
+				return m.tables
+			}, Run: func(m *BrowserModel, arg string, _ bool) tea.Cmd {
+				return m.describeTable(arg)
+			}},
+		{Name: "bnext", Aliases: []string{"bn"}, Usage: "bnext", Help: "Switch to the next buffer",
+			Run: func(m *BrowserModel, _ string, _ bool) tea.Cmd {
+				m.switchBuffer(1)
+				return nil
+			}},
+		{Name: "bprevious", Aliases: []string{"bp"}, Usage: "bprevious", Help: "Switch to the previous buffer",
+			Run: func(m *BrowserModel, _ string, _ bool) tea.Cmd {
+				m.switchBuffer(-1)
+				return nil
+			}},
+		{Name: "enew", Usage: "enew", Help: "Open a new empty buffer",
+			Run: func(m *BrowserModel, _ string, _ bool) tea.Cmd {
+				m.newBuffer()
+				return nil
+			}},
+		{Name: "bdelete", Aliases: []string{"bd"}, Usage: "bdelete[!]", Help: "Close the buffer",
+			Run: func(m *BrowserModel, _ string, force bool) tea.Cmd {
+				if m.bufferModified(m.activeBuffer) && !force {
+					m.statusMsg = "No write since last change (add ! to override)"
+					return nil
+				}
+				m.closeBuffer()
+				return nil
+			}},
+		{Name: "file", Aliases: []string{"f"}, Usage: "file <name>", Help: "Rename the buffer",
+			Run: func(m *BrowserModel, arg string, _ bool) tea.Cmd {
+				m.renameBuffer(arg)
+				return nil
+			}},
+		{Name: "explorer", Usage: "explorer", Help: "Toggle the explorer pane",
+			Run: func(m *BrowserModel, _ string, _ bool) tea.Cmd {
+				_, cmd := m.executeLeaderCommand("e")
+				return cmd
+			}},
+		{Name: "maximize", Usage: "maximize", Help: "Toggle maximizing the focused pane",
+			Run: func(m *BrowserModel, _ string, _ bool) tea.Cmd {
+				_, cmd := m.executeLeaderCommand("f")
+				return cmd
+			}},
+		{Name: "format", Aliases: []string{"fmt"}, Usage: "format", Help: "Format the query buffer",
+			Run: func(m *BrowserModel, _ string, _ bool) tea.Cmd {
+				m.formatQuery()
+				return nil
+			}},
+		{Name: "help", Aliases: []string{"h"}, Usage: "help", Help: "List commands",
+			Run: func(m *BrowserModel, _ string, _ bool) tea.Cmd {
+				m.showCommandHelp()
+				return nil
+			}},
+	}
+}
+
+// lookupExCommand finds a command by name, alias or unique prefix.
+func lookupExCommand(name string) (*exCommand, error) {
+	var matches []*exCommand
+	for i := range exCommands {
+		c := &exCommands[i]
+		if c.Name == name {
+			return c, nil
+		}
+		for _, a := range c.Aliases {
+			if a == name {
+				return c, nil
+			}
+		}
+		if strings.HasPrefix(c.Name, name) {
+			matches = append(matches, c)
+		}
+	}
+	switch len(matches) {
+	case 0:
+		return nil, fmt.Errorf("not an editor command: %s", name)
+	case 1:
+		return matches[0], nil
+	default:
+		return nil, fmt.Errorf("ambiguous command: %s", name)
+	}
+}
+
+// openCommandLine opens the ":" command line.
+func (m *BrowserModel) openCommandLine() {
+	m.commandLineActive = true
+	m.commandLineInput = ""
+	m.resetCompletion()
+	m.statusMsg = ""
+}
+
+// handleCommandLineKey handles key presses while the ":" command line is open.
+func (m *BrowserModel) handleCommandLineKey(msg tea.KeyPressMsg) (tea.Model, tea.Cmd) {
+	switch msg.String() {
+	case "tab":
+		m.completeCommandLine(1)
+		return m, nil
+	case "shift+tab":
+		m.completeCommandLine(-1)
+		return m, nil
+	}
+
+	m.resetCompletion()
+	switch msg.String() {
+	case "esc":
+		m.commandLineActive = false
+		m.statusMsg = ""
+	case "enter":
+		m.commandLineActive = false
+		return m, m.runExCommand(m.commandLineInput)
+	case "backspace":
+		if m.commandLineInput == "" {
+			m.commandLineActive = false
+			return m, nil
+		}
+		runes := []rune(m.commandLineInput)
+		m.commandLineInput = string(runes[:len(runes)-1])
+	default:
+		if msg.Text != "" {
+			m.commandLineInput += msg.Text
+		}
+	}
+	return m, nil
+}
+
+// runExCommand runs a command line such as "w queries/report.sql".
+func (m *BrowserModel) runExCommand(line string) tea.Cmd {
+	line = strings.TrimSpace(strings.TrimPrefix(strings.TrimSpace(line), ":"))
+	if line == "" {
+		return nil
+	}
+	name, arg, _ := strings.Cut(line, " ")
+	arg = strings.TrimSpace(arg)
+	force := strings.HasSuffix(name, "!")
+	name = strings.TrimSuffix(name, "!")
+
+	cmd, err := lookupExCommand(name)
+	if err != nil {
+		m.statusMsg = err.Error()
+		return nil
+	}
+	return cmd.Run(m, arg, force)
+}
+
+// completeCommandLine completes the word before the cursor, cycling through
+// the candidates on repeated presses. dir is 1 for tab and -1 for shift+tab.
+func (m *BrowserModel) completeCommandLine(dir int) {
+	if m.completions == nil {
+		m.completionBase, m.completions = m.commandLineCandidates(m.commandLineInput)
+		m.completionIndex = -1
+		if len(m.completions) == 0 {
+			m.completions = nil
+			m.statusMsg = "No completions"
+			return
+		}
+	}
+	n := len(m.completions)
+	m.completionIndex = ((m.completionIndex+dir)%n + n) % n
+	m.commandLineInput = m.completionBase + m.completions[m.completionIndex]
+	m.statusMsg = ""
+}
+
+// resetCompletion forgets the candidates of the last tab completion.
+func (m *BrowserModel) resetCompletion() {
+	m.completions = nil
+	m.completionIndex = -1
+	m.completionBase = ""
+}
+
+// commandLineCandidates returns the text before the word being completed and
+// the candidates for that word.
+func (m *BrowserModel) commandLineCandidates(input string) (string, []string) {
+	name, rest, hasArgs := strings.Cut(input, " ")
+	if !hasArgs {
+		var names []string
+		for _, c := range exCommands {
+			if strings.HasPrefix(c.Name, name) {
+				names = append(names, c.Name)
+			}
+		}
+		return "", names
+	}
+
+	cmd, err := lookupExCommand(strings.TrimSuffix(name, "!"))
+	if err != nil || cmd.Complete == nil {
+		return "", nil
+	}
+	args := strings.Fields(rest)
+	if rest == "" || strings.HasSuffix(rest, " ") {
+		args = append(args, "")
+	}
+	word := args[len(args)-1]
+	base := input[:len(input)-len(word)]
+
+	var out []string
+	for _, c := range cmd.Complete(m, args) {
+		if strings.HasPrefix(strings.ToLower(c), strings.ToLower(word)) {
+			out = append(out, c)
+		}
+	}
+	return base, out
+}
+
+// commandLineHint returns the candidates listed after the command line while
+// completing, with the selected one bracketed.
+func (m *BrowserModel) commandLineHint() string {
+	if len(m.completions) < 2 {
+		return ""
+	}
+	parts := make([]string, len(m.completions))
+	for i, c := range m.completions {
+		if i == m.completionIndex {
+			c = "[" + c + "]"
+		}
+		parts[i] = c
+	}
+	return "  " + strings.Join(parts, " ")
+}
+
+// completeFileArg completes the last argument as a path, listing directories
+// and .sql files.
+func completeFileArg(_ *BrowserModel, args []string) []string {
+	if len(args) == 0 {
+		return nil
+	}
+	word := args[len(args)-1]
+	dir, prefix := filepath.Split(word)
+	readDir := dir
+	if readDir == "" {
+		readDir = "."
+	}
+	if strings.HasPrefix(readDir, "~") {
+		readDir = expandPath(readDir)
+	}
+	entries, err := os.ReadDir(readDir)
+	if err != nil {
+		return nil
+	}
+	var out []string
+	for _, e := range entries {
+		name := e.Name()
+		if !strings.HasPrefix(name, prefix) || (strings.HasPrefix(name, ".") && !strings.HasPrefix(prefix, ".")) {
+			continue
+		}
+		switch {
+		case e.IsDir():
+			out = append(out, dir+name+string(filepath.Separator))
+		case strings.EqualFold(filepath.Ext(name), ".sql"):
+			out = append(out, dir+name)
+		}
+	}
+	return out
+}
+
+// completeConnectArg completes saved connection names.
+func completeConnectArg(_ *BrowserModel, args []string) []string {
+	if len(args) > 1 {
+		return nil
+	}
+	cfg := config.Get()
+	if cfg == nil {
+		return nil
+	}
+	var names []string
+	for _, c := range cfg.GetConnections() {
+		if c.Type == "" || c.Type == "sqlite" {
+			names = append(names, c.Name)
+		}
+	}
+	return names
+}
+
+// quit exits the app, refusing while file-backed buffers have unsaved changes
+// unless force is set.
+func (m *BrowserModel) quit(force bool) tea.Cmd {
+	if !force {
+		for i := range m.buffers {
+			if m.bufferModified(i) {
+				m.statusMsg = fmt.Sprintf("No write since last change for buffer %q (add ! to override)", m.buffers[i].Name)
+				return nil
+			}
+		}
+	}
+	m.saveBuffers(true)
+	return tea.Quit
+}
+
+// connectTo switches to a saved SQLite connection by name, or to a SQLite
+// file path. Saved connections of other types are refused.
+func (m *BrowserModel) connectTo(target string) tea.Cmd {
+	if target == "" {
+		return func() tea.Msg { return RequestConnectMsg{} }
+	}
+	path := target
+	if cfg := config.Get(); cfg != nil {
+		for _, c := range cfg.GetConnections() {
+			if c.Name != target {
+				continue
+			}
+			// The connect screen takes a file path; other databases need
+			// their settings and password entered there
+			if c.Type != "" && c.Type != "sqlite" {
+				m.statusMsg = fmt.Sprintf("%s is a %s connection: connect only opens SQLite files", c.Name, c.Type)
+				return nil
+			}
+			path = c.Path
+			break
+		}
+	}
+	return func() tea.Msg { return RequestConnectMsg{Path: path} }
+}
+
+// setTheme applies and saves a theme by name.
+func (m *BrowserModel) setTheme(name string) {
+	if name == "" {
+		m.statusMsg = "Theme: " + styles.CurrentTheme()
+		return
+	}
+	if !m.applyTheme(name) {
+		m.statusMsg = "Unknown theme: " + name
+		return
+	}
+	if cfg := config.Get(); cfg != nil {
+		go cfg.SetTheme(name)
+	}
+	m.statusMsg = "Theme: " + name
+}
+
+// setOption handles ":set". Options are switched on by name, off with a
+// "no" prefix and toggled with a "!" suffix or "inv" prefix; with no
+// argument the current settings are shown.
+func (m *BrowserModel) setOption(opt string) tea.Cmd {
+	if opt == "" {
+		m.statusMsg = strings.Join([]string{
+			optionState("readonly", m.readOnly),
+			optionState("watch", m.watch),
+			optionState("autorerun", m.autoRerun),
+		}, " ")
+		return nil
+	}
+
+	name, value, toggle := opt, true, false
+	switch {
+	case strings.HasSuffix(name, "!"):
+		name, toggle = strings.TrimSuffix(name, "!"), true
+	case strings.HasPrefix(name, "inv"):
+		name, toggle = strings.TrimPrefix(name, "inv"), true
+	case strings.HasPrefix(name, "no"):
+		name, value = strings.TrimPrefix(name, "no"), false
+	}
+
+	var flag *bool
+	switch name {
+	case "readonly", "ro":
+		name, flag = "readonly", &m.readOnly
+	case "watch":
+		flag = &m.watch
+	case "autorerun", "arr":
+		name, flag = "autorerun", &m.autoRerun
+	default:
+		m.statusMsg = "Unknown option: " + opt
+		return nil
+	}
+	if toggle {
+		value = !*flag
+	}
+	*flag = value
+	m.statusMsg = optionState(name, value)
+
+	if name == "readonly" {
+		return m.applyReadOnly(value)
+	}
+	return m.restartWatch()
+}
+
+// ReadOnlyMsg reports whether the database accepted the readonly option.
+type ReadOnlyMsg struct {
+	On  bool
+	Err error
+}
+
+// applyReadOnly has the database itself refuse writes while readonly is set,
+// so statements the client-side check lets through still cannot write.
+func (m *BrowserModel) applyReadOnly(on bool) tea.Cmd {
+	if m.db == nil {
+		return nil
+	}
+	db := m.db
+	return func() tea.Msg {
+		return ReadOnlyMsg{On: on, Err: db.SetReadOnly(on)}
+	}
+}
+
+// optionState formats a boolean option the way ":set" shows it.
+func optionState(name string, on bool) string {
+	if on {
+		return name
+	}
+	return "no" + name
+}
+
+// describeTable shows the columns of a table as a result set.
+func (m *BrowserModel) describeTable(name string) tea.Cmd {
+	if name == "" {
+		m.statusMsg = "Usage: describe <table>"
+		return nil
+	}
+	if m.db == nil {
+		m.statusMsg = "Not connected"
+		return nil
+	}
+	label := "DESCRIBE " + name
+	return func() tea.Msg {
+		schema, err := m.db.DescribeTable(name)
+		if err != nil {
+			return QueryExecutedMsg{Err: err, Query: label}
+		}
+		if schema == nil {
+			return QueryExecutedMsg{Err: fmt.Errorf("table %q not found", name), Query: label}
+		}
+		result := &models.QueryResult{
+			Columns: []string{"column", "type", "nullable", "default", "key"},
+			Query:   label,
+		}
+		for _, c := range schema.Columns {
+			def := ""
+			if c.DefaultValue != nil {
+				def = *c.DefaultValue
+			}
+			key := ""
+			switch {
+			case c.IsPrimaryKey:
+				key = "PK"
+			case c.IsForeignKey:
+				key = fmt.Sprintf("FK %s.%s", c.ForeignKeyTable, c.ForeignKeyColumn)
+			default:
+				for _, fk := range schema.ForeignKeys {
+					if len(fk.Columns) > 1 && slices.Contains(fk.Columns, c.Name) {
+						key = fmt.Sprintf("FK %s(%s)", fk.RefTable, strings.Join(fk.RefColumns, ", "))
+					}
+				}
+			}
+			result.Rows = append(result.Rows, []any{c.Name, c.Type, c.Nullable, def, key})
+		}
+		result.RowCount = len(result.Rows)
+		return QueryExecutedMsg{Result: result, Query: label}
+	}
+}
+
+// exportResults writes the current result set as "<format> <file>".
+func (m *BrowserModel) exportResults(arg string) {
+	format, path, _ := strings.Cut(arg, " ")
+	path = strings.TrimSpace(path)
+	if format == "" || path == "" {
+		m.statusMsg = "Usage: export <csv|tsv|json> <file>"
+		return
+	}
+	active := m.activeResultSet()
+	if active == nil {
+		m.statusMsg = "No results to export"
+		return
+	}
+	path = expandPath(path)
+
+	f, err := os.Create(path)
+	if err != nil {
+		m.statusMsg = "Export failed: " + err.Error()
+		return
+	}
+	defer f.Close()
+
+	switch format {
+	case "csv", "tsv":
+		w := csv.NewWriter(f)
+		if format == "tsv" {
+			w.Comma = '\t'
+		}
+		w.Write(active.Columns)
+		for _, row := range active.Rows {
+			record := make([]string, len(row))
+			for i, cell := range row {
+				if cell != nil {
+					record[i] = fmt.Sprintf("%v", cell)
+				}
+			}
+			w.Write(record)
+		}
+		w.Flush()
+		err = w.Error()
+	case "json":
+		rows := make([]map[string]any, len(active.Rows))
+		for i, row := range active.Rows {
+			obj := make(map[string]any, len(row))
+			for j, cell := range row {
+				if j < len(active.Columns) {
+					if b, ok := cell.([]byte); ok {
+						cell = string(b)
+					}
+					obj[active.Columns[j]] = cell
+				}
+			}
+			rows[i] = obj
+		}
+		enc := json.NewEncoder(f)
+		enc.SetIndent("", "  ")
+		err = enc.Encode(rows)
+	default:
+		m.statusMsg = "Unknown export format: " + format
+		return
+	}
+	if err != nil {
+		m.statusMsg = "Export failed: " + err.Error()
+		return
+	}
+	m.statusMsg = fmt.Sprintf("Exported %d rows to %s", len(active.Rows), path)
+}
+
+// showCommandHelp lists the command line commands in the preview popup.
+func (m *BrowserModel) showCommandHelp() {
+	cmds := make([]exCommand, len(exCommands))
+	copy(cmds, exCommands)
+	sort.Slice(cmds, func(i, j int) bool { return cmds[i].Name < cmds[j].Name })
+
+	var b strings.Builder
+	for _, c := range cmds {
+		fmt.Fprintf(&b, ":%-30s %s\n", c.Usage, c.Help)
+	}
+	b.WriteString("\nTab completes command names and arguments.")
+	m.showPreview = true
+	m.previewTitle = "Commands"
+	m.previewContent = b.String()
+	m.statusMsg = "Commands: esc to close"
+}
+
+// editFile opens a SQL file. A buffer already showing the file is switched to.
+// Otherwise the file replaces the active buffer, unless that buffer holds
+// scratch text, in which case it opens in a new buffer. Unsaved changes to a
+// file-backed buffer are only discarded when force is set. With no path, the
+// active buffer's file is reloaded.
+func (m *BrowserModel) editFile(path string, force bool) {
+	cur := m.currentBuffer()
+	if path == "" {
+		if cur.Path == "" {
+			m.statusMsg = "No file name"
+			return
+		}
+		path = cur.Path
+	}
+	path = expandPath(path)
+
+	data, err := os.ReadFile(path)
+	if err != nil && !os.IsNotExist(err) {
+		m.statusMsg = "Open failed: " + err.Error()
+		return
+	}
+
+	target := -1
+	for i, b := range m.buffers {
+		if b.Path == path {
+			target = i
+			break
+		}
+	}
+	switch {
+	case target >= 0 && target != m.activeBuffer:
+		m.stashBuffer()
+		m.loadBuffer(target)
+		if !force {
+			m.statusMsg = fmt.Sprintf("%q", path)
+			m.saveBuffers(false)
+			return
+		}
+	case m.bufferModified(m.activeBuffer) && !force:
+		m.statusMsg = "No write since last change (add ! to override)"
+		return
+	case target < 0 && cur.Path == "" && strings.TrimSpace(m.query.Value()) != "":
+		m.stashBuffer()
+		buf := newQueryBuffer(filepath.Base(path))
+		i := m.activeBuffer + 1
+		m.buffers = append(m.buffers[:i], append([]*queryBuffer{buf}, m.buffers[i:]...)...)
+		m.loadBuffer(i)
+	}
+
+	buf := m.currentBuffer()
+	buf.Path = path
+	buf.Name = filepath.Base(path)
+	buf.savedText = string(data)
+	m.setQueryText(string(data))
+	m.query.MoveToBegin()
+
+	if os.IsNotExist(err) {
+		m.statusMsg = fmt.Sprintf("%q [New]", path)
+	} else {
+		m.statusMsg = fmt.Sprintf("%q %dL, %dB", path, strings.Count(buf.savedText, "\n")+1, len(data))
+	}
+	m.saveBuffers(false)
+}
+
+// writeFile writes the active buffer to path, or to its own file when path is
+// empty. A scratch buffer written to a file becomes backed by that file. An
+// existing file other than the buffer's own is only replaced with force.
+// It reports whether the write succeeded.
+func (m *BrowserModel) writeFile(path string, force bool) bool {
+	buf := m.currentBuffer()
+	if path == "" {
+		if buf.Path == "" {
+			m.statusMsg = "No file name"
+			return false
+		}
+		path = buf.Path
+	}
+	path = expandPath(path)
+	if _, err := os.Stat(path); err == nil && path != buf.Path && !force {
+		m.statusMsg = "File exists (add ! to override)"
+		return false
+	}
+
+	text := m.query.Value()
+	if err := os.WriteFile(path, []byte(text), 0644); err != nil {
+		m.statusMsg = "Write failed: " + err.Error()
+		return false
+	}
+
+	if buf.Path == "" {
+		buf.Path = path
+		buf.Name = filepath.Base(path)
+	}
+	if buf.Path == path {
+		buf.savedText = text
+	}
+	m.statusMsg = fmt.Sprintf("%q %dL, %dB written", path, strings.Count(text, "\n")+1, len(text))
+	m.saveBuffers(false)
+	return true
+}
+
+// expandPath resolves a leading ~ and makes path absolute so the same file
+// is recognised however it was typed.
+func expandPath(path string) string {
+	if path == "~" || strings.HasPrefix(path, "~/") {
+		if home, err := os.UserHomeDir(); err == nil {
+			path = filepath.Join(home, path[1:])
+		}
+	}
+	if abs, err := filepath.Abs(path); err == nil {
+		return abs
+	}
+	return path
+}
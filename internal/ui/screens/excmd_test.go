@@ -0,0 +1,169 @@
+package screens
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/jupiterozeye/tornado/internal/db"
+	"github.com/jupiterozeye/tornado/internal/models"
+)
+
+func TestExCommand_editAndWrite(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "report.sql")
+	if err := os.WriteFile(path, []byte("SELECT 1;"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	m := NewBrowserModel(nil, models.ConnectionConfig{})
+	m.runExCommand(":e " + path)
+	if got := m.query.Value(); got != "SELECT 1;" {
+		t.Fatalf("after :e, query = %q", got)
+	}
+	if m.bufferModified(m.activeBuffer) {
+		t.Error("buffer should not be modified right after :e")
+	}
+
+	m.query.SetValue("SELECT 2;")
+	if !m.bufferModified(m.activeBuffer) {
+		t.Error("buffer should be modified after editing")
+	}
+
+	m.runExCommand("e " + path)
+	if got := m.query.Value(); got != "SELECT 2;" {
+		t.Errorf(":e without ! discarded changes, query = %q", got)
+	}
+
+	m.runExCommand("w")
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(data) != "SELECT 2;" {
+		t.Errorf("file = %q, want %q", data, "SELECT 2;")
+	}
+	if m.bufferModified(m.activeBuffer) {
+		t.Error("buffer should not be modified after :w")
+	}
+
+	// Another existing file is only replaced with !
+	other := filepath.Join(filepath.Dir(path), "other.sql")
+	if err := os.WriteFile(other, []byte("keep"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	m.runExCommand("w " + other)
+	if data, _ := os.ReadFile(other); string(data) != "keep" {
+		t.Errorf(":w replaced another file: %q", data)
+	}
+	m.runExCommand("w! " + other)
+	if data, _ := os.ReadFile(other); string(data) != "SELECT 2;" {
+		t.Errorf(":w! did not replace the file: %q", data)
+	}
+}
+
+func TestLookupExCommand(t *testing.T) {
+	tests := []struct {
+		name    string
+		want    string
+		wantErr bool
+	}{
+		{name: "w", want: "write"},
+		{name: "write", want: "write"},
+		{name: "desc", want: "describe"},
+		{name: "them", want: "theme"},
+		{name: "b", wantErr: true},
+		{name: "nope", wantErr: true},
+	}
+	for _, tt := range tests {
+		cmd, err := lookupExCommand(tt.name)
+		if tt.wantErr {
+			if err == nil {
+				t.Errorf("lookupExCommand(%q) = %q, want error", tt.name, cmd.Name)
+			}
+			continue
+		}
+		if err != nil {
+			t.Errorf("lookupExCommand(%q) error: %v", tt.name, err)
+			continue
+		}
+		if cmd.Name != tt.want {
+			t.Errorf("lookupExCommand(%q) = %q, want %q", tt.name, cmd.Name, tt.want)
+		}
+	}
+}
+
+func TestSetReadonly_blocksWrites(t *testing.T) {
+	s := db.NewSQLiteDB()
+	if err := s.Connect(models.ConnectionConfig{Type: "sqlite", Path: ":memory:"}); err != nil {
+		t.Fatal(err)
+	}
+	defer s.Disconnect()
+	if _, err := s.Exec("CREATE TABLE t (id INTEGER); INSERT INTO t VALUES (1), (2)"); err != nil {
+		t.Fatal(err)
+	}
+
+	m := NewBrowserModel(s, models.ConnectionConfig{})
+	if msg, ok := m.setOption("readonly")().(ReadOnlyMsg); !ok || msg.Err != nil {
+		t.Fatalf(":set readonly = %+v", msg)
+	}
+	for _, query := range []string{
+		"WITH x AS (SELECT 1) DELETE FROM t",
+		"SELECT 1; DELETE FROM t",
+	} {
+		if msg := m.executeSQL(query)().(QueryExecutedMsg); msg.Err == nil {
+			t.Errorf("%q ran in read-only mode", query)
+		}
+		// The database refuses it too, should the check above miss a write
+		s.Query(query)
+	}
+	r, err := s.Query("SELECT COUNT(*) FROM t")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if n := r.Rows[0][0]; n != int64(2) {
+		t.Errorf("rows left = %v, want 2", n)
+	}
+}
+
+func TestWatchQuery_refusesWritesAndShortIntervals(t *testing.T) {
+	m := NewBrowserModel(nil, models.ConnectionConfig{})
+	for _, tc := range []struct{ query, arg string }{
+		{"SELECT 1", "0.000000001"},
+		{"SELECT 1", "0.5"},
+		{"WITH x AS (SELECT 1) DELETE FROM t", ""},
+		{"SELECT 1; DELETE FROM t", ""},
+	} {
+		m.query.SetValue(tc.query)
+		m.watchQuery(tc.arg)
+		if m.queryWatch != nil {
+			t.Errorf(":watch %s of %q started", tc.arg, tc.query)
+			m.queryWatch = nil
+		}
+	}
+}
+
+func TestWatchQuery_stopsWithItsTab(t *testing.T) {
+	m := NewBrowserModel(nil, models.ConnectionConfig{})
+	m.queryWatch = &queryWatch{Query: "SELECT 1", Interval: time.Second}
+	m.recordResult("SELECT 1", false)
+	m.recordResult("SELECT 2", false)
+	m.closeResultTab()
+	if m.queryWatch == nil {
+		t.Fatal("closing another tab stopped the watch")
+	}
+	m.closeResultTab()
+	if m.queryWatch != nil {
+		t.Error("closing the watched tab left the watch running")
+	}
+
+	m.queryWatch = &queryWatch{Query: "SELECT 1", Interval: time.Second}
+	m.recordResult("SELECT 1", false)
+	for i := range maxResultTabs {
+		m.recordResult(fmt.Sprint("SELECT ", i+2), false)
+	}
+	if m.queryWatch != nil {
+		t.Error("evicting the watched tab left the watch running")
+	}
+}
@@ -0,0 +1,92 @@
+package screens
+
+import (
+	"os"
+	"os/exec"
+	"strings"
+
+	tea "charm.land/bubbletea/v2"
+)
+
+// ExternalEditMsg reports that the external editor exited. Path is the
+// temporary file holding the edited query.
+type ExternalEditMsg struct {
+	Path     string
+	Original string
+	Execute  bool
+	Err      error
+}
+
+// externalEditor returns the user's editor command from $VISUAL or $EDITOR,
+// falling back to vi. The command may carry arguments, e.g. "code --wait".
+func externalEditor() []string {
+	for _, env := range []string{"VISUAL", "EDITOR"} {
+		if fields := strings.Fields(os.Getenv(env)); len(fields) > 0 {
+			return fields
+		}
+	}
+	return []string{"vi"}
+}
+
+// openExternalEditor writes the query buffer to a temporary .sql file and
+// suspends the TUI while the external editor runs on it. With execute the
+// edited query runs as soon as it is loaded back.
+func (m *BrowserModel) openExternalEditor(execute bool) tea.Cmd {
+	text := m.query.Value()
+	f, err := os.CreateTemp("", "tornado-*.sql")
+	if err != nil {
+		m.statusMsg = "Editor failed: " + err.Error()
+		return nil
+	}
+	path := f.Name()
+	_, err = f.WriteString(text)
+	if closeErr := f.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		os.Remove(path)
+		m.statusMsg = "Editor failed: " + err.Error()
+		return nil
+	}
+
+	m.saveBuffers(false)
+	editor := externalEditor()
+	cmd := exec.Command(editor[0], append(editor[1:], path)...)
+	return tea.ExecProcess(cmd, func(err error) tea.Msg {
+		return ExternalEditMsg{Path: path, Original: text, Execute: execute, Err: err}
+	})
+}
+
+// handleExternalEdit loads the edited file back into the query buffer.
+func (m *BrowserModel) handleExternalEdit(msg ExternalEditMsg) tea.Cmd {
+	defer os.Remove(msg.Path)
+	if msg.Err != nil {
+		m.statusMsg = "Editor failed: " + msg.Err.Error()
+		return nil
+	}
+	data, err := os.ReadFile(msg.Path)
+	if err != nil {
+		m.statusMsg = "Editor failed: " + err.Error()
+		return nil
+	}
+
+	text := string(data)
+	// Most editors end the file with a newline the buffer did not have
+	if !strings.HasSuffix(msg.Original, "\n") {
+		text = strings.TrimSuffix(strings.TrimSuffix(text, "\n"), "\r")
+	}
+
+	m.focusedPane = PaneQuery
+	m.queryMode = QueryModeNormal
+	m.updateFocus()
+	if text == m.query.Value() {
+		m.statusMsg = "No changes from editor"
+	} else {
+		m.setQueryText(text)
+		m.statusMsg = "Loaded query from editor"
+	}
+	if msg.Execute {
+		return tea.Batch(m.scheduleValidation(), m.executeQuery())
+	}
+	return m.scheduleValidation()
+}
@@ -0,0 +1,280 @@
+package screens
+
+import (
+	"bytes"
+	"encoding/binary"
+	"fmt"
+	"os"
+	"strings"
+	"unicode/utf8"
+
+	tea "charm.land/bubbletea/v2"
+	"charm.land/lipgloss/v2"
+
+	"github.com/jupiterozeye/tornado/internal/ui/styles"
+)
+
+// hexBytesPerLine is how many bytes each line of the hex dump shows.
+const hexBytesPerLine = 16
+
+// hexDumpLine formats the bytes of data at offset the way hexdump -C does:
+// the offset, sixteen bytes in hex split into two groups, and the printable
+// ASCII characters.
+func hexDumpLine(data []byte, offset int) string {
+	end := min(offset+hexBytesPerLine, len(data))
+	chunk := data[offset:end]
+
+	var b strings.Builder
+	fmt.Fprintf(&b, "%08x  ", offset)
+	for i := 0; i < hexBytesPerLine; i++ {
+		if i < len(chunk) {
+			fmt.Fprintf(&b, "%02x ", chunk[i])
+		} else {
+			b.WriteString("   ")
+		}
+		if i == hexBytesPerLine/2-1 {
+			b.WriteByte(' ')
+		}
+	}
+	b.WriteString(" |")
+	for _, c := range chunk {
+		if c >= 0x20 && c < 0x7f {
+			b.WriteByte(c)
+		} else {
+			b.WriteByte('.')
+		}
+	}
+	b.WriteByte('|')
+	return b.String()
+}
+
+// blobFormat is what a blob appears to contain.
+type blobFormat struct {
+	Name   string // Empty when unknown
+	Ext    string // File extension to save it with
+	Detail string // Extra facts read from the data
+}
+
+// detectBlobFormat recognises common formats from their magic bytes, and
+// UUIDs and protobuf messages from their shape.
+func detectBlobFormat(data []byte) blobFormat {
+	switch {
+	case bytes.HasPrefix(data, []byte("\x89PNG\r\n\x1a\n")):
+		f := blobFormat{Name: "PNG image", Ext: ".png"}
+		if len(data) >= 24 && string(data[12:16]) == "IHDR" {
+			f.Detail = fmt.Sprintf("%d×%d", binary.BigEndian.Uint32(data[16:20]), binary.BigEndian.Uint32(data[20:24]))
+		}
+		return f
+	case bytes.HasPrefix(data, []byte("\xff\xd8\xff")):
+		return blobFormat{Name: "JPEG image", Ext: ".jpg"}
+	case bytes.HasPrefix(data, []byte("GIF87a")) || bytes.HasPrefix(data, []byte("GIF89a")):
+		f := blobFormat{Name: "GIF image", Ext: ".gif"}
+		if len(data) >= 10 {
+			f.Detail = fmt.Sprintf("%d×%d", binary.LittleEndian.Uint16(data[6:8]), binary.LittleEndian.Uint16(data[8:10]))
+		}
+		return f
+	case bytes.HasPrefix(data, []byte("\x1f\x8b")):
+		f := blobFormat{Name: "gzip data", Ext: ".gz"}
+		if len(data) >= 18 {
+			// ISIZE: the uncompressed size modulo 2^32
+			f.Detail = fmt.Sprintf("%d bytes uncompressed", binary.LittleEndian.Uint32(data[len(data)-4:]))
+		}
+		return f
+	case bytes.HasPrefix(data, []byte("PK\x03\x04")):
+		return blobFormat{Name: "ZIP archive", Ext: ".zip"}
+	case bytes.HasPrefix(data, []byte("%PDF-")):
+		return blobFormat{Name: "PDF document", Ext: ".pdf"}
+	case bytes.HasPrefix(data, []byte("SQLite format 3\x00")):
+		return blobFormat{Name: "SQLite database", Ext: ".db"}
+	case len(data) == 16:
+		return blobFormat{Name: "UUID", Ext: ".bin", Detail: formatUUID(data)}
+	}
+	if fields, ok := protobufFields(data); ok {
+		return blobFormat{Name: "protobuf message", Ext: ".pb", Detail: fmt.Sprintf("%d fields", fields)}
+	}
+	if utf8.Valid(data) {
+		return blobFormat{Name: "UTF-8 text", Ext: ".txt"}
+	}
+	return blobFormat{Ext: ".bin"}
+}
+
+// formatUUID writes 16 bytes in the canonical 8-4-4-4-12 form.
+func formatUUID(b []byte) string {
+	return fmt.Sprintf("%x-%x-%x-%x-%x", b[0:4], b[4:6], b[6:8], b[8:10], b[10:16])
+}
+
+// protobufFields reports whether data parses completely as protobuf wire
+// format, and how many top-level fields it has. This is a heuristic: many
+// short byte strings happen to be valid.
+func protobufFields(data []byte) (int, bool) {
+	fields := 0
+	for i := 0; i < len(data); {
+		key, n := binary.Uvarint(data[i:])
+		if n <= 0 || key>>3 == 0 {
+			return 0, false
+		}
+		i += n
+		switch key & 7 {
+		case 0: // varint
+			_, n := binary.Uvarint(data[i:])
+			if n <= 0 {
+				return 0, false
+			}
+			i += n
+		case 1: // 64-bit
+			i += 8
+		case 2: // length-delimited
+			size, n := binary.Uvarint(data[i:])
+			if n <= 0 || size > uint64(len(data)) {
+				return 0, false
+			}
+			i += n + int(size)
+		case 5: // 32-bit
+			i += 4
+		default:
+			return 0, false
+		}
+		if i > len(data) {
+			return 0, false
+		}
+		fields++
+	}
+	return fields, fields > 0
+}
+
+// hexViewer is the preview dialog's state for a binary cell.
+type hexViewer struct {
+	data   []byte
+	format blobFormat
+	scroll int // First dump line shown
+	saving bool
+	input  string
+	// confirm is the input whose existing file enter now overwrites
+	confirm string
+}
+
+// openHexViewer shows a binary cell in the preview dialog as a hex dump.
+func (m *BrowserModel) openHexViewer(title string, data []byte) {
+	m.hexView = &hexViewer{data: data, format: detectBlobFormat(data)}
+	m.showPreview = true
+	m.previewTitle = title
+	m.statusMsg = ""
+}
+
+// hexViewHeight is how many dump lines fit in the dialog.
+func (m *BrowserModel) hexViewHeight() int {
+	return max(m.height-10, 3)
+}
+
+// handleHexViewKey handles keys while a binary cell is previewed.
+func (m *BrowserModel) handleHexViewKey(msg tea.KeyPressMsg) (tea.Model, tea.Cmd) {
+	v := m.hexView
+	if v.saving {
+		switch msg.String() {
+		case "esc":
+			v.saving = false
+		case "enter":
+			v.saving = !m.saveBlob(v.input, v.confirm == v.input)
+		case "backspace", "ctrl+h":
+			r := []rune(v.input)
+			if len(r) > 0 {
+				v.input = string(r[:len(r)-1])
+			}
+		default:
+			v.input += msg.Text
+		}
+		return m, nil
+	}
+
+	lines := (len(v.data) + hexBytesPerLine - 1) / hexBytesPerLine
+	height := m.hexViewHeight()
+	switch msg.String() {
+	case "esc", "q":
+		m.showPreview = false
+		m.hexView = nil
+		m.statusMsg = ""
+		return m, nil
+	case "j", "down":
+		v.scroll++
+	case "k", "up":
+		v.scroll--
+	case "ctrl+d", "pgdown":
+		v.scroll += max(height/2, 1)
+	case "ctrl+u", "pgup":
+		v.scroll -= max(height/2, 1)
+	case "g", "home":
+		v.scroll = 0
+	case "G", "end":
+		v.scroll = lines
+	case "w":
+		v.saving = true
+		v.input = "blob" + v.format.Ext
+	}
+	v.scroll = min(max(v.scroll, 0), max(lines-height, 0))
+	return m, nil
+}
+
+// saveBlob writes the previewed blob to the path typed as input. An existing
+// file is only replaced with force; otherwise it asks for enter again and
+// returns false to keep the prompt open.
+func (m *BrowserModel) saveBlob(input string, force bool) bool {
+	path := strings.TrimSpace(input)
+	if path == "" {
+		return true
+	}
+	path = expandPath(path)
+	if _, err := os.Stat(path); err == nil && !force {
+		m.hexView.confirm = input
+		m.statusMsg = path + " exists: enter again to overwrite"
+		return false
+	}
+	m.hexView.confirm = ""
+	if err := os.WriteFile(path, m.hexView.data, 0o644); err != nil {
+		m.statusMsg = "Save failed: " + err.Error()
+		return true
+	}
+	m.statusMsg = fmt.Sprintf("Wrote %d bytes to %s", len(m.hexView.data), path)
+	return true
+}
+
+// renderHexView draws the hex dump dialog.
+func (m *BrowserModel) renderHexView() (string, int) {
+	v := m.hexView
+	width := max(min(80, m.width-6), 20)
+	inner := width - 2
+	bg := lipgloss.NewStyle().Background(styles.BgDark)
+	muted := bg.Foreground(styles.TextMuted)
+	offsetStyle := bg.Foreground(styles.Primary)
+
+	kind := v.format.Name
+	if kind == "" {
+		kind = "binary data"
+	}
+	info := fmt.Sprintf("%d bytes, %s", len(v.data), kind)
+	if v.format.Detail != "" {
+		info += " " + v.format.Detail
+	}
+	body := []string{bg.Foreground(styles.Accent).Render(truncateToWidth(info, inner))}
+	if v.saving {
+		body = append(body, bg.Foreground(styles.Primary).Render(truncateToWidth("save to: "+v.input+"_", inner)))
+	} else {
+		body = append(body, "")
+	}
+
+	height := m.hexViewHeight()
+	for i := 0; i < height; i++ {
+		offset := (v.scroll + i) * hexBytesPerLine
+		if offset >= len(v.data) {
+			break
+		}
+		line := hexDumpLine(v.data, offset)
+		hex, ascii, _ := strings.Cut(line[10:], "|")
+		body = append(body, truncateToWidth(offsetStyle.Render(line[:10])+bg.Render(hex)+muted.Render("|"+ascii), inner))
+	}
+
+	footer := "j/k Scroll  g/G Start/End  w Save  esc Close"
+	if v.saving {
+		footer = "enter Save  esc Cancel"
+	}
+	return renderDialogBox(m.previewTitle, body, footer, width), width
+}
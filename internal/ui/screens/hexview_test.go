@@ -0,0 +1,67 @@
+package screens
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	tea "charm.land/bubbletea/v2"
+
+	"github.com/jupiterozeye/tornado/internal/models"
+)
+
+func TestHexDumpLine(t *testing.T) {
+	data := []byte("0123456789abcdef\x00\x01hi")
+	want := []string{
+		"00000000  30 31 32 33 34 35 36 37  38 39 61 62 63 64 65 66  |0123456789abcdef|",
+		"00000010  00 01 68 69                                       |..hi|",
+	}
+	for i, w := range want {
+		if got := hexDumpLine(data, i*hexBytesPerLine); got != w {
+			t.Errorf("line %d =\n%q\nwant\n%q", i, got, w)
+		}
+	}
+}
+
+func TestDetectBlobFormat(t *testing.T) {
+	png := []byte("\x89PNG\r\n\x1a\n\x00\x00\x00\x0dIHDR\x00\x00\x01\x00\x00\x00\x00\x80")
+	gzip := append([]byte("\x1f\x8b\x08\x00\x00\x00\x00\x00\x00\x03"), 0, 0, 0, 0, 0, 0, 0x2a, 0, 0, 0)
+	uuid := []byte{0x12, 0x3e, 0x45, 0x67, 0xe8, 0x9b, 0x12, 0xd3, 0xa4, 0x56, 0x42, 0x66, 0x14, 0x17, 0x40, 0x00}
+	// Field 1 varint 150, field 2 string "ab"
+	proto := []byte{0x08, 0x96, 0x01, 0x12, 0x02, 'a', 'b'}
+
+	tests := []struct {
+		data []byte
+		want blobFormat
+	}{
+		{png, blobFormat{Name: "PNG image", Ext: ".png", Detail: "256×128"}},
+		{gzip, blobFormat{Name: "gzip data", Ext: ".gz", Detail: "42 bytes uncompressed"}},
+		{uuid, blobFormat{Name: "UUID", Ext: ".bin", Detail: "123e4567-e89b-12d3-a456-426614174000"}},
+		{proto, blobFormat{Name: "protobuf message", Ext: ".pb", Detail: "2 fields"}},
+		{[]byte{0x00, 0xff, 0xfe}, blobFormat{Ext: ".bin"}},
+	}
+	for _, tt := range tests {
+		if got := detectBlobFormat(tt.data); got != tt.want {
+			t.Errorf("detectBlobFormat(%x) = %+v, want %+v", tt.data, got, tt.want)
+		}
+	}
+}
+
+func TestSaveBlob_asksBeforeOverwriting(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "blob.bin")
+	if err := os.WriteFile(path, []byte("keep"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	m := NewBrowserModel(nil, models.ConnectionConfig{})
+	m.openHexViewer("blob", []byte{1, 2})
+	m.hexView.saving, m.hexView.input = true, path
+
+	m.handleHexViewKey(tea.KeyPressMsg{Code: tea.KeyEnter})
+	if data, _ := os.ReadFile(path); string(data) != "keep" || !m.hexView.saving {
+		t.Fatalf("first enter wrote %q, prompt open %v", data, m.hexView.saving)
+	}
+	m.handleHexViewKey(tea.KeyPressMsg{Code: tea.KeyEnter})
+	if data, _ := os.ReadFile(path); string(data) != "\x01\x02" || m.hexView.saving {
+		t.Errorf("second enter wrote %q, prompt open %v", data, m.hexView.saving)
+	}
+}
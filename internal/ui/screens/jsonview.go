@@ -0,0 +1,671 @@
+package screens
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"strings"
+
+	tea "charm.land/bubbletea/v2"
+	"charm.land/lipgloss/v2"
+
+	"github.com/jupiterozeye/tornado/internal/ui/styles"
+)
+
+// jsonKind is the type of a JSON value.
+type jsonKind int
+
+const (
+	jsonObject jsonKind = iota
+	jsonArray
+	jsonString
+	jsonNumber
+	jsonBool
+	jsonNull
+)
+
+// jsonNode is one value of a parsed JSON document. Object members keep the
+// order they had in the source.
+type jsonNode struct {
+	Key       string // Member name, for object members
+	Index     int    // Position, for array elements; -1 otherwise
+	Kind      jsonKind
+	Scalar    string // JSON text of a string, number, bool or null
+	Children  []*jsonNode
+	Parent    *jsonNode
+	Collapsed bool
+}
+
+func (n *jsonNode) isContainer() bool {
+	return n.Kind == jsonObject || n.Kind == jsonArray
+}
+
+// jsonCellText returns a cell's text when it holds a JSON document: any
+// valid JSON in a json/jsonb column, or an object or array in a text column.
+func jsonCellText(val any, colType string) (string, bool) {
+	var s string
+	switch v := val.(type) {
+	case string:
+		s = v
+	case []byte:
+		s = string(v)
+	default:
+		return "", false
+	}
+	s = strings.TrimSpace(s)
+	if s == "" {
+		return "", false
+	}
+	if !strings.Contains(strings.ToUpper(colType), "JSON") && s[0] != '{' && s[0] != '[' {
+		return "", false
+	}
+	if !json.Valid([]byte(s)) {
+		return "", false
+	}
+	return s, true
+}
+
+// parseJSONTree parses a JSON document into a tree.
+func parseJSONTree(text string) (*jsonNode, error) {
+	dec := json.NewDecoder(strings.NewReader(text))
+	dec.UseNumber()
+	root, err := parseJSONValue(dec)
+	if err != nil {
+		return nil, err
+	}
+	if _, err := dec.Token(); err == nil {
+		return nil, fmt.Errorf("unexpected data after the JSON value")
+	}
+	return root, nil
+}
+
+func parseJSONValue(dec *json.Decoder) (*jsonNode, error) {
+	tok, err := dec.Token()
+	if err != nil {
+		return nil, err
+	}
+	n := &jsonNode{Index: -1}
+	switch t := tok.(type) {
+	case json.Delim:
+		n.Kind = jsonArray
+		if t == '{' {
+			n.Kind = jsonObject
+		}
+		for i := 0; dec.More(); i++ {
+			key := ""
+			if n.Kind == jsonObject {
+				keyTok, err := dec.Token()
+				if err != nil {
+					return nil, err
+				}
+				key, _ = keyTok.(string)
+			}
+			child, err := parseJSONValue(dec)
+			if err != nil {
+				return nil, err
+			}
+			child.Parent = n
+			if n.Kind == jsonObject {
+				child.Key = key
+			} else {
+				child.Index = i
+			}
+			n.Children = append(n.Children, child)
+		}
+		if _, err := dec.Token(); err != nil { // Closing delimiter
+			return nil, err
+		}
+	case string:
+		n.Kind, n.Scalar = jsonString, quoteJSON(t)
+	case json.Number:
+		n.Kind, n.Scalar = jsonNumber, t.String()
+	case bool:
+		n.Kind, n.Scalar = jsonBool, strconv.FormatBool(t)
+	case nil:
+		n.Kind, n.Scalar = jsonNull, "null"
+	}
+	return n, nil
+}
+
+// quoteJSON returns s as a JSON string literal, leaving <, > and & alone.
+func quoteJSON(s string) string {
+	var b bytes.Buffer
+	enc := json.NewEncoder(&b)
+	enc.SetEscapeHTML(false)
+	_ = enc.Encode(s)
+	return strings.TrimSuffix(b.String(), "\n")
+}
+
+// encode returns the node as JSON, indented when pretty is set.
+func (n *jsonNode) encode(pretty bool) string {
+	var b strings.Builder
+	n.writeCompact(&b)
+	if !pretty || !n.isContainer() {
+		return b.String()
+	}
+	var out bytes.Buffer
+	if err := json.Indent(&out, []byte(b.String()), "", "  "); err != nil {
+		return b.String()
+	}
+	return out.String()
+}
+
+func (n *jsonNode) writeCompact(b *strings.Builder) {
+	switch n.Kind {
+	case jsonObject, jsonArray:
+		open, close := "[", "]"
+		if n.Kind == jsonObject {
+			open, close = "{", "}"
+		}
+		b.WriteString(open)
+		for i, child := range n.Children {
+			if i > 0 {
+				b.WriteByte(',')
+			}
+			if n.Kind == jsonObject {
+				b.WriteString(quoteJSON(child.Key))
+				b.WriteByte(':')
+			}
+			child.writeCompact(b)
+		}
+		b.WriteString(close)
+	default:
+		b.WriteString(n.Scalar)
+	}
+}
+
+// path returns the jq-style path of the node from the document root.
+func (n *jsonNode) path() string {
+	var parts []string
+	for c := n; c.Parent != nil; c = c.Parent {
+		switch {
+		case c.Index >= 0:
+			parts = append(parts, fmt.Sprintf("[%d]", c.Index))
+		case isJSONIdent(c.Key):
+			parts = append(parts, "."+c.Key)
+		default:
+			parts = append(parts, "["+quoteJSON(c.Key)+"]")
+		}
+	}
+	if len(parts) == 0 {
+		return "."
+	}
+	var b strings.Builder
+	for i := len(parts) - 1; i >= 0; i-- {
+		b.WriteString(parts[i])
+	}
+	return b.String()
+}
+
+func isJSONIdent(s string) bool {
+	if s == "" {
+		return false
+	}
+	for i := 0; i < len(s); i++ {
+		if !isJSONIdentChar(s[i]) || (i == 0 && s[i] >= '0' && s[i] <= '9') {
+			return false
+		}
+	}
+	return true
+}
+
+func isJSONIdentChar(ch byte) bool {
+	return ch == '_' || ch >= 'a' && ch <= 'z' || ch >= 'A' && ch <= 'Z' || ch >= '0' && ch <= '9'
+}
+
+// evalJSONPath selects the values at a jq-style path: .key, ."key",
+// ["key"], [n] (negative counts from the end) and [] for every element or
+// member. Steps that do not apply to a value skip it rather than failing.
+func evalJSONPath(root *jsonNode, path string) ([]*jsonNode, error) {
+	path = strings.TrimSpace(path)
+	nodes := []*jsonNode{root}
+	for i := 0; i < len(path); {
+		switch path[i] {
+		case '.':
+			i++
+			if i >= len(path) || path[i] == '[' {
+				continue
+			}
+			if path[i] == '"' {
+				key, n, err := readJSONQuoted(path[i:])
+				if err != nil {
+					return nil, err
+				}
+				i += n
+				nodes = jsonMembers(nodes, key)
+				continue
+			}
+			start := i
+			for i < len(path) && isJSONIdentChar(path[i]) {
+				i++
+			}
+			if start == i {
+				return nil, fmt.Errorf("expected a key at %d", i)
+			}
+			nodes = jsonMembers(nodes, path[start:i])
+		case '[':
+			end := strings.IndexByte(path[i:], ']')
+			if end < 0 {
+				return nil, fmt.Errorf("unclosed [ at %d", i)
+			}
+			inner := strings.TrimSpace(path[i+1 : i+end])
+			i += end + 1
+			switch {
+			case inner == "":
+				var all []*jsonNode
+				for _, n := range nodes {
+					all = append(all, n.Children...)
+				}
+				nodes = all
+			case inner[0] == '"':
+				key, _, err := readJSONQuoted(inner)
+				if err != nil {
+					return nil, err
+				}
+				nodes = jsonMembers(nodes, key)
+			default:
+				idx, err := strconv.Atoi(inner)
+				if err != nil {
+					return nil, fmt.Errorf("bad index %q", inner)
+				}
+				nodes = jsonElements(nodes, idx)
+			}
+		case ' ':
+			i++
+		default:
+			return nil, fmt.Errorf("unexpected %q at %d", path[i], i)
+		}
+	}
+	return nodes, nil
+}
+
+// readJSONQuoted reads the string literal at the start of s and returns it
+// with the number of bytes it took.
+func readJSONQuoted(s string) (string, int, error) {
+	for i := 1; i < len(s); i++ {
+		switch s[i] {
+		case '\\':
+			i++
+		case '"':
+			var key string
+			if err := json.Unmarshal([]byte(s[:i+1]), &key); err != nil {
+				return "", 0, fmt.Errorf("bad key %s", s[:i+1])
+			}
+			return key, i + 1, nil
+		}
+	}
+	return "", 0, fmt.Errorf("unclosed string %s", s)
+}
+
+func jsonMembers(nodes []*jsonNode, key string) []*jsonNode {
+	var out []*jsonNode
+	for _, n := range nodes {
+		if n.Kind != jsonObject {
+			continue
+		}
+		for _, child := range n.Children {
+			if child.Key == key {
+				out = append(out, child)
+			}
+		}
+	}
+	return out
+}
+
+func jsonElements(nodes []*jsonNode, idx int) []*jsonNode {
+	var out []*jsonNode
+	for _, n := range nodes {
+		if n.Kind != jsonArray {
+			continue
+		}
+		i := idx
+		if i < 0 {
+			i += len(n.Children)
+		}
+		if i >= 0 && i < len(n.Children) {
+			out = append(out, n.Children[i])
+		}
+	}
+	return out
+}
+
+// jsonLine is one line of the tree as displayed: a value, or the closing
+// bracket of an expanded object or array.
+type jsonLine struct {
+	Node    *jsonNode
+	Depth   int
+	Closing bool
+	Last    bool // No comma follows
+	Member  bool // Show the node's key
+}
+
+// jsonLines flattens the displayed tree under root, skipping the children
+// of collapsed nodes.
+func jsonLines(root *jsonNode) []jsonLine {
+	var lines []jsonLine
+	var walk func(n *jsonNode, depth int, member, last bool)
+	walk = func(n *jsonNode, depth int, member, last bool) {
+		lines = append(lines, jsonLine{Node: n, Depth: depth, Last: last, Member: member})
+		if !n.isContainer() || n.Collapsed || len(n.Children) == 0 {
+			return
+		}
+		for i, child := range n.Children {
+			walk(child, depth+1, n.Kind == jsonObject, i == len(n.Children)-1)
+		}
+		lines = append(lines, jsonLine{Node: n, Depth: depth, Closing: true, Last: last})
+	}
+	walk(root, 0, false, true)
+	return lines
+}
+
+// renderJSONLine draws a line with syntax colouring. The fold marker shows
+// whether an object or array is expanded.
+func renderJSONLine(l jsonLine, bg lipgloss.Style) string {
+	key := bg.Foreground(styles.Primary)
+	str := bg.Foreground(styles.Success)
+	num := bg.Foreground(styles.Warning)
+	lit := bg.Foreground(styles.Accent)
+	punct := bg.Foreground(styles.TextMuted)
+
+	n := l.Node
+	marker := "  "
+	if n.isContainer() && len(n.Children) > 0 && !l.Closing {
+		marker = "▾ "
+		if n.Collapsed {
+			marker = "▸ "
+		}
+	}
+	out := punct.Render(marker + strings.Repeat("  ", l.Depth))
+
+	comma := ""
+	if !l.Last {
+		comma = punct.Render(",")
+	}
+	open, close := "[", "]"
+	if n.Kind == jsonObject {
+		open, close = "{", "}"
+	}
+	if l.Closing {
+		return out + punct.Render(close) + comma
+	}
+	if l.Member {
+		out += key.Render(quoteJSON(n.Key)) + punct.Render(": ")
+	}
+
+	switch n.Kind {
+	case jsonObject, jsonArray:
+		switch {
+		case len(n.Children) == 0:
+			out += punct.Render(open + close)
+		case n.Collapsed:
+			what := "item"
+			if n.Kind == jsonObject {
+				what = "key"
+			}
+			if len(n.Children) != 1 {
+				what += "s"
+			}
+			out += punct.Render(open+"…"+close) + bg.Foreground(styles.TextMuted).Render(fmt.Sprintf(" %d %s", len(n.Children), what))
+		default:
+			return out + punct.Render(open)
+		}
+	case jsonString:
+		out += str.Render(n.Scalar)
+	case jsonNumber:
+		out += num.Render(n.Scalar)
+	default:
+		out += lit.Render(n.Scalar)
+	}
+	return out + comma
+}
+
+// setCollapsed folds or unfolds n and everything below it.
+func setCollapsed(n *jsonNode, collapsed bool) {
+	if !n.isContainer() {
+		return
+	}
+	n.Collapsed = collapsed
+	for _, child := range n.Children {
+		setCollapsed(child, collapsed)
+	}
+}
+
+// jsonViewer is the preview dialog's state for a JSON cell.
+type jsonViewer struct {
+	root      *jsonNode // Whole document
+	view      *jsonNode // root, or what the path filter selected
+	cursor    int
+	scroll    int
+	path      string // Applied path filter
+	filtering bool   // Typing a path
+	input     string
+	err       string
+}
+
+func newJSONViewer(text string) (*jsonViewer, error) {
+	root, err := parseJSONTree(text)
+	if err != nil {
+		return nil, err
+	}
+	// Open large documents with everything below the top level folded
+	if strings.Count(text, "\n") > 200 || len(text) > 8000 {
+		for _, child := range root.Children {
+			setCollapsed(child, true)
+		}
+	}
+	return &jsonViewer{root: root, view: root}, nil
+}
+
+// current returns the node under the cursor.
+func (v *jsonViewer) current() *jsonNode {
+	lines := jsonLines(v.view)
+	if v.cursor < 0 || v.cursor >= len(lines) {
+		return nil
+	}
+	return lines[v.cursor].Node
+}
+
+// applyPath filters the view to the values at path. Several values are
+// shown together as an array.
+func (v *jsonViewer) applyPath(path string) {
+	path = strings.TrimSpace(path)
+	v.err = ""
+	if path == "" || path == "." {
+		v.view, v.path = v.root, ""
+		v.cursor, v.scroll = 0, 0
+		return
+	}
+	nodes, err := evalJSONPath(v.root, path)
+	switch {
+	case err != nil:
+		v.err = err.Error()
+		return
+	case len(nodes) == 0:
+		v.err = "no match for " + path
+		return
+	case len(nodes) == 1:
+		v.view = nodes[0]
+	default:
+		// The matches keep their real parents so paths stay correct
+		v.view = &jsonNode{Kind: jsonArray, Index: -1, Children: nodes}
+	}
+	v.path = path
+	v.cursor, v.scroll = 0, 0
+}
+
+// moveToParent puts the cursor on the line of the current node's parent.
+func (v *jsonViewer) moveToParent() {
+	n := v.current()
+	if n == nil || n == v.view {
+		return
+	}
+	lines := jsonLines(v.view)
+	for i := v.cursor - 1; i >= 0; i-- {
+		if !lines[i].Closing && lines[i].Depth < lines[v.cursor].Depth {
+			v.cursor = i
+			return
+		}
+	}
+}
+
+// openJSONViewer shows a JSON cell in the preview dialog as a tree.
+func (m *BrowserModel) openJSONViewer(title, text string) bool {
+	v, err := newJSONViewer(text)
+	if err != nil {
+		return false
+	}
+	m.jsonView = v
+	m.showPreview = true
+	m.previewTitle = title
+	m.previewContent = text
+	m.statusMsg = ""
+	return true
+}
+
+// handleJSONViewKey handles keys while a JSON cell is previewed.
+func (m *BrowserModel) handleJSONViewKey(msg tea.KeyPressMsg) (tea.Model, tea.Cmd) {
+	v := m.jsonView
+	if v.filtering {
+		switch msg.String() {
+		case "esc":
+			v.filtering = false
+		case "enter":
+			v.filtering = false
+			v.applyPath(v.input)
+		case "backspace", "ctrl+h":
+			r := []rune(v.input)
+			if len(r) > 0 {
+				v.input = string(r[:len(r)-1])
+			}
+		default:
+			v.input += msg.Text
+		}
+		return m, nil
+	}
+
+	lines := jsonLines(v.view)
+	page := max(m.jsonViewHeight()/2, 1)
+	switch msg.String() {
+	case "esc", "q":
+		if v.path != "" {
+			v.applyPath("")
+			return m, nil
+		}
+		m.showPreview = false
+		m.jsonView = nil
+		m.statusMsg = ""
+	case "j", "down":
+		v.cursor++
+	case "k", "up":
+		v.cursor--
+	case "g", "home":
+		v.cursor = 0
+	case "G", "end":
+		v.cursor = len(lines) - 1
+	case "ctrl+d", "pgdown":
+		v.cursor += page
+	case "ctrl+u", "pgup":
+		v.cursor -= page
+	case "h", "left":
+		if n := v.current(); n != nil && n.isContainer() && !n.Collapsed && len(n.Children) > 0 && !lines[v.cursor].Closing {
+			n.Collapsed = true
+		} else {
+			v.moveToParent()
+		}
+	case "l", "right":
+		if n := v.current(); n != nil && n.isContainer() {
+			n.Collapsed = false
+		}
+	case "enter", "space", "tab":
+		if n := v.current(); n != nil && n.isContainer() && len(n.Children) > 0 {
+			n.Collapsed = !n.Collapsed
+			if lines[v.cursor].Closing {
+				// Keep the cursor on the node once its closing line is gone
+				for i, l := range lines {
+					if l.Node == n {
+						v.cursor = i
+						break
+					}
+				}
+			}
+		}
+	case "E":
+		setCollapsed(v.view, false)
+	case "C":
+		setCollapsed(v.view, true)
+		v.view.Collapsed = false
+		v.cursor = 0
+	case "/", ".":
+		v.filtering = true
+		v.input = v.path
+		if v.input == "" {
+			v.input = "."
+		}
+	case "y":
+		if n := v.current(); n != nil && m.writeClipboard(n.encode(true)) {
+			m.statusMsg = "Copied " + n.path()
+		}
+	case "Y":
+		if n := v.current(); n != nil && m.writeClipboard(n.path()) {
+			m.statusMsg = "Copied path " + n.path()
+		}
+	}
+
+	n := len(jsonLines(v.view))
+	v.cursor = min(max(v.cursor, 0), max(n-1, 0))
+	return m, nil
+}
+
+// jsonViewHeight is how many tree lines fit in the dialog.
+func (m *BrowserModel) jsonViewHeight() int {
+	return max(m.height-8, 3)
+}
+
+// renderJSONView draws the JSON tree dialog.
+func (m *BrowserModel) renderJSONView() (string, int) {
+	v := m.jsonView
+	width := max(min(100, m.width-6), 20)
+	inner := width - 2
+	height := m.jsonViewHeight()
+	bg := lipgloss.NewStyle().Background(styles.BgDark)
+
+	var body []string
+	switch {
+	case v.filtering:
+		body = append(body, bg.Foreground(styles.Primary).Render("path: "+v.input+"_"))
+		height--
+	case v.err != "":
+		body = append(body, bg.Foreground(styles.Error).Render(v.err))
+		height--
+	case v.path != "":
+		body = append(body, bg.Foreground(styles.TextMuted).Render("path: "+v.path))
+		height--
+	}
+
+	lines := jsonLines(v.view)
+	if v.cursor < v.scroll {
+		v.scroll = v.cursor
+	}
+	if v.cursor >= v.scroll+height {
+		v.scroll = v.cursor - height + 1
+	}
+	for i := v.scroll; i < len(lines) && i < v.scroll+height; i++ {
+		style := bg
+		if i == v.cursor {
+			style = lipgloss.NewStyle().Background(styles.BgLight)
+		}
+		line := truncateToWidth(renderJSONLine(lines[i], style), inner)
+		if i == v.cursor {
+			line += style.Render(strings.Repeat(" ", max(inner-lipgloss.Width(line), 0)))
+		}
+		body = append(body, line)
+	}
+
+	title := m.previewTitle
+	if n := v.current(); n != nil {
+		title += " " + n.path()
+	}
+	footer := "j/k Move  h/l Fold  E/C All  / Path  y Copy  Y Path  esc Close"
+	return renderDialogBox(title, body, footer, width), width
+}
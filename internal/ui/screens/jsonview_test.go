@@ -0,0 +1,84 @@
+package screens
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+
+	"charm.land/lipgloss/v2"
+	xansi "github.com/charmbracelet/x/ansi"
+)
+
+func TestJSONTree(t *testing.T) {
+	doc := `{"z": 1, "items": [{"id": 1, "tags": ["a"]}, {"id": 2, "tags": []}], "odd key": "<&>", "n": null}`
+	root, err := parseJSONTree(doc)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// Members keep their order and HTML characters are not escaped
+	want := `{"z":1,"items":[{"id":1,"tags":["a"]},{"id":2,"tags":[]}],"odd key":"<&>","n":null}`
+	if got := root.encode(false); got != want {
+		t.Errorf("encode = %s, want %s", got, want)
+	}
+
+	paths := []struct {
+		path string
+		want []string
+	}{
+		{".items[].id", []string{"1", "2"}},
+		{".items[-1].tags", []string{"[]"}},
+		{`.["odd key"]`, []string{`"<&>"`}},
+		{`."odd key"`, []string{`"<&>"`}},
+		{".z.missing", nil},
+	}
+	for _, tt := range paths {
+		nodes, err := evalJSONPath(root, tt.path)
+		if err != nil {
+			t.Errorf("evalJSONPath(%q): %v", tt.path, err)
+			continue
+		}
+		var got []string
+		for _, n := range nodes {
+			got = append(got, n.encode(false))
+		}
+		if !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("evalJSONPath(%q) = %q, want %q", tt.path, got, tt.want)
+		}
+	}
+	if _, err := evalJSONPath(root, ".items[x]"); err == nil {
+		t.Error("expected an error for a bad index")
+	}
+
+	nodes, _ := evalJSONPath(root, ".items[1].tags")
+	if got := nodes[0].path(); got != ".items[1].tags" {
+		t.Errorf("path = %q", got)
+	}
+	nodes, _ = evalJSONPath(root, `.["odd key"]`)
+	if got := nodes[0].path(); got != `["odd key"]` {
+		t.Errorf("path = %q", got)
+	}
+
+	root.Children[1].Children[0].Collapsed = true
+	var lines []string
+	for _, l := range jsonLines(root) {
+		lines = append(lines, strings.TrimRight(xansi.Strip(renderJSONLine(l, lipgloss.NewStyle())), " "))
+	}
+	wantLines := []string{
+		"▾ {",
+		`    "z": 1,`,
+		`▾   "items": [`,
+		`▸     {…} 2 keys,`,
+		`▾     {`,
+		`        "id": 2,`,
+		`        "tags": []`,
+		`      }`,
+		`    ],`,
+		`    "odd key": "<&>",`,
+		`    "n": null`,
+		"  }",
+	}
+	if !reflect.DeepEqual(lines, wantLines) {
+		t.Errorf("lines =\n%s\nwant\n%s", strings.Join(lines, "\n"), strings.Join(wantLines, "\n"))
+	}
+}
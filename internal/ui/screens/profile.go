@@ -0,0 +1,438 @@
+package screens
+
+import (
+	"cmp"
+	"fmt"
+	"math"
+	"slices"
+	"strconv"
+	"strings"
+
+	tea "charm.land/bubbletea/v2"
+	"charm.land/lipgloss/v2"
+
+	"github.com/jupiterozeye/tornado/internal/db"
+	"github.com/jupiterozeye/tornado/internal/models"
+	"github.com/jupiterozeye/tornado/internal/telemetry"
+	"github.com/jupiterozeye/tornado/internal/ui/styles"
+)
+
+const (
+	profileTopValues = 10
+	profileBuckets   = 10
+	profileBarWidth  = 20
+	// profileMaxValues caps the numbers fetched for the median and histogram
+	// of a table; beyond it they describe a sample
+	profileMaxValues = 100000
+)
+
+// valueCount is one of a column's most frequent values.
+type valueCount struct {
+	Value string
+	Count int
+}
+
+// columnProfile holds statistics for one column of a result or table.
+type columnProfile struct {
+	Column   string
+	Source   string // What the statistics cover
+	Count    int    // Rows, including NULLs
+	Nulls    int
+	Distinct int
+	Min, Max string
+	Numeric  bool
+	Mean     float64
+	Median   float64
+	Sampled  int // Numbers the median and histogram were taken from, when fewer than all
+	Top      []valueCount
+	Bounds   []float64 // Histogram bucket boundaries, one more than Buckets
+	Buckets  []int
+}
+
+// ColumnProfileMsg carries a column profile computed in the background.
+type ColumnProfileMsg struct {
+	Profile columnProfile
+	Err     error
+}
+
+// profileViewer is the state of the column profile dialog.
+type profileViewer struct {
+	column  string
+	loading bool
+	profile columnProfile
+	err     string
+	scroll  int
+}
+
+// profileColumn opens the profile of the highlighted column. Tables opened
+// from the explorer are profiled with SQL so the whole table is covered;
+// other results are profiled from the rows already fetched.
+func (m *BrowserModel) profileColumn() tea.Cmd {
+	active := m.activeResultSet()
+	if active == nil || len(m.visibleColumns()) == 0 {
+		m.statusMsg = "No column to profile"
+		return nil
+	}
+	col := m.resultsCursorCol
+	name := active.Columns[col]
+	m.profileView = &profileViewer{column: name}
+	m.showPreview = true
+	m.previewTitle = "Profile: " + name
+	m.statusMsg = ""
+
+	if m.tableQuery == nil || m.db == nil {
+		p := profileResult(active, col, m.cellFormat())
+		p.Source = fmt.Sprintf("%d rows in the result", len(active.Rows))
+		if m.resultsFilter != "" {
+			p.Source = fmt.Sprintf("%d rows matching the filter", len(active.Rows))
+		}
+		m.profileView.profile = p
+		return nil
+	}
+
+	m.profileView.loading = true
+	database, t, colType := m.db, *m.tableQuery, columnType(active, col)
+	return func() tea.Msg {
+		p, err := profileTable(database, &t, name, colType)
+		return ColumnProfileMsg{Profile: p, Err: err}
+	}
+}
+
+// applyColumnProfile shows a profile computed in the background, unless the
+// dialog was closed or moved on to another column meanwhile.
+func (m *BrowserModel) applyColumnProfile(msg ColumnProfileMsg) {
+	v := m.profileView
+	if v == nil || !v.loading || v.column != msg.Profile.Column {
+		return
+	}
+	v.loading = false
+	v.profile = msg.Profile
+	if msg.Err != nil {
+		v.err = msg.Err.Error()
+	}
+}
+
+// profileResult computes a column profile from the rows of a result.
+func profileResult(r *models.QueryResult, col int, format cellFormat) columnProfile {
+	p := columnProfile{Column: r.Columns[col], Count: len(r.Rows), Numeric: true}
+	colType := columnType(r, col)
+	counts := map[string]int{}
+	var numbers []float64
+	var minText, maxText string
+	var minNum, maxNum float64
+	nonNull := 0
+	for _, row := range r.Rows {
+		if col >= len(row) || row[col] == nil {
+			p.Nulls++
+			continue
+		}
+		nonNull++
+		first := nonNull == 1
+		text, _ := formatCell(row[col], colType, format)
+		counts[text]++
+
+		n, ok := numericValue(row[col])
+		if !ok && isNumericType(colType) {
+			n, ok = parseNumber(text)
+		}
+		switch {
+		case !finite(n):
+			// ±Inf and NaN, stored or written out, have no place in the
+			// statistics; they count as values but not as numbers
+		case !ok:
+			p.Numeric = false
+		default:
+			numbers = append(numbers, n)
+			if len(numbers) == 1 || n < minNum {
+				minNum = n
+			}
+			if len(numbers) == 1 || n > maxNum {
+				maxNum = n
+			}
+		}
+		if first || text < minText {
+			minText = text
+		}
+		if first || text > maxText {
+			maxText = text
+		}
+	}
+	p.Distinct = len(counts)
+	p.Top = topValues(counts)
+	if nonNull == 0 {
+		p.Numeric = false
+		return p
+	}
+	if !p.Numeric || len(numbers) == 0 {
+		p.Numeric = false
+		p.Min, p.Max = minText, maxText
+		return p
+	}
+	p.Min, p.Max = formatStat(minNum), formatStat(maxNum)
+	p.Mean = mean(numbers)
+	p.Bounds, p.Buckets = telemetry.Histogram(numbers, profileBuckets)
+	p.Median = telemetry.Percentile(numbers, 50)
+	return p
+}
+
+// profileTable computes a column profile with SQL over the table's rows that
+// match its filters.
+func profileTable(database db.Database, t *tableQuery, column, colType string) (columnProfile, error) {
+	p := columnProfile{Column: column, Source: "whole table " + t.Table}
+	if len(t.Filters) > 0 {
+		p.Source = "rows of " + t.Table + " matching the filter"
+	}
+	col, from := quoteIdent(column), " FROM "+quoteIdent(t.Table)
+	notNull := t.where(col + " IS NOT NULL")
+
+	res, err := database.Query("SELECT COUNT(*), COUNT(" + col + "), COUNT(DISTINCT " + col + "), MIN(" + col + "), MAX(" + col + ")" + from + t.where())
+	if err != nil {
+		return p, err
+	}
+	if len(res.Rows) == 0 || len(res.Rows[0]) < 5 {
+		return p, fmt.Errorf("no statistics returned")
+	}
+	stats := res.Rows[0]
+	count, _ := numericValue(stats[0])
+	nonNull, _ := numericValue(stats[1])
+	distinct, _ := numericValue(stats[2])
+	p.Count, p.Nulls, p.Distinct = int(count), int(count-nonNull), int(distinct)
+	format := defaultCellFormat
+	p.Min, _ = formatCell(stats[3], colType, format)
+	p.Max, _ = formatCell(stats[4], colType, format)
+	if nonNull == 0 {
+		p.Min, p.Max = "", ""
+		return p, nil
+	}
+
+	res, err = database.Query(fmt.Sprintf("SELECT %s, COUNT(*)%s%s GROUP BY %s ORDER BY COUNT(*) DESC, %s LIMIT %d",
+		col, from, notNull, col, col, profileTopValues))
+	if err != nil {
+		return p, err
+	}
+	for _, row := range res.Rows {
+		text, _ := formatCell(row[0], colType, format)
+		n, _ := numericValue(row[1])
+		p.Top = append(p.Top, valueCount{Value: text, Count: int(n)})
+	}
+
+	_, minNumeric := numericValue(stats[3])
+	_, maxNumeric := numericValue(stats[4])
+	if !isNumericType(colType) && !(minNumeric && maxNumeric) {
+		return p, nil
+	}
+	res, err = database.Query(fmt.Sprintf("SELECT %s%s%s LIMIT %d", col, from, notNull, profileMaxValues))
+	if err != nil {
+		return p, err
+	}
+	numbers := make([]float64, 0, len(res.Rows))
+	ok := false
+	for _, row := range res.Rows {
+		var n float64
+		if n, ok = numericValue(row[0]); !ok {
+			text, _ := formatCell(row[0], colType, format)
+			if n, ok = parseNumber(text); !ok && finite(n) {
+				return p, nil // Not a numeric column after all
+			}
+		}
+		if finite(n) {
+			numbers = append(numbers, n)
+		}
+	}
+	if len(numbers) == 0 {
+		return p, nil
+	}
+	p.Numeric = true
+	res, err = database.Query("SELECT AVG(" + col + ")" + from + notNull)
+	if err != nil {
+		return p, err
+	}
+	if len(res.Rows) > 0 && len(res.Rows[0]) > 0 {
+		// Some drivers return exact averages as text
+		if p.Mean, ok = numericValue(res.Rows[0][0]); !ok {
+			p.Mean, _ = parseNumber(cellText(res.Rows[0][0], ""))
+		}
+	}
+	if !finite(p.Mean) {
+		// The average of a column holding ±Inf or NaN, or one that
+		// overflowed: use the numbers fetched
+		p.Mean = mean(numbers)
+	}
+	if len(numbers) < int(nonNull) {
+		p.Sampled = len(numbers)
+	}
+	p.Bounds, p.Buckets = telemetry.Histogram(numbers, profileBuckets)
+	p.Median = telemetry.Percentile(numbers, 50)
+	return p, nil
+}
+
+// topValues returns the most frequent values, ties in value order.
+func topValues(counts map[string]int) []valueCount {
+	top := make([]valueCount, 0, len(counts))
+	for v, n := range counts {
+		top = append(top, valueCount{Value: v, Count: n})
+	}
+	slices.SortFunc(top, func(a, b valueCount) int {
+		if c := cmp.Compare(b.Count, a.Count); c != 0 {
+			return c
+		}
+		return cmp.Compare(a.Value, b.Value)
+	})
+	return top[:min(len(top), profileTopValues)]
+}
+
+// parseNumber reads s as a finite number. Text reading as ±Inf or NaN is
+// returned as such, but not as a number.
+func parseNumber(s string) (float64, bool) {
+	n, err := strconv.ParseFloat(strings.TrimSpace(s), 64)
+	return n, err == nil && finite(n)
+}
+
+// finite reports whether n is neither ±Inf nor NaN.
+func finite(n float64) bool {
+	return !math.IsInf(n, 0) && !math.IsNaN(n)
+}
+
+// mean averages numbers, dividing each first when their sum overflows.
+func mean(numbers []float64) float64 {
+	sum := 0.0
+	for _, n := range numbers {
+		sum += n
+	}
+	if finite(sum) {
+		return sum / float64(len(numbers))
+	}
+	m := 0.0
+	for _, n := range numbers {
+		m += n / float64(len(numbers))
+	}
+	return m
+}
+
+// formatStat renders a statistic rounded to four decimal places, and huge
+// ones, which rounding would overflow, in full.
+func formatStat(f float64) string {
+	if math.Abs(f) >= 1e15 {
+		return strconv.FormatFloat(f, 'g', -1, 64)
+	}
+	return strconv.FormatFloat(math.Round(f*1e4)/1e4, 'f', -1, 64)
+}
+
+// profileLines lays out a profile for the dialog.
+func profileLines(p columnProfile, width int) []string {
+	percent := func(n int) string {
+		if p.Count == 0 {
+			return ""
+		}
+		return fmt.Sprintf(" (%.1f%%)", float64(n)*100/float64(p.Count))
+	}
+	lines := []string{
+		"Covers   " + p.Source,
+		fmt.Sprintf("Rows     %d", p.Count),
+		fmt.Sprintf("Nulls    %d%s", p.Nulls, percent(p.Nulls)),
+		fmt.Sprintf("Distinct %d", p.Distinct),
+	}
+	if p.Count > p.Nulls {
+		lines = append(lines, "Min      "+p.Min, "Max      "+p.Max)
+	}
+	if p.Numeric {
+		lines = append(lines, "Mean     "+formatStat(p.Mean), "Median   "+formatStat(p.Median))
+	}
+
+	if len(p.Top) > 0 {
+		lines = append(lines, "", "Most frequent")
+		labelW := 0
+		for _, t := range p.Top {
+			labelW = max(labelW, lipgloss.Width(t.Value))
+		}
+		labelW = min(labelW, max(width-profileBarWidth-12, 8))
+		lines = append(lines, barLines(p.Top, labelW)...)
+	}
+
+	if len(p.Buckets) > 0 {
+		title := "Histogram"
+		if p.Sampled > 0 {
+			title += fmt.Sprintf(" (first %d values)", p.Sampled)
+		}
+		lines = append(lines, "", title)
+		buckets := make([]valueCount, len(p.Buckets))
+		labelW := 0
+		for i, n := range p.Buckets {
+			buckets[i] = valueCount{Value: formatStat(p.Bounds[i]) + " – " + formatStat(p.Bounds[i+1]), Count: n}
+			labelW = max(labelW, lipgloss.Width(buckets[i].Value))
+		}
+		lines = append(lines, barLines(buckets, min(labelW, max(width-profileBarWidth-12, 8)))...)
+	}
+	return lines
+}
+
+// barLines draws counts as "label  ████ n" lines scaled to the largest.
+func barLines(counts []valueCount, labelW int) []string {
+	most := 1
+	for _, c := range counts {
+		most = max(most, c.Count)
+	}
+	lines := make([]string, len(counts))
+	for i, c := range counts {
+		label := padRight(truncateString(strings.ReplaceAll(c.Value, "\n", " "), labelW), labelW)
+		bar := strings.Repeat("█", c.Count*profileBarWidth/most)
+		if bar == "" && c.Count > 0 {
+			bar = "▏"
+		}
+		lines[i] = fmt.Sprintf("  %s  %s %d", label, bar, c.Count)
+	}
+	return lines
+}
+
+// profileViewHeight is how many profile lines fit in the dialog.
+func (m *BrowserModel) profileViewHeight() int {
+	return max(m.height-8, 3)
+}
+
+// handleProfileViewKey handles keys while the profile dialog is open.
+func (m *BrowserModel) handleProfileViewKey(msg tea.KeyPressMsg) (tea.Model, tea.Cmd) {
+	v := m.profileView
+	switch msg.String() {
+	case "esc", "q", "i":
+		m.showPreview = false
+		m.profileView = nil
+		return m, nil
+	case "j", "down":
+		v.scroll++
+	case "k", "up":
+		v.scroll = max(v.scroll-1, 0)
+	case "g", "home":
+		v.scroll = 0
+	}
+	return m, nil
+}
+
+// renderProfileView draws the column profile dialog.
+func (m *BrowserModel) renderProfileView() (string, int) {
+	v := m.profileView
+	width := max(min(70, m.width-6), 30)
+	inner := width - 2
+	bg := lipgloss.NewStyle().Background(styles.BgDark)
+
+	var lines []string
+	switch {
+	case v.loading:
+		lines = []string{bg.Foreground(styles.TextMuted).Render("Profiling " + v.column + "…")}
+	case v.err != "":
+		lines = []string{bg.Foreground(styles.Error).Render(truncateToWidth("Error: "+v.err, inner))}
+	default:
+		heading := bg.Foreground(styles.Primary).Bold(true)
+		for _, line := range profileLines(v.profile, inner) {
+			line = truncateToWidth(line, inner)
+			if line == "Most frequent" || strings.HasPrefix(line, "Histogram") {
+				line = heading.Render(line)
+			}
+			lines = append(lines, line)
+		}
+	}
+
+	height := m.profileViewHeight()
+	v.scroll = min(v.scroll, max(len(lines)-height, 0))
+	lines = lines[v.scroll:min(v.scroll+height, len(lines))]
+	return renderDialogBox(m.previewTitle, lines, "j/k Scroll  esc Close", width), width
+}
@@ -0,0 +1,86 @@
+package screens
+
+import (
+	"math"
+	"reflect"
+	"testing"
+
+	"github.com/jupiterozeye/tornado/internal/models"
+)
+
+func TestProfileResult(t *testing.T) {
+	r := &models.QueryResult{
+		Columns:     []string{"n", "s"},
+		ColumnTypes: []string{"INTEGER", "TEXT"},
+		Rows: [][]any{
+			{int64(4), "b"},
+			{int64(1), "a"},
+			{nil, "b"},
+			{int64(4), nil},
+			{int64(11), "c"},
+		},
+	}
+
+	p := profileResult(r, 0, defaultCellFormat)
+	if p.Count != 5 || p.Nulls != 1 || p.Distinct != 3 || !p.Numeric {
+		t.Errorf("counts = %d rows, %d nulls, %d distinct, numeric %v", p.Count, p.Nulls, p.Distinct, p.Numeric)
+	}
+	if p.Min != "1" || p.Max != "11" || p.Mean != 5 || p.Median != 4 {
+		t.Errorf("min %s max %s mean %v median %v, want 1 11 5 4", p.Min, p.Max, p.Mean, p.Median)
+	}
+	if want := []valueCount{{"4", 2}, {"1", 1}, {"11", 1}}; !reflect.DeepEqual(p.Top, want) {
+		t.Errorf("top = %v, want %v", p.Top, want)
+	}
+	total := 0
+	for _, n := range p.Buckets {
+		total += n
+	}
+	if len(p.Bounds) != len(p.Buckets)+1 || total != 4 {
+		t.Errorf("histogram %v %v does not cover the 4 values", p.Bounds, p.Buckets)
+	}
+
+	p = profileResult(r, 1, defaultCellFormat)
+	if p.Numeric || p.Min != "a" || p.Max != "c" || p.Buckets != nil {
+		t.Errorf("text profile = %+v", p)
+	}
+}
+
+func TestProfileResult_nonFinite(t *testing.T) {
+	r := &models.QueryResult{
+		Columns:     []string{"n"},
+		ColumnTypes: []string{"REAL"},
+		Rows:        [][]any{{1.0}, {math.Inf(1)}, {"NaN"}, {3.0}, {math.Inf(-1)}},
+	}
+	p := profileResult(r, 0, defaultCellFormat)
+	if !p.Numeric || p.Count != 5 || p.Distinct != 5 {
+		t.Errorf("numeric %v, %d rows, %d distinct", p.Numeric, p.Count, p.Distinct)
+	}
+	if p.Min != "1" || p.Max != "3" || p.Mean != 2 || p.Median != 1 {
+		t.Errorf("min %s max %s mean %v median %v, want 1 3 2 1", p.Min, p.Max, p.Mean, p.Median)
+	}
+	total := 0
+	for _, n := range p.Buckets {
+		total += n
+	}
+	if total != 2 {
+		t.Errorf("histogram %v %v, want the 2 finite values", p.Bounds, p.Buckets)
+	}
+}
+
+func TestProfileResult_hugeRange(t *testing.T) {
+	r := &models.QueryResult{
+		Columns:     []string{"n"},
+		ColumnTypes: []string{"REAL"},
+		Rows:        [][]any{{-1e308}, {1e308}, {1e308}, {0.0}},
+	}
+	p := profileResult(r, 0, defaultCellFormat)
+	if p.Min != "-1e+308" || p.Max != "1e+308" || p.Mean != 2.5e307 {
+		t.Errorf("min %s max %s mean %v", p.Min, p.Max, p.Mean)
+	}
+	if len(p.Bounds) != profileBuckets+1 || p.Bounds[0] != -1e308 || p.Bounds[profileBuckets] != 1e308 {
+		t.Errorf("bounds = %v", p.Bounds)
+	}
+	if p.Buckets[0] != 1 || p.Buckets[profileBuckets/2] != 1 || p.Buckets[profileBuckets-1] != 2 {
+		t.Errorf("buckets = %v", p.Buckets)
+	}
+}
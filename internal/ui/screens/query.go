@@ -47,6 +47,7 @@ import (
 
 	"github.com/jupiterozeye/tornado/internal/db"
 	"github.com/jupiterozeye/tornado/internal/models"
+	"github.com/jupiterozeye/tornado/internal/sqlparse"
 )
 
 // QueryModel is the model for the query editor screen.
@@ -180,6 +181,13 @@ func (m *QueryModel) loadFromHistory() {
 type QueryExecutedMsg struct {
 	Result *models.QueryResult
 	Err    error
+	// Query is the SQL that was executed, set even when Err is non-nil
+	Query string
+	// Schema lists the DDL statements in Query
+	Schema []sqlparse.SchemaChange
+	// Rerun is set when a watched result was re-run after the database
+	// changed, rather than run by the user
+	Rerun bool
 }
 
 // QueryHistoryMsg is sent when loading query history.
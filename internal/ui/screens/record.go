@@ -0,0 +1,175 @@
+package screens
+
+import (
+	"fmt"
+	"image/color"
+	"strings"
+	"unicode/utf8"
+
+	tea "charm.land/bubbletea/v2"
+	"charm.land/lipgloss/v2"
+
+	"github.com/jupiterozeye/tornado/internal/ui/styles"
+)
+
+// maxRecordNameWidth caps the column name gutter of the record view so
+// values keep most of the width.
+const maxRecordNameWidth = 30
+
+// toggleRecordView switches the results pane between the grid and the
+// selected row shown as a vertical list of columns.
+func (m *BrowserModel) toggleRecordView() {
+	active := m.activeResultSet()
+	if !m.recordView && (active == nil || len(active.Rows) == 0) {
+		m.statusMsg = "No row to show"
+		return
+	}
+	m.recordView = !m.recordView
+	m.recordScroll = 0
+	if m.recordView && m.results.Cursor() < 0 {
+		m.results.SetCursor(0)
+	}
+}
+
+// handleRecordKey handles keys while the record view is open. Keys it does
+// not use fall through to the results pane.
+func (m *BrowserModel) handleRecordKey(msg tea.KeyPressMsg) (bool, tea.Cmd) {
+	switch msg.String() {
+	case "esc", "q", "enter":
+		m.recordView = false
+	case "j", "down", "n":
+		m.results.MoveDown(1)
+		m.recordScroll = 0
+	case "k", "up", "N":
+		m.results.MoveUp(1)
+		m.recordScroll = 0
+	case "g", "home":
+		m.results.GotoTop()
+		m.recordScroll = 0
+	case "G", "end":
+		m.results.GotoBottom()
+		m.recordScroll = 0
+	case "ctrl+d", "pgdown":
+		m.recordScroll += max(m.results.Height()/2, 1)
+	case "ctrl+u", "pgup":
+		m.recordScroll = max(m.recordScroll-max(m.results.Height()/2, 1), 0)
+	case "ctrl+e":
+		m.recordScroll++
+	case "ctrl+y":
+		m.recordScroll = max(m.recordScroll-1, 0)
+	default:
+		return false, nil
+	}
+	return true, nil
+}
+
+// renderRecord draws the selected row as "column  type  value" lines, with
+// long values wrapped under their column.
+func (m *BrowserModel) renderRecord(bg color.Color, width, height int) string {
+	active := m.activeResultSet()
+	cursor := m.results.Cursor()
+	if active == nil || cursor < 0 || cursor >= len(active.Rows) {
+		return ""
+	}
+	row := active.Rows[cursor]
+
+	nameW, typeW := 0, 0
+	for i, col := range active.Columns {
+		nameW = max(nameW, lipgloss.Width(col))
+		if i < len(active.ColumnTypes) {
+			typeW = max(typeW, lipgloss.Width(strings.ToLower(active.ColumnTypes[i])))
+		}
+	}
+	nameW = min(nameW, maxRecordNameWidth, max(width/3, 1))
+	gutter := nameW + 1
+	if typeW > 0 {
+		gutter += typeW + 1
+	}
+	valueW := max(width-gutter, 10)
+
+	nameStyle := lipgloss.NewStyle().Background(bg).Foreground(styles.Primary).Bold(true)
+	typeStyle := lipgloss.NewStyle().Background(bg).Foreground(styles.TextMuted)
+	valueStyle := lipgloss.NewStyle().Background(bg).Foreground(styles.Text)
+	format := m.cellFormat()
+	nullStyle := lipgloss.NewStyle().Background(bg).Foreground(styles.TextMuted).Italic(true)
+	pad := lipgloss.NewStyle().Background(bg)
+
+	lines := []string{typeStyle.Render(fmt.Sprintf("Row %d/%d", cursor+1, len(active.Rows)))}
+	for i, col := range active.Columns {
+		var val any
+		if i < len(row) {
+			val = row[i]
+		}
+		text, kind := formatCell(val, columnType(active, i), format)
+		style := valueStyle
+		if kind == cellNull {
+			style = nullStyle
+		}
+
+		prefix := nameStyle.Render(padRight(truncateString(col, nameW), nameW)) + pad.Render(" ")
+		if typeW > 0 {
+			typ := ""
+			if i < len(active.ColumnTypes) {
+				typ = strings.ToLower(active.ColumnTypes[i])
+			}
+			prefix += typeStyle.Render(padRight(typ, typeW)) + pad.Render(" ")
+		}
+		for j, part := range wrapValue(text, valueW) {
+			if j > 0 {
+				prefix = pad.Render(strings.Repeat(" ", gutter))
+			}
+			lines = append(lines, prefix+style.Render(part))
+		}
+	}
+
+	m.recordScroll = min(m.recordScroll, max(len(lines)-height, 0))
+	lines = lines[m.recordScroll:min(m.recordScroll+height, len(lines))]
+	for i, line := range lines {
+		lines[i] = pad.Width(width).Render(line)
+	}
+	return strings.Join(lines, "\n")
+}
+
+// padRight pads s with spaces to width cells.
+func padRight(s string, width int) string {
+	return s + strings.Repeat(" ", max(width-lipgloss.Width(s), 0))
+}
+
+// wrapValue wraps a cell value to width, keeping its line breaks and tabs as
+// spaces. Lines break at spaces where possible and anywhere otherwise, so no
+// part of the value is lost.
+func wrapValue(s string, width int) []string {
+	width = max(width, 1)
+	var out []string
+	for _, line := range strings.Split(strings.ReplaceAll(s, "\t", "    "), "\n") {
+		line = strings.TrimRight(line, "\r")
+		for lipgloss.Width(line) > width {
+			cut, w := 0, 0
+			lastSpace := -1
+			for i, r := range line {
+				rw := lipgloss.Width(string(r))
+				if w+rw > width {
+					break
+				}
+				if r == ' ' {
+					lastSpace = i
+				}
+				w += rw
+				cut = i + utf8.RuneLen(r)
+			}
+			if cut == 0 {
+				// A single character wider than the line
+				_, cut = utf8.DecodeRuneInString(line)
+			}
+			if lastSpace > 0 && cut < len(line) && line[cut] != ' ' {
+				out = append(out, line[:lastSpace])
+				line = line[lastSpace+1:]
+				continue
+			}
+			out = append(out, line[:cut])
+			line = strings.TrimPrefix(line[cut:], " ")
+		}
+		out = append(out, line)
+	}
+	return out
+}
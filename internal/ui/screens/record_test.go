@@ -0,0 +1,25 @@
+package screens
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestWrapValue(t *testing.T) {
+	tests := []struct {
+		s     string
+		width int
+		want  []string
+	}{
+		{"short", 10, []string{"short"}},
+		{"the quick brown fox", 10, []string{"the quick", "brown fox"}},
+		{"abcdefghijkl", 5, []string{"abcde", "fghij", "kl"}},
+		{"line one\nline two", 20, []string{"line one", "line two"}},
+		{"", 5, []string{""}},
+	}
+	for _, tt := range tests {
+		if got := wrapValue(tt.s, tt.width); !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("wrapValue(%q, %d) = %q, want %q", tt.s, tt.width, got, tt.want)
+		}
+	}
+}
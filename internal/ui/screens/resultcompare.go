@@ -0,0 +1,452 @@
+package screens
+
+import (
+	"fmt"
+	"image/color"
+	"slices"
+	"strings"
+
+	tea "charm.land/bubbletea/v2"
+	"charm.land/lipgloss/v2"
+
+	"github.com/jupiterozeye/tornado/internal/models"
+	"github.com/jupiterozeye/tornado/internal/sqlparse"
+	"github.com/jupiterozeye/tornado/internal/ui/styles"
+)
+
+// resultBaseline is the result set later results are compared with.
+type resultBaseline struct {
+	Result *models.QueryResult
+	Query  string
+}
+
+// diffViewer is the state of the baseline diff shown in place of the results
+// grid.
+type diffViewer struct {
+	key       []string // Chosen or primary key; nil picks defaultDiffKey
+	diff      *setDiff
+	err       string
+	scroll    int // First diff row shown
+	scrollCol int // First column shown
+}
+
+// ResultDiffKeyMsg carries the primary key of the table a diffed result came
+// from.
+type ResultDiffKeyMsg struct {
+	Table string
+	Key   []string
+}
+
+// setDiff compares a result set with a baseline, matching rows by key columns.
+type setDiff struct {
+	Key []string
+	// Columns are the current result's columns, then those only the baseline
+	// has. OldCol and NewCol give each one's index in either result, or -1.
+	Columns        []string
+	OldCol, NewCol []int
+	// Rows holds the rows that differ: current rows in order, then removed
+	// baseline rows.
+	Rows []diffRow
+
+	Added, Removed, Changed, Same int
+}
+
+// diffRow is a row that differs from the baseline. Old is nil for an added
+// row and New for a removed one.
+type diffRow struct {
+	Old, New []any
+	Changed  []bool // By column of the diff
+}
+
+// compareResults diffs next against base by the key columns, which both must
+// have. Rows sharing a key are matched in order.
+func compareResults(base, next *models.QueryResult, key []string) (*setDiff, error) {
+	if len(key) == 0 {
+		return nil, fmt.Errorf("no key column")
+	}
+	d := &setDiff{Key: key}
+	for i, col := range next.Columns {
+		d.Columns = append(d.Columns, col)
+		d.NewCol = append(d.NewCol, i)
+		d.OldCol = append(d.OldCol, slices.Index(base.Columns, col))
+	}
+	for i, col := range base.Columns {
+		if !slices.Contains(next.Columns, col) {
+			d.Columns = append(d.Columns, col)
+			d.NewCol = append(d.NewCol, -1)
+			d.OldCol = append(d.OldCol, i)
+		}
+	}
+	oldKey, err := keyColumns(base.Columns, key)
+	if err != nil {
+		return nil, fmt.Errorf("baseline: %w", err)
+	}
+	newKey, err := keyColumns(next.Columns, key)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, m := range matchRows(base.Rows, next.Rows, oldKey, newKey) {
+		switch {
+		case m.Old == nil:
+			d.Rows = append(d.Rows, diffRow{New: m.New, Changed: make([]bool, len(d.Columns))})
+			d.Added++
+		case m.New == nil:
+			d.Rows = append(d.Rows, diffRow{Old: m.Old, Changed: make([]bool, len(d.Columns))})
+			d.Removed++
+		default:
+			changed := changedCells(m.Old, m.New, d.OldCol, d.NewCol)
+			if changed == nil {
+				d.Same++
+				continue
+			}
+			d.Rows = append(d.Rows, diffRow{Old: m.Old, New: m.New, Changed: changed})
+			d.Changed++
+		}
+	}
+	return d, nil
+}
+
+// keyColumns returns the indexes of the key columns.
+func keyColumns(columns, key []string) ([]int, error) {
+	idx := make([]int, len(key))
+	for i, name := range key {
+		idx[i] = slices.Index(columns, name)
+		if idx[i] < 0 {
+			return nil, fmt.Errorf("no column %q", name)
+		}
+	}
+	return idx, nil
+}
+
+// defaultDiffKey picks a key column when the primary key is not known: an
+// "id" column, else the first column unique in both results, else the first
+// column they share.
+func defaultDiffKey(base, next *models.QueryResult) []string {
+	var shared []string
+	for _, col := range next.Columns {
+		if slices.Contains(base.Columns, col) {
+			shared = append(shared, col)
+		}
+	}
+	for _, col := range shared {
+		if strings.EqualFold(col, "id") {
+			return []string{col}
+		}
+	}
+	for _, col := range shared {
+		if uniqueColumn(base.Rows, slices.Index(base.Columns, col)) && uniqueColumn(next.Rows, slices.Index(next.Columns, col)) {
+			return []string{col}
+		}
+	}
+	if len(shared) > 0 {
+		return shared[:1]
+	}
+	return nil
+}
+
+// markBaseline keeps the active result as the baseline for diffs.
+func (m *BrowserModel) markBaseline() {
+	if m.currentResults == nil {
+		m.statusMsg = "No result to mark as baseline"
+		return
+	}
+	query := m.currentResults.Query
+	if m.activeTab >= 0 && m.activeTab < len(m.resultTabs) {
+		query = m.resultTabs[m.activeTab].Query
+	}
+	m.baseline = &resultBaseline{Result: m.currentResults, Query: query}
+	m.statusMsg = fmt.Sprintf("Baseline set (%d rows): run another query and press B to diff", len(m.currentResults.Rows))
+}
+
+// openDiff shows the active result's diff against the baseline, keyed by the
+// given columns or, when none are given, by the table's primary key once it
+// is looked up.
+func (m *BrowserModel) openDiff(key []string) tea.Cmd {
+	if m.baseline == nil {
+		m.statusMsg = "No baseline: press b on a result to mark it"
+		return nil
+	}
+	if m.currentResults == nil {
+		m.statusMsg = "No result to compare"
+		return nil
+	}
+	if m.currentResults == m.baseline.Result {
+		m.statusMsg = "This is the baseline: run another query to compare"
+		return nil
+	}
+	if m.diffView == nil || len(key) > 0 {
+		m.diffView = &diffViewer{key: key}
+	}
+	m.recordView = false
+	m.chartView = nil
+	m.selection = nil
+	m.statusMsg = ""
+	m.refreshDiff()
+
+	table := m.resultTable()
+	if len(key) > 0 || table == "" || m.db == nil {
+		return nil
+	}
+	db := m.db
+	return func() tea.Msg {
+		schema, err := db.DescribeTable(table)
+		if err != nil || schema == nil {
+			return ResultDiffKeyMsg{Table: table}
+		}
+		return ResultDiffKeyMsg{Table: table, Key: schema.PrimaryKey}
+	}
+}
+
+// applyDiffKey keys the open diff by the primary key, unless a key was
+// picked meanwhile or the results lack it.
+func (m *BrowserModel) applyDiffKey(msg ResultDiffKeyMsg) {
+	v := m.diffView
+	if v == nil || v.key != nil || len(msg.Key) == 0 || m.currentResults == nil || m.baseline == nil {
+		return
+	}
+	if _, err := keyColumns(m.currentResults.Columns, msg.Key); err != nil {
+		return
+	}
+	if _, err := keyColumns(m.baseline.Result.Columns, msg.Key); err != nil {
+		return
+	}
+	v.key = msg.Key
+	m.refreshDiff()
+}
+
+// resultTable names the table the active result was read from: the explorer
+// table, or the only table a query selects from.
+func (m *BrowserModel) resultTable() string {
+	if m.tableQuery != nil {
+		return m.tableQuery.Table
+	}
+	if m.activeTab < 0 || m.activeTab >= len(m.resultTabs) {
+		return ""
+	}
+	query := strings.TrimRight(strings.TrimSpace(m.resultTabs[m.activeTab].Query), ";")
+	var tables []string
+	for _, t := range sqlparse.Analyze(query, len(query)).Tables {
+		if t.Derived {
+			return ""
+		}
+		tables = append(tables, t.Name)
+	}
+	if len(tables) != 1 {
+		return ""
+	}
+	return tables[0]
+}
+
+// refreshDiff compares the current result with the baseline again.
+func (m *BrowserModel) refreshDiff() {
+	v := m.diffView
+	if v == nil {
+		return
+	}
+	v.diff, v.err = nil, ""
+	if m.baseline == nil || m.currentResults == nil {
+		v.err = "Nothing to compare"
+		return
+	}
+	key := v.key
+	if key == nil {
+		key = defaultDiffKey(m.baseline.Result, m.currentResults)
+	}
+	d, err := compareResults(m.baseline.Result, m.currentResults, key)
+	if err != nil {
+		v.err = "Cannot diff: " + err.Error()
+		return
+	}
+	v.diff = d
+	v.scroll = min(v.scroll, max(len(d.Rows)-1, 0))
+	v.scrollCol = min(v.scrollCol, max(len(d.Columns)-1, 0))
+}
+
+// diffCommand handles ":diff": "base" marks the baseline, "off" closes the
+// diff and anything else lists key columns, separated by commas.
+func (m *BrowserModel) diffCommand(arg string) tea.Cmd {
+	arg = strings.TrimSpace(arg)
+	switch arg {
+	case "base", "baseline":
+		m.markBaseline()
+		return nil
+	case "off":
+		m.diffView = nil
+		return nil
+	case "":
+		return m.openDiff(nil)
+	}
+	var key []string
+	for _, col := range strings.Split(arg, ",") {
+		if col = strings.TrimSpace(col); col != "" {
+			key = append(key, col)
+		}
+	}
+	return m.openDiff(key)
+}
+
+// handleDiffKey handles keys while the diff is shown. K keys the diff by the
+// next shared column. Keys it does not use fall through to the results pane.
+func (m *BrowserModel) handleDiffKey(msg tea.KeyPressMsg) (bool, tea.Cmd) {
+	v := m.diffView
+	rows, cols := 0, 0
+	if v.diff != nil {
+		rows, cols = len(v.diff.Rows), len(v.diff.Columns)
+	}
+	page := max(m.results.Height()/2, 1)
+	switch msg.String() {
+	case "esc", "q", "B":
+		m.diffView = nil
+	case "j", "down":
+		v.scroll = min(v.scroll+1, max(rows-1, 0))
+	case "k", "up":
+		v.scroll = max(v.scroll-1, 0)
+	case "ctrl+d", "pgdown":
+		v.scroll = min(v.scroll+page, max(rows-1, 0))
+	case "ctrl+u", "pgup":
+		v.scroll = max(v.scroll-page, 0)
+	case "g", "home":
+		v.scroll = 0
+	case "G", "end":
+		v.scroll = max(rows-1, 0)
+	case "l", "right":
+		v.scrollCol = min(v.scrollCol+1, max(cols-1, 0))
+	case "h", "left":
+		v.scrollCol = max(v.scrollCol-1, 0)
+	case "K":
+		if v.diff == nil || m.baseline == nil {
+			return true, nil
+		}
+		var shared []string
+		for c, col := range v.diff.Columns {
+			if v.diff.OldCol[c] >= 0 && v.diff.NewCol[c] >= 0 {
+				shared = append(shared, col)
+			}
+		}
+		if len(shared) == 0 {
+			return true, nil
+		}
+		next := 0
+		if len(v.diff.Key) == 1 {
+			next = (slices.Index(shared, v.diff.Key[0]) + 1) % len(shared)
+		}
+		v.key = []string{shared[next]}
+		v.scroll = 0
+		m.refreshDiff()
+		m.statusMsg = "Diff key: " + shared[next]
+	default:
+		return false, nil
+	}
+	return true, nil
+}
+
+// diffSummary is the first line of the diff view.
+func diffSummary(d *setDiff) string {
+	text := fmt.Sprintf("+%d added  -%d removed  ~%d changed  %d same  (key %s)",
+		d.Added, d.Removed, d.Changed, d.Same, strings.Join(d.Key, ", "))
+	var added, removed []string
+	for c, col := range d.Columns {
+		switch {
+		case d.OldCol[c] < 0:
+			added = append(added, col)
+		case d.NewCol[c] < 0:
+			removed = append(removed, col)
+		}
+	}
+	if len(added) > 0 {
+		text += "  columns +" + strings.Join(added, ", +")
+	}
+	if len(removed) > 0 {
+		text += "  columns -" + strings.Join(removed, ", -")
+	}
+	return text
+}
+
+// diffCellText is what a diff cell shows: the value, or "old → new" where it
+// changed.
+func (m *BrowserModel) diffCellText(d *setDiff, r diffRow, c int) string {
+	format := m.cellFormat()
+	text := func(row []any, i int, res *models.QueryResult) string {
+		s, _ := formatCell(cellAt(row, i), columnType(res, i), format)
+		s, _, _ = strings.Cut(s, "\n")
+		return s
+	}
+	switch {
+	case r.Changed[c]:
+		return text(r.Old, d.OldCol[c], m.baseline.Result) + " → " + text(r.New, d.NewCol[c], m.currentResults)
+	case r.New != nil && d.NewCol[c] >= 0:
+		return text(r.New, d.NewCol[c], m.currentResults)
+	case r.Old != nil && d.OldCol[c] >= 0:
+		return text(r.Old, d.OldCol[c], m.baseline.Result)
+	}
+	return ""
+}
+
+// renderDiff draws the diff view: the summary, then the differing rows marked
+// + added, - removed and ~ changed, with changed cells highlighted.
+func (m *BrowserModel) renderDiff(bg color.Color, width, height int) string {
+	v := m.diffView
+	muted := lipgloss.NewStyle().Background(bg).Foreground(styles.TextMuted)
+	pad := func(s string) string {
+		return s + muted.Render(strings.Repeat(" ", max(width-lipgloss.Width(s), 0)))
+	}
+	if v.diff == nil {
+		return pad(lipgloss.NewStyle().Background(bg).Foreground(styles.Error).Render(truncateToWidth(v.err, width)))
+	}
+	d := v.diff
+	lines := []string{pad(muted.Render(truncateToWidth(diffSummary(d), width)))}
+	if len(d.Rows) == 0 {
+		lines = append(lines, pad(lipgloss.NewStyle().Background(bg).Foreground(styles.Success).Render("No differences")))
+		return strings.Join(lines, "\n")
+	}
+
+	shown := d.Rows[v.scroll:min(len(d.Rows), v.scroll+max(height-2, 1))]
+	widths := make([]int, len(d.Columns))
+	for c, col := range d.Columns {
+		widths[c] = lipgloss.Width(col)
+		for _, r := range shown {
+			widths[c] = max(widths[c], lipgloss.Width(m.diffCellText(d, r, c)))
+		}
+		widths[c] = min(max(widths[c], minColumnWidth), maxAutoColumnWidth)
+	}
+
+	header := lipgloss.NewStyle().Background(bg).Foreground(styles.Primary).Bold(true)
+	text := lipgloss.NewStyle().Background(bg).Foreground(styles.Text)
+	added := lipgloss.NewStyle().Background(bg).Foreground(styles.Success)
+	removed := lipgloss.NewStyle().Background(bg).Foreground(styles.Error)
+	changed := lipgloss.NewStyle().Background(bg).Foreground(styles.Warning).Bold(true)
+	sep := muted.Render(" ")
+
+	line := muted.Render("  ")
+	for c := v.scrollCol; c < len(d.Columns); c++ {
+		line += header.Render(padRight(truncateString(d.Columns[c], widths[c]), widths[c])) + sep
+	}
+	lines = append(lines, pad(truncateToWidth(line, width)))
+	for _, r := range shown {
+		marker, style := "~", text
+		switch {
+		case r.Old == nil:
+			marker, style = "+", added
+		case r.New == nil:
+			marker, style = "-", removed
+		}
+		line := style.Render(marker + " ")
+		if marker == "~" {
+			line = changed.Render(marker + " ")
+		}
+		for c := v.scrollCol; c < len(d.Columns); c++ {
+			cell := padRight(truncateString(m.diffCellText(d, r, c), widths[c]), widths[c])
+			if r.Changed[c] {
+				line += changed.Render(cell) + sep
+			} else {
+				line += style.Render(cell) + sep
+			}
+		}
+		lines = append(lines, pad(truncateToWidth(line, width)))
+	}
+	if more := len(d.Rows) - v.scroll - len(shown); more > 0 && len(lines) < height {
+		lines = append(lines, pad(muted.Render(fmt.Sprintf("  … %d more", more))))
+	}
+	return strings.Join(lines, "\n")
+}
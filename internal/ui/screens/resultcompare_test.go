@@ -0,0 +1,70 @@
+package screens
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/jupiterozeye/tornado/internal/models"
+)
+
+func TestCompareResults(t *testing.T) {
+	base := &models.QueryResult{Columns: []string{"id", "name", "old"}, Rows: [][]any{
+		{int64(1), "ann", "x"},
+		{int64(2), "bob", "y"},
+		{int64(3), "cy", "z"},
+	}}
+	// Reordered, one changed, one removed, one added and a column swapped
+	next := &models.QueryResult{Columns: []string{"id", "name", "new"}, Rows: [][]any{
+		{int64(3), "cy", true},
+		{int64(1), "anne", true},
+		{int64(4), "dee", false},
+	}}
+
+	d, err := compareResults(base, next, defaultDiffKey(base, next))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(d.Key, []string{"id"}) {
+		t.Errorf("key = %v, want id", d.Key)
+	}
+	if d.Added != 1 || d.Removed != 1 || d.Changed != 1 || d.Same != 1 {
+		t.Errorf("counts = +%d -%d ~%d =%d, want 1 each", d.Added, d.Removed, d.Changed, d.Same)
+	}
+	if want := []string{"id", "name", "new", "old"}; !reflect.DeepEqual(d.Columns, want) {
+		t.Errorf("columns = %v, want %v", d.Columns, want)
+	}
+	if r := d.Rows[0]; r.Old == nil || !reflect.DeepEqual(r.Changed, []bool{false, true, false, false}) {
+		t.Errorf("changed row = %+v, want only name changed", r)
+	}
+	if r := d.Rows[1]; r.Old != nil || r.New[0] != int64(4) {
+		t.Errorf("added row = %+v", r)
+	}
+	if r := d.Rows[2]; r.New != nil || r.Old[0] != int64(2) {
+		t.Errorf("removed row = %+v", r)
+	}
+
+	if _, err := compareResults(base, next, []string{"old"}); err == nil {
+		t.Error("a key missing from the current result should fail")
+	}
+}
+
+func TestCompareResultsDuplicateKeys(t *testing.T) {
+	// Rows sharing a key are matched in order
+	base := &models.QueryResult{Columns: []string{"k", "v"}, Rows: [][]any{{"a", 1}, {"a", 2}}}
+	next := &models.QueryResult{Columns: []string{"k", "v"}, Rows: [][]any{{"a", 1}, {"a", 3}, {"a", 4}}}
+	d, err := compareResults(base, next, []string{"k"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if d.Same != 1 || d.Changed != 1 || d.Added != 1 || d.Removed != 0 {
+		t.Errorf("counts = +%d -%d ~%d =%d", d.Added, d.Removed, d.Changed, d.Same)
+	}
+}
+
+func TestDefaultDiffKey(t *testing.T) {
+	base := &models.QueryResult{Columns: []string{"grp", "code"}, Rows: [][]any{{"x", "a"}, {"x", "b"}}}
+	next := &models.QueryResult{Columns: []string{"grp", "code"}, Rows: [][]any{{"y", "a"}}}
+	if got := defaultDiffKey(base, next); !reflect.DeepEqual(got, []string{"code"}) {
+		t.Errorf("defaultDiffKey = %v, want the first unique column", got)
+	}
+}
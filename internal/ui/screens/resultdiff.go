@@ -0,0 +1,234 @@
+package screens
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/jupiterozeye/tornado/internal/models"
+)
+
+// rowChange describes how a row differs from the previous run of its query.
+type rowChange struct {
+	// Added is set when the row has no counterpart in the previous run.
+	Added bool
+	// Changed marks the columns whose value differs.
+	Changed []bool
+	// Deltas holds new minus old for numeric columns that changed.
+	Deltas map[int]float64
+}
+
+// delta returns the change in column col, if it is numeric and changed.
+func (c *rowChange) delta(col int) (float64, bool) {
+	if c == nil {
+		return 0, false
+	}
+	d, ok := c.Deltas[col]
+	return d, ok
+}
+
+// resultDiff maps the rows of a result to their changes. Rows are keyed by
+// their first cell's address, so the filtered view, which shares row slices
+// with the full result, finds them too.
+type resultDiff map[*any]*rowChange
+
+// row returns the change recorded for row, or nil if it is unchanged.
+func (d resultDiff) row(row []any) *rowChange {
+	if d == nil || len(row) == 0 {
+		return nil
+	}
+	return d[&row[0]]
+}
+
+// diffResults compares two runs of the same query. Rows are matched by their
+// first column when its values are unique in both results, and by position
+// otherwise. Results with different columns are not compared.
+func diffResults(prev, next *models.QueryResult) resultDiff {
+	if prev == nil || next == nil || !sameColumns(prev.Columns, next.Columns) || len(next.Columns) == 0 {
+		return nil
+	}
+
+	cols := make([]int, len(next.Columns))
+	for i := range cols {
+		cols[i] = i
+	}
+	var key []int
+	if uniqueColumn(prev.Rows, 0) && uniqueColumn(next.Rows, 0) {
+		key = []int{0}
+	}
+
+	diff := resultDiff{}
+	for _, m := range matchRows(prev.Rows, next.Rows, key, key) {
+		row := m.New
+		if len(row) == 0 {
+			continue // Removed, or nothing to key a change by
+		}
+		if m.Old == nil {
+			diff[&row[0]] = &rowChange{Added: true, Changed: make([]bool, len(cols))}
+			continue
+		}
+		changed := changedCells(m.Old, row, cols, cols)
+		if changed == nil {
+			continue
+		}
+		change := &rowChange{Changed: changed}
+		for c, ch := range changed {
+			if !ch {
+				continue
+			}
+			if a, ok := numericValue(cellAt(m.Old, c)); ok {
+				if b, ok := numericValue(cellAt(row, c)); ok {
+					if change.Deltas == nil {
+						change.Deltas = map[int]float64{}
+					}
+					change.Deltas[c] = b - a
+				}
+			}
+		}
+		diff[&row[0]] = change
+	}
+	return diff
+}
+
+// rowMatch pairs a row with its counterpart in an older result. Old is nil
+// for an added row and New for a removed one.
+type rowMatch struct {
+	Old, New []any
+}
+
+// matchRows pairs each row of next with a row of prev: the first one not
+// yet paired whose key cells are the same, or with no key, the one at the
+// same position. The key columns are given by index in each result. Rows of
+// next come first, in order, then the rows of prev left over.
+func matchRows(prev, next [][]any, prevKey, nextKey []int) []rowMatch {
+	var byKey map[string][]int
+	if len(prevKey) > 0 {
+		byKey = make(map[string][]int, len(prev))
+		for i, row := range prev {
+			k := rowKey(row, prevKey)
+			byKey[k] = append(byKey[k], i)
+		}
+	}
+	paired := make([]bool, len(prev))
+	matches := make([]rowMatch, 0, len(next))
+	for i, row := range next {
+		j := -1
+		if byKey != nil {
+			if k := rowKey(row, nextKey); len(byKey[k]) > 0 {
+				j, byKey[k] = byKey[k][0], byKey[k][1:]
+			}
+		} else if i < len(prev) {
+			j = i
+		}
+		m := rowMatch{New: row}
+		if j >= 0 {
+			m.Old = prev[j]
+			paired[j] = true
+		}
+		matches = append(matches, m)
+	}
+	for j, row := range prev {
+		if !paired[j] {
+			matches = append(matches, rowMatch{Old: row})
+		}
+	}
+	return matches
+}
+
+// changedCells compares a row with its match. Column c is at oldCol[c] in
+// old and newCol[c] in row, or -1 where a result lacks it; such columns are
+// not compared. It returns which columns differ, or nil if none do.
+func changedCells(old, row []any, oldCol, newCol []int) []bool {
+	var changed []bool
+	for c := range newCol {
+		o, n := oldCol[c], newCol[c]
+		if o < 0 || n < 0 || cellKey(cellAt(old, o)) == cellKey(cellAt(row, n)) {
+			continue
+		}
+		if changed == nil {
+			changed = make([]bool, len(newCol))
+		}
+		changed[c] = true
+	}
+	return changed
+}
+
+func sameColumns(a, b []string) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if a[i] != b[i] {
+			return false
+		}
+	}
+	return true
+}
+
+// uniqueColumn reports whether no two rows hold the same value in column col.
+func uniqueColumn(rows [][]any, col int) bool {
+	seen := make(map[string]bool, len(rows))
+	for _, row := range rows {
+		k := cellKey(cellAt(row, col))
+		if seen[k] {
+			return false
+		}
+		seen[k] = true
+	}
+	return true
+}
+
+// rowKey joins the key cells of a row into one comparable string.
+func rowKey(row []any, cols []int) string {
+	parts := make([]string, len(cols))
+	for i, c := range cols {
+		parts[i] = cellKey(cellAt(row, c))
+	}
+	return strings.Join(parts, "\x1f")
+}
+
+func cellAt(row []any, i int) any {
+	if i < 0 || i >= len(row) {
+		return nil
+	}
+	return row[i]
+}
+
+// cellKey returns a comparable form of a cell value.
+func cellKey(v any) string {
+	switch v := v.(type) {
+	case nil:
+		return "\x00NULL"
+	case []byte:
+		return string(v)
+	}
+	return fmt.Sprintf("%v", v)
+}
+
+// numericValue returns v as a float when the driver returned a number.
+func numericValue(v any) (float64, bool) {
+	switch n := v.(type) {
+	case int:
+		return float64(n), true
+	case int32:
+		return float64(n), true
+	case int64:
+		return float64(n), true
+	case uint64:
+		return float64(n), true
+	case float32:
+		return float64(n), true
+	case float64:
+		return n, true
+	}
+	return 0, false
+}
+
+// formatDelta renders a delta with an explicit sign.
+func formatDelta(d float64) string {
+	s := strconv.FormatFloat(d, 'f', -1, 64)
+	if d > 0 {
+		s = "+" + s
+	}
+	return s
+}
@@ -0,0 +1,79 @@
+package screens
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/jupiterozeye/tornado/internal/models"
+)
+
+func TestDiffResults(t *testing.T) {
+	cols := []string{"status", "n"}
+	prev := &models.QueryResult{Columns: cols, Rows: [][]any{
+		{"ok", int64(10)},
+		{"failed", int64(3)},
+	}}
+	// Rows are matched by status even though the order changed
+	next := &models.QueryResult{Columns: cols, Rows: [][]any{
+		{"failed", int64(5)},
+		{"ok", int64(10)},
+		{"retry", int64(1)},
+	}}
+
+	diff := diffResults(prev, next)
+	if c := diff.row(next.Rows[1]); c != nil {
+		t.Errorf("unchanged row reported as %+v", c)
+	}
+	c := diff.row(next.Rows[0])
+	if c == nil || c.Changed[0] || !c.Changed[1] {
+		t.Fatalf("changed row = %+v, want only n changed", c)
+	}
+	if d, ok := c.delta(1); !ok || formatDelta(d) != "+2" {
+		t.Errorf("delta = %v, %v, want +2", d, ok)
+	}
+	if c := diff.row(next.Rows[2]); c == nil || !c.Added {
+		t.Errorf("new row = %+v, want Added", c)
+	}
+
+	// Duplicate first-column values fall back to matching by position
+	dup := &models.QueryResult{Columns: cols, Rows: [][]any{{"ok", 1.5}, {"ok", 2.0}}}
+	again := &models.QueryResult{Columns: cols, Rows: [][]any{{"ok", 1.5}, {"ok", 1.0}}}
+	diff = diffResults(dup, again)
+	if d, ok := diff.row(again.Rows[1]).delta(1); !ok || formatDelta(d) != "-1" {
+		t.Errorf("positional delta = %v, %v, want -1", d, ok)
+	}
+
+	if diffResults(prev, &models.QueryResult{Columns: []string{"other"}}) != nil {
+		t.Error("results with different columns should not be compared")
+	}
+}
+
+func TestMatchRows(t *testing.T) {
+	prev := [][]any{{1, "a"}, {2, "b"}, {2, "c"}, {3, "d"}}
+	next := [][]any{{"b2", 2}, {"x", 4}, {"b", 2}, {"a", 1}}
+
+	// By key: duplicates pair in order, leftovers come last
+	var got [][2]any
+	for _, m := range matchRows(prev, next, []int{0}, []int{1}) {
+		got = append(got, [2]any{cellAt(m.Old, 1), cellAt(m.New, 0)})
+	}
+	want := [][2]any{{"b", "b2"}, {nil, "x"}, {"c", "b"}, {"a", "a"}, {"d", nil}}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("by key = %v, want %v", got, want)
+	}
+
+	// By position
+	matches := matchRows(prev[:2], next[:3], nil, nil)
+	if len(matches) != 3 || matches[1].Old[1] != "b" || matches[2].Old != nil {
+		t.Errorf("by position = %v", matches)
+	}
+
+	// Columns missing from either row are not compared
+	changed := changedCells([]any{1, "a", nil}, []any{"b", 1}, []int{0, 1, 2}, []int{1, 0, -1})
+	if !reflect.DeepEqual(changed, []bool{false, true, false}) {
+		t.Errorf("changedCells = %v", changed)
+	}
+	if changed := changedCells([]any{1, "a"}, []any{1, "a"}, []int{0, 1}, []int{0, 1}); changed != nil {
+		t.Errorf("changedCells of equal rows = %v", changed)
+	}
+}
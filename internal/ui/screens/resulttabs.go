@@ -0,0 +1,245 @@
+package screens
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"charm.land/lipgloss/v2"
+
+	"github.com/jupiterozeye/tornado/internal/models"
+	"github.com/jupiterozeye/tornado/internal/ui/styles"
+)
+
+// maxResultTabs is how many unpinned result sets are kept per session.
+// Pinned tabs are never evicted and do not count towards the limit.
+const maxResultTabs = 10
+
+// resultTab is one entry of the session's result history. It holds the
+// result set together with the view state of the results pane so switching
+// tabs restores the grid exactly as it was left.
+type resultTab struct {
+	Result     *models.QueryResult
+	Err        string
+	Query      string
+	ExecutedAt time.Time
+	Pinned     bool
+
+	// diff marks what changed since the previous run when the query was
+	// re-run by a watch
+	diff resultDiff
+
+	tableQuery *tableQuery
+	filter     string
+	cursorRow  int
+	cursorCol  int
+	scrollCol  int
+}
+
+// label returns the short tab title: the query's first line and the time it ran.
+func (t *resultTab) label(maxQuery int) string {
+	q := strings.Join(strings.Fields(t.Query), " ")
+	if q == "" {
+		q = "(empty)"
+	}
+	if lipgloss.Width(q) > maxQuery {
+		q = truncateString(q, maxQuery-1) + "…"
+	}
+	return q + " " + t.ExecutedAt.Format("15:04:05")
+}
+
+// activeDiff returns the changes recorded for the active tab's last re-run.
+func (m *BrowserModel) activeDiff() resultDiff {
+	if m.activeTab < 0 || m.activeTab >= len(m.resultTabs) {
+		return nil
+	}
+	return m.resultTabs[m.activeTab].diff
+}
+
+// saveActiveTab stores the results pane state into the active tab.
+func (m *BrowserModel) saveActiveTab() {
+	if m.activeTab < 0 || m.activeTab >= len(m.resultTabs) {
+		return
+	}
+	tab := m.resultTabs[m.activeTab]
+	tab.Result = m.currentResults
+	tab.Err = m.queryError
+	tab.tableQuery = m.tableQuery
+	tab.filter = m.resultsFilter
+	tab.cursorRow = m.results.Cursor()
+	tab.cursorCol = m.resultsCursorCol
+	tab.scrollCol = m.resultsScrollCol
+}
+
+// loadTab makes tab i the active tab and restores its view state.
+func (m *BrowserModel) loadTab(i int) {
+	if i < 0 || i >= len(m.resultTabs) {
+		return
+	}
+	m.activeTab = i
+	tab := m.resultTabs[i]
+	m.currentResults = tab.Result
+	m.queryError = tab.Err
+	m.tableQuery = tab.tableQuery
+	m.resultsFilter = tab.filter
+	m.resultsFilterActive = false
+	m.whereFilterActive = false
+	m.filteredResults = nil
+	m.resultsCursorCol = tab.cursorCol
+	m.resultsScrollCol = tab.scrollCol
+	m.updateResultsTable()
+	if m.resultsFilter != "" {
+		m.applyFilter()
+	}
+	m.results.SetCursor(tab.cursorRow)
+}
+
+// recordResult files the results pane's current state as a tab. Refinements of
+// the active result (re-sorting or filtering an explorer table) replace it in
+// place unless it is pinned; anything else opens a new tab.
+func (m *BrowserModel) recordResult(query string, replace bool) {
+	tab := &resultTab{Query: query, ExecutedAt: time.Now()}
+	if replace && m.activeTab >= 0 && m.activeTab < len(m.resultTabs) && !m.resultTabs[m.activeTab].Pinned {
+		old := m.resultTabs[m.activeTab]
+		m.resultTabs[m.activeTab] = tab
+		m.resultTabClosed(old.Query)
+	} else {
+		m.resultTabs = append(m.resultTabs, tab)
+		m.activeTab = len(m.resultTabs) - 1
+		m.evictResultTabs()
+	}
+	m.saveActiveTab()
+}
+
+// evictResultTabs drops the oldest unpinned tabs beyond maxResultTabs.
+func (m *BrowserModel) evictResultTabs() {
+	unpinned := 0
+	for _, t := range m.resultTabs {
+		if !t.Pinned {
+			unpinned++
+		}
+	}
+	for i := 0; unpinned > maxResultTabs && i < len(m.resultTabs); {
+		if m.resultTabs[i].Pinned || i == m.activeTab {
+			i++
+			continue
+		}
+		evicted := m.resultTabs[i]
+		m.resultTabs = append(m.resultTabs[:i], m.resultTabs[i+1:]...)
+		if m.activeTab > i {
+			m.activeTab--
+		}
+		unpinned--
+		m.resultTabClosed(evicted.Query)
+	}
+}
+
+// switchResultTab moves to the tab delta positions away, wrapping around.
+func (m *BrowserModel) switchResultTab(delta int) {
+	if len(m.resultTabs) < 2 {
+		return
+	}
+	m.saveActiveTab()
+	n := len(m.resultTabs)
+	m.loadTab(((m.activeTab+delta)%n + n) % n)
+	m.statusMsg = fmt.Sprintf("Result %d/%d", m.activeTab+1, n)
+}
+
+// togglePinResultTab pins or unpins the active tab.
+func (m *BrowserModel) togglePinResultTab() {
+	if m.activeTab < 0 || m.activeTab >= len(m.resultTabs) {
+		m.statusMsg = "No result to pin"
+		return
+	}
+	tab := m.resultTabs[m.activeTab]
+	tab.Pinned = !tab.Pinned
+	if tab.Pinned {
+		m.statusMsg = "Pinned result"
+	} else {
+		m.statusMsg = "Unpinned result"
+		m.evictResultTabs()
+	}
+}
+
+// closeResultTab closes the active tab and shows its neighbour.
+func (m *BrowserModel) closeResultTab() {
+	if m.activeTab < 0 || m.activeTab >= len(m.resultTabs) {
+		m.clearResults()
+		return
+	}
+	closed := m.resultTabs[m.activeTab]
+	m.resultTabs = append(m.resultTabs[:m.activeTab], m.resultTabs[m.activeTab+1:]...)
+	if len(m.resultTabs) == 0 {
+		m.activeTab = -1
+		m.clearResults()
+	} else {
+		m.activeTab = min(m.activeTab, len(m.resultTabs)-1)
+		m.loadTab(m.activeTab)
+		m.statusMsg = "Closed result"
+	}
+	m.resultTabClosed(closed.Query)
+}
+
+// resultTabClosed stops the query watch once the last tab showing its query
+// is closed or evicted, since its re-runs would have nowhere to go.
+func (m *BrowserModel) resultTabClosed(query string) {
+	w := m.queryWatch
+	if w == nil || w.Query != query {
+		return
+	}
+	for _, t := range m.resultTabs {
+		if t.Query == query {
+			return
+		}
+	}
+	m.queryWatch = nil
+	m.statusMsg = "Watch stopped: its result was closed"
+}
+
+// renderResultTabs renders the tab bar shown above the results grid.
+func (m *BrowserModel) renderResultTabs(width int) string {
+	if len(m.resultTabs) == 0 || width < 1 {
+		return ""
+	}
+	bg := styles.BgDefault
+	activeStyle := lipgloss.NewStyle().Foreground(styles.BgDark).Background(styles.Primary).Bold(true)
+	inactiveStyle := lipgloss.NewStyle().Foreground(styles.TextMuted).Background(styles.BgLight)
+	errStyle := lipgloss.NewStyle().Foreground(styles.Error).Background(styles.BgLight)
+	sep := lipgloss.NewStyle().Background(bg).Render(" ")
+
+	parts := make([]string, len(m.resultTabs))
+	widths := make([]int, len(m.resultTabs))
+	for i, tab := range m.resultTabs {
+		text := fmt.Sprintf(" %d ", i+1)
+		if tab.Pinned {
+			text += "◆ "
+		}
+		if m.baseline != nil && tab.Result == m.baseline.Result {
+			text += "≡ "
+		}
+		text += tab.label(24) + " "
+		switch {
+		case i == m.activeTab:
+			parts[i] = activeStyle.Render(text)
+		case tab.Err != "":
+			parts[i] = errStyle.Render(text)
+		default:
+			parts[i] = inactiveStyle.Render(text)
+		}
+		widths[i] = lipgloss.Width(text) + 1
+	}
+
+	// Scroll the bar so the active tab stays visible
+	start := 0
+	total := 0
+	for i := 0; i <= m.activeTab && i < len(widths); i++ {
+		total += widths[i]
+	}
+	for total > width && start < m.activeTab {
+		total -= widths[start]
+		start++
+	}
+
+	line := strings.Join(parts[start:], sep)
+	return lipgloss.NewStyle().Background(bg).Render(padToWidth(truncateToWidth(line, width), width))
+}
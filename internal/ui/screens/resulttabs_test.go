@@ -0,0 +1,92 @@
+package screens
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/jupiterozeye/tornado/internal/models"
+)
+
+// tabQueries lists the tabs' queries, the active one marked with *.
+func tabQueries(m *BrowserModel) []string {
+	var qs []string
+	for i, t := range m.resultTabs {
+		if i == m.activeTab {
+			qs = append(qs, "*"+t.Query)
+		} else {
+			qs = append(qs, t.Query)
+		}
+	}
+	return qs
+}
+
+// recordQueries records a result tab for each query, as if each had run.
+func recordQueries(m *BrowserModel, queries ...string) {
+	for _, q := range queries {
+		m.currentResults = &models.QueryResult{Columns: []string{"q"}, Rows: [][]any{{q}}}
+		m.recordResult(q, false)
+	}
+}
+
+func TestResultTabs_eviction(t *testing.T) {
+	m := NewBrowserModel(nil, models.ConnectionConfig{})
+	for i := range maxResultTabs + 2 {
+		recordQueries(m, fmt.Sprint("q", i))
+	}
+	qs := tabQueries(m)
+	if len(qs) != maxResultTabs || qs[0] != "q2" || qs[len(qs)-1] != fmt.Sprint("*q", maxResultTabs+1) {
+		t.Errorf("tabs = %v, want the last %d with the newest active", qs, maxResultTabs)
+	}
+}
+
+func TestResultTabs_pinnedSurviveEviction(t *testing.T) {
+	m := NewBrowserModel(nil, models.ConnectionConfig{})
+	recordQueries(m, "pinned")
+	m.togglePinResultTab()
+	for i := range maxResultTabs + 2 {
+		recordQueries(m, fmt.Sprint("q", i))
+	}
+	qs := tabQueries(m)
+	if len(qs) != maxResultTabs+1 || qs[0] != "pinned" || qs[1] != "q2" {
+		t.Errorf("tabs = %v, want the pinned tab and the last %d", qs, maxResultTabs)
+	}
+
+	// Unpinning counts it again; the oldest other tab goes, not the active one
+	m.switchResultTab(1)
+	m.togglePinResultTab()
+	if qs := tabQueries(m); len(qs) != maxResultTabs || qs[0] != "*pinned" || qs[1] != "q3" {
+		t.Errorf("after unpinning the active tab, tabs = %v", qs)
+	}
+}
+
+func TestResultTabs_closeAndSwitch(t *testing.T) {
+	m := NewBrowserModel(nil, models.ConnectionConfig{})
+	recordQueries(m, "a", "b", "c")
+
+	m.switchResultTab(1) // Wraps to the first
+	if m.activeTab != 0 || m.currentResults.Rows[0][0] != "a" {
+		t.Errorf("switch past the end: active %d, showing %v", m.activeTab, m.currentResults.Rows)
+	}
+	m.switchResultTab(-1) // Wraps to the last
+	if m.activeTab != 2 {
+		t.Errorf("switch before the start: active %d", m.activeTab)
+	}
+
+	m.switchResultTab(-1)
+	m.closeResultTab() // The middle one: its right neighbour moves up
+	if qs := tabQueries(m); fmt.Sprint(qs) != "[a *c]" || m.currentResults.Rows[0][0] != "c" {
+		t.Errorf("after closing b, tabs = %v, showing %v", qs, m.currentResults.Rows)
+	}
+	m.closeResultTab() // The last one: its left neighbour shows
+	if qs := tabQueries(m); fmt.Sprint(qs) != "[*a]" || m.currentResults.Rows[0][0] != "a" {
+		t.Errorf("after closing c, tabs = %v, showing %v", qs, m.currentResults.Rows)
+	}
+	m.closeResultTab() // The only one
+	if len(m.resultTabs) != 0 || m.activeTab != -1 || m.currentResults != nil {
+		t.Errorf("after closing every tab: %d tabs, active %d, results %v", len(m.resultTabs), m.activeTab, m.currentResults)
+	}
+	m.closeResultTab() // Nothing left to close
+	if m.activeTab != -1 {
+		t.Errorf("closing with no tabs: active %d", m.activeTab)
+	}
+}
@@ -0,0 +1,155 @@
+package screens
+
+import (
+	"strings"
+	"time"
+
+	tea "charm.land/bubbletea/v2"
+
+	"github.com/jupiterozeye/tornado/internal/sqlparse"
+)
+
+// schemaPollInterval is how often the schema version is checked for
+// changes made by other connections.
+const schemaPollInterval = 2 * time.Second
+
+// schemaPollMsg fires when the schema version is due to be checked.
+type schemaPollMsg struct{}
+
+// SchemaVersionMsg carries the schema version read by a poll.
+type SchemaVersionMsg struct {
+	Version int64
+	Err     error
+}
+
+// explorerCategories maps DDL object kinds to the explorer categories that
+// list them. Dropping or renaming a table also affects its indexes and
+// triggers.
+var explorerCategories = map[string][]string{
+	"TABLE":    {"Tables", "Indexes", "Triggers"},
+	"VIEW":     {"Views"},
+	"INDEX":    {"Indexes"},
+	"TRIGGER":  {"Triggers"},
+	"SEQUENCE": {"Sequences"},
+}
+
+// pollSchemaVersion schedules the next schema version check.
+func (m *BrowserModel) pollSchemaVersion() tea.Cmd {
+	return tea.Tick(schemaPollInterval, func(time.Time) tea.Msg {
+		return schemaPollMsg{}
+	})
+}
+
+// checkSchemaVersion reads the schema version in the background. Polling
+// stops once the screen has been closed.
+func (m *BrowserModel) checkSchemaVersion() tea.Cmd {
+	if m.db == nil || m.ctx.Err() != nil {
+		return nil
+	}
+	database := m.db
+	return func() tea.Msg {
+		version, err := database.SchemaVersion()
+		return SchemaVersionMsg{Version: version, Err: err}
+	}
+}
+
+// handleSchemaVersion reloads everything when another connection changed
+// the schema, then keeps polling.
+func (m *BrowserModel) handleSchemaVersion(msg SchemaVersionMsg) tea.Cmd {
+	cmds := []tea.Cmd{m.pollSchemaVersion()}
+	if msg.Err == nil && msg.Version != m.schemaVersion {
+		m.schemaVersion = msg.Version
+		cmds = append(cmds, m.loadSchemaCmd(nil))
+		if m.explorer != nil {
+			cmds = append(cmds, m.explorer.Refresh(nil, nil))
+		}
+	}
+	return tea.Batch(cmds...)
+}
+
+// refreshSchema reloads the explorer nodes and schema cache entries touched
+// by DDL the user ran. Changes to other kinds of objects (schemas, types,
+// extensions, ...) reload everything that is loaded.
+func (m *BrowserModel) refreshSchema(changes []sqlparse.SchemaChange) tea.Cmd {
+	if len(changes) == 0 {
+		return nil
+	}
+
+	var categories, tables []string
+	full := false
+	for _, c := range changes {
+		cats, ok := explorerCategories[c.Kind]
+		if !ok {
+			full = true
+			break
+		}
+		categories = append(categories, cats...)
+		if c.Kind == "TABLE" {
+			tables = append(tables, c.Names...)
+		}
+	}
+
+	var cmds []tea.Cmd
+	switch {
+	case full:
+		cmds = append(cmds, m.loadSchemaCmd(nil))
+		categories, tables = nil, nil
+	case len(tables) > 0:
+		cmds = append(cmds, m.loadSchemaCmd(tables))
+	default:
+		// The explorer lists nothing to re-describe
+		tables = []string{}
+	}
+	if m.explorer != nil {
+		cmds = append(cmds, m.explorer.Refresh(categories, tables))
+	}
+	return tea.Batch(cmds...)
+}
+
+// applySchema stores a loaded schema. A partial load replaces only the
+// tables it described and drops tables that no longer exist.
+func (m *BrowserModel) applySchema(msg SchemaLoadedMsg) {
+	m.schemaVersion = msg.Version
+	if !msg.Partial {
+		m.tables = msg.Tables
+		m.columns = msg.Columns
+		m.foreignKeys = msg.ForeignKeys
+		return
+	}
+
+	exists := make(map[string]bool, len(msg.Tables))
+	for _, table := range msg.Tables {
+		exists[table] = true
+	}
+	columns := make(map[string][]string, len(msg.Tables))
+	for table, cols := range m.columns {
+		if exists[table] {
+			columns[table] = cols
+		}
+	}
+	for table, cols := range msg.Columns {
+		columns[table] = cols
+	}
+
+	var foreignKeys []ForeignKey
+	for _, fk := range m.foreignKeys {
+		if _, described := msg.Columns[fk.Table]; exists[fk.Table] && !described {
+			foreignKeys = append(foreignKeys, fk)
+		}
+	}
+
+	m.tables = msg.Tables
+	m.columns = columns
+	m.foreignKeys = append(foreignKeys, msg.ForeignKeys...)
+}
+
+// containsTable reports whether names includes table. SQLite and unquoted
+// Postgres names are case-insensitive, so DDL may spell a table differently.
+func containsTable(names []string, table string) bool {
+	for _, name := range names {
+		if strings.EqualFold(name, table) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,190 @@
+package screens
+
+import (
+	"encoding/csv"
+	"fmt"
+	"slices"
+	"strings"
+
+	tea "charm.land/bubbletea/v2"
+
+	"github.com/jupiterozeye/tornado/internal/models"
+)
+
+// cellSelection is a visual block in the results grid, from an anchor cell
+// to the cursor. Rows index the shown rows; columns are result columns, and
+// the block spans the columns between them in display order.
+type cellSelection struct {
+	row, col int
+}
+
+// cellBlock is a selection resolved against the grid.
+type cellBlock struct {
+	firstRow, lastRow int
+	cols              []int // Result columns, in display order
+}
+
+// contains reports whether the block covers the cell.
+func (b *cellBlock) contains(row, col int) bool {
+	return b != nil && row >= b.firstRow && row <= b.lastRow && slices.Contains(b.cols, col)
+}
+
+// blockStats summarises the numeric cells of a block.
+type blockStats struct {
+	Cells    int // Every cell, numeric or not
+	Count    int // Numeric cells
+	Distinct int
+	Sum      float64
+	Min, Max float64
+}
+
+// toggleSelection starts a visual block at the cursor cell, or ends it.
+func (m *BrowserModel) toggleSelection() {
+	if m.selection != nil {
+		m.selection = nil
+		m.statusMsg = ""
+		return
+	}
+	active := m.activeResultSet()
+	if active == nil || len(active.Rows) == 0 || len(m.visibleColumns()) == 0 {
+		m.statusMsg = "No cells to select"
+		return
+	}
+	if m.results.Cursor() < 0 {
+		m.results.SetCursor(0)
+	}
+	m.selection = &cellSelection{row: m.results.Cursor(), col: m.resultsCursorCol}
+	m.statusMsg = ""
+}
+
+// selectedBlock resolves the selection against the grid, or returns nil when
+// there is none.
+func (m *BrowserModel) selectedBlock() *cellBlock {
+	active := m.activeResultSet()
+	if m.selection == nil || active == nil {
+		return nil
+	}
+	order := m.visibleColumns()
+	from := slices.Index(order, m.selection.col)
+	to := slices.Index(order, m.resultsCursorCol)
+	if from < 0 || to < 0 {
+		return nil // The anchor column was hidden
+	}
+	cursor := max(m.results.Cursor(), 0)
+	anchor := min(m.selection.row, len(active.Rows)-1)
+	return &cellBlock{
+		firstRow: min(anchor, cursor),
+		lastRow:  max(anchor, cursor),
+		cols:     order[min(from, to) : max(from, to)+1],
+	}
+}
+
+// blockSummary computes the statistics of a block's numeric cells. Text
+// that reads as a number counts when its column holds numbers.
+func blockSummary(r *models.QueryResult, b *cellBlock) blockStats {
+	var s blockStats
+	seen := map[float64]bool{}
+	for _, row := range r.Rows[b.firstRow : b.lastRow+1] {
+		for _, col := range b.cols {
+			s.Cells++
+			if col >= len(row) || row[col] == nil {
+				continue
+			}
+			n, ok := numericValue(row[col])
+			if !ok && isNumericType(columnType(r, col)) {
+				n, ok = parseNumber(cellText(row[col], columnType(r, col)))
+			}
+			if !ok {
+				continue
+			}
+			if s.Count == 0 || n < s.Min {
+				s.Min = n
+			}
+			if s.Count == 0 || n > s.Max {
+				s.Max = n
+			}
+			s.Count++
+			s.Sum += n
+			seen[n] = true
+		}
+	}
+	s.Distinct = len(seen)
+	return s
+}
+
+// selectionStatus is the footer text while a block is selected.
+func (m *BrowserModel) selectionStatus() string {
+	b := m.selectedBlock()
+	if b == nil {
+		return "Block: h/l/j/k extend  y Copy  esc Cancel"
+	}
+	s := blockSummary(m.activeResultSet(), b)
+	text := fmt.Sprintf("Block %d×%d", b.lastRow-b.firstRow+1, len(b.cols))
+	if s.Count > 0 {
+		text += fmt.Sprintf("  sum %s  avg %s  min %s  max %s  count %d  distinct %d",
+			formatStat(s.Sum), formatStat(s.Sum/float64(s.Count)), formatStat(s.Min), formatStat(s.Max), s.Count, s.Distinct)
+	} else {
+		text += "  no numbers"
+	}
+	return text + "  |  y Copy  esc Cancel"
+}
+
+// formatBlock writes a block's cells, under a header of its column names, as
+// tsv, csv or markdown. NULLs are empty except in markdown.
+func formatBlock(r *models.QueryResult, b *cellBlock, format string) string {
+	header := make([]string, len(b.cols))
+	for i, col := range b.cols {
+		header[i] = r.Columns[col]
+	}
+	records := [][]string{header}
+	for _, row := range r.Rows[b.firstRow : b.lastRow+1] {
+		record := make([]string, len(b.cols))
+		for i, col := range b.cols {
+			if col < len(row) && (row[col] != nil || format == "markdown") {
+				record[i] = cellText(row[col], columnType(r, col))
+			}
+		}
+		records = append(records, record)
+	}
+
+	var out strings.Builder
+	switch format {
+	case "csv":
+		w := csv.NewWriter(&out)
+		w.WriteAll(records)
+	case "markdown":
+		for i, record := range records {
+			for j, cell := range record {
+				cell = strings.ReplaceAll(cell, "|", `\|`)
+				record[j] = strings.ReplaceAll(strings.ReplaceAll(cell, "\r\n", "<br>"), "\n", "<br>")
+			}
+			out.WriteString("| " + strings.Join(record, " | ") + " |\n")
+			if i == 0 {
+				out.WriteString("|" + strings.Repeat(" --- |", len(record)) + "\n")
+			}
+		}
+	default:
+		for _, record := range records {
+			out.WriteString(strings.Join(record, "\t") + "\n")
+		}
+	}
+	return strings.TrimSuffix(out.String(), "\n")
+}
+
+// copySelection copies the selected block in the given format and ends the
+// selection.
+func (m *BrowserModel) copySelection(format string) (tea.Model, tea.Cmd) {
+	b := m.selectedBlock()
+	if b == nil {
+		m.statusMsg = "No cells selected"
+		return m, nil
+	}
+	value := formatBlock(m.activeResultSet(), b, format)
+	m.setUnnamedRegister(value)
+	if !m.writeClipboard(value) {
+		return m, nil
+	}
+	m.selection = nil
+	m.statusMsg = fmt.Sprintf("Copied %d×%d block as %s", b.lastRow-b.firstRow+1, len(b.cols), format)
+	return m, nil
+}
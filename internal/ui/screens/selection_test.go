@@ -0,0 +1,38 @@
+package screens
+
+import (
+	"testing"
+
+	"github.com/jupiterozeye/tornado/internal/models"
+)
+
+func TestBlock(t *testing.T) {
+	r := &models.QueryResult{
+		Columns: []string{"id", "qty", "note"},
+		Rows: [][]any{
+			{int64(1), int64(5), "a|b"},
+			{int64(2), nil, "c"},
+			{int64(3), 2.5, nil},
+		},
+	}
+	b := &cellBlock{firstRow: 0, lastRow: 2, cols: []int{1, 2}}
+
+	s := blockSummary(r, b)
+	if s.Cells != 6 || s.Count != 2 || s.Distinct != 2 || s.Sum != 7.5 || s.Min != 2.5 || s.Max != 5 {
+		t.Errorf("blockSummary = %+v", s)
+	}
+
+	tests := []struct {
+		format string
+		want   string
+	}{
+		{"tsv", "qty\tnote\n5\ta|b\n\tc\n2.5\t"},
+		{"csv", "qty,note\n5,a|b\n,c\n2.5,"},
+		{"markdown", "| qty | note |\n| --- | --- |\n| 5 | a\\|b |\n| NULL | c |\n| 2.5 | NULL |"},
+	}
+	for _, tt := range tests {
+		if got := formatBlock(r, b, tt.format); got != tt.want {
+			t.Errorf("formatBlock(%s) =\n%s\nwant\n%s", tt.format, got, tt.want)
+		}
+	}
+}
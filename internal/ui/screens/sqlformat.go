@@ -0,0 +1,51 @@
+package screens
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/jupiterozeye/tornado/internal/config"
+	"github.com/jupiterozeye/tornado/internal/sqlparse"
+)
+
+// formatOptions returns the SQL formatter options from the config file.
+func (m *BrowserModel) formatOptions() sqlparse.FormatOptions {
+	opts := sqlparse.FormatOptions{KeywordCase: "upper", Indent: 2, AlignColumns: true, Dialect: m.dialect}
+	if cfg := config.Get(); cfg != nil {
+		f := cfg.GetFormatter()
+		opts.KeywordCase = f.KeywordCase
+		opts.Indent = f.Indent
+		opts.AlignColumns = f.AlignColumns
+	}
+	return opts
+}
+
+// formatQuery pretty-prints the visual selection, or the whole buffer
+// outside visual mode.
+func (m *BrowserModel) formatQuery() {
+	text := m.query.Value()
+	start, end := 0, len(text)
+	if m.queryMode == QueryModeVisual || m.queryMode == QueryModeVisualLine {
+		var ok bool
+		if start, end, ok = m.selectedQueryRange(); !ok || end > len(text) {
+			m.statusMsg = "Nothing selected to format"
+			return
+		}
+	}
+
+	original := text[start:end]
+	formatted := sqlparse.Format(original, m.formatOptions())
+	// Keep the selection's trailing newline, if any
+	if strings.HasSuffix(original, "\n") {
+		formatted += "\n"
+	}
+	if formatted == original {
+		m.statusMsg = "Already formatted"
+		return
+	}
+
+	newText := text[:start] + formatted + text[end:]
+	m.query.SetValue(newText)
+	m.setQueryCursor(indexToLineCol(newText, start))
+	m.statusMsg = fmt.Sprintf("Formatted %d line(s)", strings.Count(formatted, "\n")+1)
+}
@@ -0,0 +1,259 @@
+package screens
+
+import (
+	"fmt"
+	"math"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// defaultTableLimit is the row limit used when a table is opened from the explorer.
+const defaultTableLimit = 100
+
+// sortKey is a single ORDER BY term.
+type sortKey struct {
+	Column string
+	Desc   bool
+}
+
+// filterCond is a single WHERE term built from the structured filter bar.
+type filterCond struct {
+	Column string
+	Op     string // Upper-cased SQL operator (=, >, LIKE, IS NULL, ...)
+	Value  string // Already rendered as a SQL literal; empty for IS [NOT] NULL
+}
+
+// tableQuery describes a SELECT generated for a table opened from the explorer.
+// Sorting and filtering rewrite this query so they apply to the whole table
+// rather than only the rows that were already fetched.
+type tableQuery struct {
+	Table      string
+	Sort       []sortKey
+	Filters    []filterCond
+	FilterText string // Raw text the filters were parsed from, for re-editing
+	Limit      int
+}
+
+func newTableQuery(table string) *tableQuery {
+	return &tableQuery{Table: table, Limit: defaultTableLimit}
+}
+
+// SQL renders the query. With no sort or filter it matches the plain
+// "SELECT * FROM t LIMIT 100;" the explorer has always generated.
+func (t *tableQuery) SQL() string {
+	var b strings.Builder
+	b.WriteString("SELECT * FROM ")
+	b.WriteString(quoteIdent(t.Table))
+	b.WriteString(t.where())
+
+	if len(t.Sort) > 0 {
+		terms := make([]string, len(t.Sort))
+		for i, s := range t.Sort {
+			dir := "ASC"
+			if s.Desc {
+				dir = "DESC"
+			}
+			terms[i] = quoteIdent(s.Column) + " " + dir
+		}
+		b.WriteString(" ORDER BY ")
+		b.WriteString(strings.Join(terms, ", "))
+	}
+
+	if t.Limit > 0 {
+		fmt.Fprintf(&b, " LIMIT %d", t.Limit)
+	}
+	b.WriteString(";")
+	return b.String()
+}
+
+// where renders the filters, and any extra conditions, as a WHERE clause
+// with a leading space, or "" when there are none.
+func (t *tableQuery) where(extra ...string) string {
+	conds := make([]string, 0, len(t.Filters)+len(extra))
+	for _, f := range t.Filters {
+		conds = append(conds, f.SQL())
+	}
+	conds = append(conds, extra...)
+	if len(conds) == 0 {
+		return ""
+	}
+	return " WHERE " + strings.Join(conds, " AND ")
+}
+
+// SQL renders a filter condition as a WHERE term.
+func (f filterCond) SQL() string {
+	if f.Value == "" {
+		return quoteIdent(f.Column) + " " + f.Op
+	}
+	return quoteIdent(f.Column) + " " + f.Op + " " + f.Value
+}
+
+// toggleSort cycles a column through ascending, descending and unsorted.
+// When additive is false the column becomes the only sort key; otherwise it is
+// appended to (or updated in) the existing multi-column ordering.
+func (t *tableQuery) toggleSort(column string, additive bool) {
+	idx := -1
+	for i, s := range t.Sort {
+		if s.Column == column {
+			idx = i
+			break
+		}
+	}
+
+	if !additive {
+		switch {
+		case idx == -1 || len(t.Sort) > 1:
+			t.Sort = []sortKey{{Column: column}}
+		case !t.Sort[idx].Desc:
+			t.Sort = []sortKey{{Column: column, Desc: true}}
+		default:
+			t.Sort = nil
+		}
+		return
+	}
+
+	switch {
+	case idx == -1:
+		t.Sort = append(t.Sort, sortKey{Column: column})
+	case !t.Sort[idx].Desc:
+		t.Sort[idx].Desc = true
+	default:
+		t.Sort = append(t.Sort[:idx], t.Sort[idx+1:]...)
+	}
+}
+
+// sortIndicator returns the header marker for a column, e.g. "▲" or "▼2".
+func (t *tableQuery) sortIndicator(column string) string {
+	for i, s := range t.Sort {
+		if s.Column != column {
+			continue
+		}
+		arrow := "▲"
+		if s.Desc {
+			arrow = "▼"
+		}
+		if len(t.Sort) > 1 {
+			return fmt.Sprintf("%s%d", arrow, i+1)
+		}
+		return arrow
+	}
+	return ""
+}
+
+var filterCondRe = regexp.MustCompile(`(?i)^\s*(?:("[^"]+"|[A-Za-z_][A-Za-z0-9_]*)\s*)??` +
+	`(is\s+not\s+null|is\s+null|not\s+like|not\s+ilike|ilike|like|<>|!=|>=|<=|=|<|>)\s*(.*?)\s*$`)
+
+// quoteIdent double-quotes an identifier. Plain words are quoted too: they
+// may be reserved, like "order", and Postgres folds unquoted names to lower
+// case.
+func quoteIdent(name string) string {
+	return `"` + strings.ReplaceAll(name, `"`, `""`) + `"`
+}
+
+// parseFilterExpr parses filter bar input such as "age > 30",
+// "name like %ann%" or "is null". Conditions may be joined with "and".
+// A condition without a column applies to defaultColumn. Column names are
+// resolved case-insensitively against columns.
+func parseFilterExpr(expr string, columns []string, defaultColumn string) ([]filterCond, error) {
+	expr = strings.TrimSpace(expr)
+	if expr == "" {
+		return nil, nil
+	}
+
+	var conds []filterCond
+	for _, part := range splitFilterConds(expr) {
+		m := filterCondRe.FindStringSubmatch(part)
+		if m == nil {
+			return nil, fmt.Errorf("cannot parse filter %q (expected: column op value)", strings.TrimSpace(part))
+		}
+
+		column := defaultColumn
+		if m[1] != "" {
+			name := strings.Trim(m[1], `"`)
+			column = ""
+			for _, c := range columns {
+				if strings.EqualFold(c, name) {
+					column = c
+					break
+				}
+			}
+			if column == "" {
+				return nil, fmt.Errorf("unknown column %q", name)
+			}
+		}
+		if column == "" {
+			return nil, fmt.Errorf("no column for filter %q", strings.TrimSpace(part))
+		}
+
+		op := strings.ToUpper(strings.Join(strings.Fields(m[2]), " "))
+		value := m[3]
+		if op == "IS NULL" || op == "IS NOT NULL" {
+			if value != "" {
+				return nil, fmt.Errorf("unexpected value after %s", op)
+			}
+			conds = append(conds, filterCond{Column: column, Op: op})
+			continue
+		}
+		if value == "" {
+			return nil, fmt.Errorf("missing value for %s %s", column, op)
+		}
+		if op == "!=" {
+			op = "<>"
+		}
+		conds = append(conds, filterCond{Column: column, Op: op, Value: sqlLiteral(value)})
+	}
+	return conds, nil
+}
+
+// splitFilterConds splits filter input on the keyword "and", ignoring any
+// "and" inside single-quoted values.
+func splitFilterConds(expr string) []string {
+	var parts []string
+	inQuote := false
+	start := 0
+	lower := strings.ToLower(expr)
+	for i := 0; i < len(expr); i++ {
+		switch {
+		case expr[i] == '\'':
+			inQuote = !inQuote
+		case !inQuote && i > start && isSpaceByte(expr[i-1]) &&
+			strings.HasPrefix(lower[i:], "and") && i+3 < len(expr) && isSpaceByte(expr[i+3]):
+			parts = append(parts, expr[start:i])
+			start = i + 3
+			i += 2
+		}
+	}
+	return append(parts, expr[start:])
+}
+
+func isSpaceByte(ch byte) bool {
+	return ch == ' ' || ch == '\t' || ch == '\n'
+}
+
+// sqlLiteral renders filter input as a SQL literal. Finite decimal numbers
+// are passed through; anything else is quoted. Input already in quotes has
+// them stripped and its content escaped again, so a stray quote inside cannot
+// end the literal early.
+func sqlLiteral(value string) string {
+	if strings.EqualFold(value, "null") || strings.EqualFold(value, "true") || strings.EqualFold(value, "false") {
+		return strings.ToUpper(value)
+	}
+	if isNumberLiteral(value) {
+		return value
+	}
+	if len(value) >= 2 && value[0] == '\'' && value[len(value)-1] == '\'' {
+		value = strings.ReplaceAll(value[1:len(value)-1], "''", "'")
+	}
+	return "'" + strings.ReplaceAll(value, "'", "''") + "'"
+}
+
+// isNumberLiteral reports whether s is a finite decimal number SQL reads as
+// one: no NaN, Inf, hex or digit separators, which ParseFloat also accepts.
+func isNumberLiteral(s string) bool {
+	f, err := strconv.ParseFloat(s, 64)
+	if err != nil || math.IsInf(f, 0) || math.IsNaN(f) {
+		return false
+	}
+	return strings.Trim(s, "0123456789+-.eE") == ""
+}
@@ -0,0 +1,100 @@
+package screens
+
+import "testing"
+
+func TestTableQuery_SQL(t *testing.T) {
+	q := newTableQuery("users")
+	if got, want := q.SQL(), `SELECT * FROM "users" LIMIT 100;`; got != want {
+		t.Errorf("SQL() = %q, want %q", got, want)
+	}
+
+	q.toggleSort("age", false)
+	q.toggleSort("name", true)
+	q.toggleSort("name", true)
+	q.Filters = []filterCond{{Column: "age", Op: ">", Value: "30"}}
+	want := `SELECT * FROM "users" WHERE "age" > 30 ORDER BY "age" ASC, "name" DESC LIMIT 100;`
+	if got := q.SQL(); got != want {
+		t.Errorf("SQL() = %q, want %q", got, want)
+	}
+
+	q = newTableQuery("order items")
+	if got, want := q.SQL(), `SELECT * FROM "order items" LIMIT 100;`; got != want {
+		t.Errorf("SQL() = %q, want %q", got, want)
+	}
+
+	// Reserved words and embedded quotes
+	q = newTableQuery("order")
+	q.toggleSort("group", false)
+	q.Filters = []filterCond{{Column: `say "hi"`, Op: "IS NULL"}}
+	if got, want := q.SQL(), `SELECT * FROM "order" WHERE "say ""hi""" IS NULL ORDER BY "group" ASC LIMIT 100;`; got != want {
+		t.Errorf("SQL() = %q, want %q", got, want)
+	}
+}
+
+func TestTableQuery_toggleSort(t *testing.T) {
+	q := newTableQuery("users")
+
+	q.toggleSort("age", false)
+	if len(q.Sort) != 1 || q.Sort[0].Desc {
+		t.Fatalf("first toggle should sort ascending, got %+v", q.Sort)
+	}
+	q.toggleSort("age", false)
+	if len(q.Sort) != 1 || !q.Sort[0].Desc {
+		t.Fatalf("second toggle should sort descending, got %+v", q.Sort)
+	}
+	q.toggleSort("age", false)
+	if len(q.Sort) != 0 {
+		t.Fatalf("third toggle should clear sort, got %+v", q.Sort)
+	}
+
+	q.toggleSort("age", false)
+	q.toggleSort("name", false)
+	if len(q.Sort) != 1 || q.Sort[0].Column != "name" {
+		t.Errorf("non-additive sort should replace keys, got %+v", q.Sort)
+	}
+}
+
+func TestParseFilterExpr(t *testing.T) {
+	columns := []string{"id", "name", "Age", "deleted_at"}
+
+	tests := []struct {
+		expr string
+		want string
+	}{
+		{"age > 30", `"Age" > 30`},
+		{"name like %ann%", `"name" LIKE '%ann%'`},
+		{"is null", `"deleted_at" IS NULL`},
+		{"name != 'O''Brien'", `"name" <> 'O''Brien'`},
+		{"id >= 2 AND name = tom", `"id" >= 2 AND "name" = 'tom'`},
+		{"name = 'tom and jerry'", `"name" = 'tom and jerry'`},
+		{"age > -1.5e3", `"Age" > -1.5e3`},
+		// Quoted input is escaped again rather than pasted in
+		{"name = 'x' OR 1=1 --'", `"name" = 'x'' OR 1=1 --'`},
+		{"name = 'it's'", `"name" = 'it''s'`},
+		// Non-finite and hex numbers are strings, not bare identifiers
+		{"name = NaN", `"name" = 'NaN'`},
+		{"age = Inf", `"Age" = 'Inf'`},
+		{"age = 0x10", `"Age" = '0x10'`},
+	}
+
+	for _, tt := range tests {
+		conds, err := parseFilterExpr(tt.expr, columns, "deleted_at")
+		if err != nil {
+			t.Errorf("parseFilterExpr(%q) error: %v", tt.expr, err)
+			continue
+		}
+		q := &tableQuery{Table: "t", Filters: conds}
+		got := q.SQL()
+		want := `SELECT * FROM "t" WHERE ` + tt.want + ";"
+		if got != want {
+			t.Errorf("parseFilterExpr(%q) = %q, want %q", tt.expr, got, want)
+		}
+	}
+
+	if _, err := parseFilterExpr("missing = 1", columns, "id"); err == nil {
+		t.Error("unknown column should be rejected")
+	}
+	if _, err := parseFilterExpr("id = 1 and name", columns, "id"); err == nil {
+		t.Error("condition without operator should be rejected")
+	}
+}
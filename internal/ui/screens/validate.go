@@ -0,0 +1,148 @@
+package screens
+
+import (
+	"errors"
+	"fmt"
+	"time"
+
+	tea "charm.land/bubbletea/v2"
+	"charm.land/lipgloss/v2"
+
+	"github.com/jupiterozeye/tornado/internal/db"
+	"github.com/jupiterozeye/tornado/internal/ui/components"
+	"github.com/jupiterozeye/tornado/internal/ui/styles"
+)
+
+// validateDelay is how long the buffer must stay unchanged before it is
+// checked against the database.
+const validateDelay = 500 * time.Millisecond
+
+// validateTickMsg fires once editing has paused on Text.
+type validateTickMsg struct {
+	Text string
+}
+
+// QueryValidatedMsg carries the result of compiling Text without running it.
+type QueryValidatedMsg struct {
+	Text string
+	Err  error
+}
+
+// scheduleValidation checks the buffer after a pause in editing.
+func (m *BrowserModel) scheduleValidation() tea.Cmd {
+	text := m.query.Value()
+	return tea.Tick(validateDelay, func(time.Time) tea.Msg {
+		return validateTickMsg{Text: text}
+	})
+}
+
+// validateCmd compiles the buffer in the background if it is still current.
+func (m *BrowserModel) validateCmd(text string) tea.Cmd {
+	if text != m.query.Value() || m.db == nil {
+		return nil
+	}
+	if text == "" {
+		m.validation, m.validationText = nil, ""
+		return nil
+	}
+	database := m.db
+	return func() tea.Msg {
+		return QueryValidatedMsg{Text: text, Err: database.Validate(text)}
+	}
+}
+
+// handleValidated stores a validation result for the text it was made for.
+// Connection problems are not shown as errors in the SQL.
+func (m *BrowserModel) handleValidated(msg QueryValidatedMsg) {
+	if msg.Text != m.query.Value() {
+		return
+	}
+	var ve *db.ValidationError
+	if !errors.As(msg.Err, &ve) {
+		ve = nil
+	}
+	m.validation, m.validationText = ve, msg.Text
+}
+
+// currentValidation returns the validation error for the buffer as it is
+// now, or nil once it has been edited since the last check.
+func (m *BrowserModel) currentValidation() *db.ValidationError {
+	if m.validation == nil || m.validationText != m.query.Value() {
+		return nil
+	}
+	return m.validation
+}
+
+// validationFooter describes the current validation error as "line:col message".
+func (m *BrowserModel) validationFooter() string {
+	ve := m.currentValidation()
+	if ve == nil {
+		return ""
+	}
+	if ve.Offset < 0 {
+		return "✗ " + ve.Message
+	}
+	// Count the column in runes, as the editor and the mark do
+	text := m.validationText
+	offset := min(ve.Offset, len(text))
+	row, col := indexToLineCol(text, offset)
+	col = len([]rune(text[offset-col : offset]))
+	return fmt.Sprintf("✗ %d:%d %s", row+1, col+1, ve.Message)
+}
+
+// validationMark returns the line and rune columns to underline for the
+// current validation error. An error spanning lines is cut at the line end.
+func (m *BrowserModel) validationMark() (row, from, to int) {
+	ve := m.currentValidation()
+	if ve == nil || ve.Offset < 0 || ve.Offset > len(m.validationText) {
+		return -1, 0, 0
+	}
+	text := m.validationText
+	end := ve.Offset + ve.Length
+	if end > len(text) {
+		end = len(text)
+	}
+	row, startCol := indexToLineCol(text, ve.Offset)
+	lineStart := ve.Offset - startCol
+	lineEnd := len(text)
+	for i := ve.Offset; i < len(text); i++ {
+		if text[i] == '\n' {
+			lineEnd = i
+			break
+		}
+	}
+	if end > lineEnd {
+		end = lineEnd
+	}
+	from = len([]rune(text[lineStart:ve.Offset]))
+	to = from + len([]rune(text[ve.Offset:end]))
+	if to == from {
+		to = from + 1 // Underline the position of an empty span
+	}
+	return row, from, to
+}
+
+// highlightWithMark highlights runes[from:to] like components.HighlightSQL,
+// drawing the part inside [markFrom, markTo) as an error underline.
+func highlightWithMark(runes []rune, from, to int, inBlockComment bool, markFrom, markTo int) (string, bool) {
+	start, end := max(from, markFrom), min(to, markTo)
+	if start >= end {
+		return components.HighlightSQL(string(runes[from:to]), inBlockComment)
+	}
+
+	markStyle := lipgloss.NewStyle().Foreground(styles.Error).Underline(true)
+	var out, seg string
+	bc := inBlockComment
+	if start > from {
+		seg, bc = components.HighlightSQL(string(runes[from:start]), bc)
+		out += seg
+	}
+	marked := string(runes[start:end])
+	out += markStyle.Render(marked)
+	_, bc = components.HighlightSQL(marked, bc)
+	if end < to {
+		seg, bc = components.HighlightSQL(string(runes[end:to]), bc)
+		out += seg
+	}
+	return out, bc
+}
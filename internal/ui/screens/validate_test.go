@@ -0,0 +1,20 @@
+package screens
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/jupiterozeye/tornado/internal/db"
+	"github.com/jupiterozeye/tornado/internal/models"
+)
+
+func TestValidationFooter_countsRunes(t *testing.T) {
+	m := NewBrowserModel(nil, models.ConnectionConfig{})
+	text := "SELECT 1;\nSELECT 'héllo wörld' FORM t"
+	m.query.SetValue(text)
+	m.validationText = text
+	m.validation = &db.ValidationError{Offset: strings.Index(text, "FORM"), Message: "syntax error"}
+	if got, want := m.validationFooter(), "✗ 2:22 syntax error"; got != want {
+		t.Errorf("validationFooter() = %q, want %q", got, want)
+	}
+}
@@ -0,0 +1,1109 @@
+package screens
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+
+	tea "charm.land/bubbletea/v2"
+	"github.com/atotto/clipboard"
+
+	"github.com/jupiterozeye/tornado/internal/sqlparse"
+)
+
+// vimState is the modal editing state of the query editor: the command
+// being typed in NORMAL or VISUAL mode, registers, the last search and the
+// last change for dot-repeat.
+type vimState struct {
+	count    int    // count typed before the command, 0 when none
+	opCount  int    // count typed after the operator
+	register rune   // register named with ", 0 for the unnamed one
+	operator string // pending operator: d c y > < = g~ gu gU
+	pending  string // prefix waiting for its argument: g f t F T r i a "
+
+	keys       []tea.KeyPressMsg // keys of the command being typed
+	lastChange vimChange
+	inserting  bool // the change continues in INSERT mode until esc
+	replaying  bool
+
+	// Text before the current INSERT session and where it started, to
+	// record what was typed for dot-repeat
+	insertBase  string
+	insertStart int
+
+	lastFind string // last f/t/F/T and its character, e.g. "t,"
+
+	searching      bool // "/" or "?" prompt is open
+	searchInput    string
+	searchBackward bool
+	searchPattern  string
+
+	registers map[rune]string
+}
+
+// vimChange is a repeatable change: the keys that started it and the text
+// typed if it entered INSERT mode.
+type vimChange struct {
+	keys   []tea.KeyPressMsg
+	insert string
+}
+
+// isPending reports whether a command is partly typed, so the next key
+// belongs to the editor even if it is normally a global shortcut.
+func (v *vimState) isPending() bool {
+	return v.count > 0 || v.opCount > 0 || v.register != 0 || v.operator != "" || v.pending != ""
+}
+
+// done ends the current command without recording it as a change.
+func (v *vimState) done() {
+	v.count, v.opCount, v.register = 0, 0, 0
+	v.operator, v.pending = "", ""
+	v.keys = nil
+}
+
+// changed ends the current command and remembers it for dot-repeat.
+func (v *vimState) changed() {
+	if !v.replaying && len(v.keys) > 0 {
+		v.lastChange = vimChange{keys: v.keys}
+	}
+	v.done()
+}
+
+// totalCount returns the command's count, multiplying counts typed before
+// and after an operator as vim does (2d3w deletes six words).
+func (v *vimState) totalCount() int {
+	return max(v.count, 1) * max(v.opCount, 1)
+}
+
+// hasCount reports whether any count was typed.
+func (v *vimState) hasCount() bool {
+	return v.count > 0 || v.opCount > 0
+}
+
+// describe renders the partly typed command for the footer.
+func (v *vimState) describe() string {
+	var b strings.Builder
+	if v.register != 0 {
+		b.WriteString(`"` + string(v.register))
+	}
+	if v.count > 0 {
+		b.WriteString(strconv.Itoa(v.count))
+	}
+	b.WriteString(v.operator)
+	if v.opCount > 0 {
+		b.WriteString(strconv.Itoa(v.opCount))
+	}
+	b.WriteString(v.pending)
+	return b.String()
+}
+
+// register returns the contents of register r; 0 means the unnamed one.
+func (m *BrowserModel) register(r rune) string {
+	switch {
+	case r == 0:
+		r = '"'
+	case r == '+' || r == '*':
+		text, err := clipboard.ReadAll()
+		if err != nil {
+			m.statusMsg = "Clipboard failed: " + err.Error()
+			return ""
+		}
+		return text
+	case r >= 'A' && r <= 'Z':
+		r = unicode.ToLower(r)
+	}
+	return m.vim.registers[r]
+}
+
+// storeRegister saves yanked or deleted text in the selected register and
+// the unnamed one. Yanks also go to "0; deletes of whole or several lines
+// shift through "1-"9 and smaller deletes go to "-. Uppercase names append.
+func (m *BrowserModel) storeRegister(text string, yank bool) {
+	v := &m.vim
+	if v.registers == nil {
+		v.registers = make(map[rune]string)
+	}
+	switch r := v.register; {
+	case r == '_':
+		return
+	case r == '+' || r == '*':
+		m.writeClipboard(text)
+	case r >= 'a' && r <= 'z':
+		v.registers[r] = text
+	case r >= 'A' && r <= 'Z':
+		text = v.registers[unicode.ToLower(r)] + text
+		v.registers[unicode.ToLower(r)] = text
+	case yank:
+		v.registers['0'] = text
+	case strings.Contains(text, "\n"):
+		for i := '9'; i > '1'; i-- {
+			v.registers[i] = v.registers[i-1]
+		}
+		v.registers['1'] = text
+	default:
+		v.registers['-'] = text
+	}
+	v.registers['"'] = text
+}
+
+// setUnnamedRegister makes value available to p and P, for copies made
+// outside the editor.
+func (m *BrowserModel) setUnnamedRegister(value string) {
+	if m.vim.registers == nil {
+		m.vim.registers = make(map[rune]string)
+	}
+	m.vim.registers['"'] = value
+}
+
+// cursorIndex returns the cursor as a byte offset into the editor text.
+func (m *BrowserModel) cursorIndex() int {
+	return lineColToIndex(m.query.Value(), m.query.Line(), m.query.Column())
+}
+
+// setCursorIndex moves the cursor to a byte offset. In NORMAL mode the
+// cursor rests on a character, never after the last one of a line.
+func (m *BrowserModel) setCursorIndex(text string, pos int) {
+	pos = max(0, min(pos, len(text)))
+	if m.queryMode != QueryModeInsert {
+		if start, end := lineBounds(text, pos); pos == end && end > start {
+			_, size := utf8.DecodeLastRuneInString(text[:end])
+			pos = end - size
+		}
+	}
+	row, col := indexToLineCol(text, pos)
+	m.setQueryCursor(row, col)
+}
+
+// replaceText swaps text[start:end] for repl and moves the cursor to pos in
+// the new text.
+func (m *BrowserModel) replaceText(start, end int, repl string, pos int) {
+	text := m.query.Value()
+	newText := text[:start] + repl + text[end:]
+	m.query.SetValue(newText)
+	m.setCursorIndex(newText, pos)
+}
+
+// motion is where a motion key moves the cursor. Linewise motions act on
+// whole lines; inclusive ones include the character they land on.
+type motion struct {
+	pos       int
+	linewise  bool
+	inclusive bool
+}
+
+// vimMotion computes the motion for key from pos. underOperator changes a
+// few motions the way vim does when they are the target of d, c or y.
+func (m *BrowserModel) vimMotion(text string, pos int, key string, underOperator bool) (motion, bool) {
+	v := &m.vim
+	count := v.totalCount()
+	lineStart, lineEnd := lineBounds(text, pos)
+	row, col := indexToLineCol(text, pos)
+	lines := strings.Split(text, "\n")
+
+	toLine := func(r int) motion {
+		r = max(0, min(r, len(lines)-1))
+		start := lineColToIndex(text, r, 0)
+		return motion{pos: firstNonBlank(text, start), linewise: true}
+	}
+
+	switch key {
+	case "h", "left", "backspace":
+		return motion{pos: max(lineStart, pos-count)}, true
+	case "l", "right":
+		last := lineEnd
+		if !underOperator && lineEnd > lineStart {
+			last = lineEnd - 1
+		}
+		return motion{pos: min(last, pos+count)}, true
+	case "j", "down":
+		return motion{pos: lineColToIndex(text, min(row+count, len(lines)-1), col), linewise: true}, true
+	case "k", "up":
+		return motion{pos: lineColToIndex(text, max(row-count, 0), col), linewise: true}, true
+	case "0", "home":
+		return motion{pos: lineStart}, true
+	case "^":
+		return motion{pos: firstNonBlank(text, lineStart)}, true
+	case "$", "end":
+		start, end := lineBounds(text, lineColToIndex(text, min(row+count-1, len(lines)-1), 0))
+		if underOperator {
+			return motion{pos: end}, true
+		}
+		return motion{pos: max(end-1, start), inclusive: true}, true
+	case "gg":
+		if v.hasCount() {
+			return toLine(count - 1), true
+		}
+		return toLine(0), true
+	case "G":
+		if v.hasCount() {
+			return toLine(count - 1), true
+		}
+		return toLine(len(lines) - 1), true
+	case "w", "W":
+		target := pos
+		for i := 0; i < count; i++ {
+			target = nextWordStart(text, target, key == "W")
+		}
+		if underOperator {
+			// The last word of a line is the end of the operated text
+			end := target
+			for end > pos && charClass(text[end-1], false) == classBlank {
+				end--
+			}
+			if strings.Contains(text[end:target], "\n") {
+				target = end
+			}
+		}
+		return motion{pos: target}, true
+	case "e", "E":
+		target := pos
+		for i := 0; i < count; i++ {
+			target = wordEnd(text, target, key == "E")
+		}
+		return motion{pos: target, inclusive: true}, true
+	case "b", "B":
+		target := pos
+		for i := 0; i < count; i++ {
+			target = prevWordStart(text, target, key == "B")
+		}
+		return motion{pos: target}, true
+	case ";", ",":
+		if v.lastFind == "" {
+			return motion{}, false
+		}
+		kind, ch := v.lastFind[:1], v.lastFind[1:]
+		if key == "," {
+			kind = map[string]string{"f": "F", "F": "f", "t": "T", "T": "t"}[kind]
+		}
+		return m.findMotion(text, pos, kind, ch, count)
+	case "%":
+		target, ok := matchBracket(text, pos)
+		return motion{pos: target, inclusive: true}, ok
+	case "}":
+		target := pos
+		for i := 0; i < count; i++ {
+			target = paragraphForward(text, target)
+		}
+		return motion{pos: target}, true
+	case "{":
+		target := pos
+		for i := 0; i < count; i++ {
+			target = paragraphBackward(text, target)
+		}
+		return motion{pos: target}, true
+	case "n", "N":
+		return m.searchMotion(text, pos, key == "N", count)
+	case "*", "#":
+		word := wordAt(text, pos)
+		if word == "" {
+			m.statusMsg = "No string under cursor"
+			return motion{}, false
+		}
+		v.searchPattern = `\b` + word + `\b`
+		v.searchBackward = key == "#"
+		return m.searchMotion(text, pos, false, count)
+	}
+	if len(key) == 2 && strings.ContainsAny(key[:1], "fFtT") {
+		v.lastFind = key
+		return m.findMotion(text, pos, key[:1], key[1:], count)
+	}
+	return motion{}, false
+}
+
+// findMotion implements f, t, F and T.
+func (m *BrowserModel) findMotion(text string, pos int, kind, ch string, count int) (motion, bool) {
+	forward := kind == "f" || kind == "t"
+	target, ok := findInLine(text, pos, ch, forward, kind == "t" || kind == "T", count)
+	return motion{pos: target, inclusive: forward}, ok
+}
+
+// searchMotion jumps to the count-th match of the last search, reversed by
+// N and ?.
+func (m *BrowserModel) searchMotion(text string, pos int, reverse bool, count int) (motion, bool) {
+	v := &m.vim
+	if v.searchPattern == "" {
+		m.statusMsg = "No previous search pattern"
+		return motion{}, false
+	}
+	backward := v.searchBackward != reverse
+	target := pos
+	for i := 0; i < count; i++ {
+		match, wrapped, ok := searchText(text, v.searchPattern, target, backward)
+		if !ok {
+			m.statusMsg = "Pattern not found: " + v.searchPattern
+			return motion{}, false
+		}
+		target = match
+		if wrapped && backward {
+			m.statusMsg = "search hit TOP, continuing at BOTTOM"
+		} else if wrapped {
+			m.statusMsg = "search hit BOTTOM, continuing at TOP"
+		} else {
+			m.statusMsg = "/" + v.searchPattern
+			if backward {
+				m.statusMsg = "?" + v.searchPattern
+			}
+		}
+	}
+	return motion{pos: target}, true
+}
+
+// operatorRange turns a motion from pos into the [start, end) range an
+// operator acts on.
+func operatorRange(text string, pos int, mo motion) (start, end int) {
+	start, end = min(pos, mo.pos), max(pos, mo.pos)
+	if mo.linewise {
+		start, _ = lineBounds(text, start)
+		_, end = lineBounds(text, end)
+		return start, end
+	}
+	if mo.inclusive && end < len(text) {
+		_, size := utf8.DecodeRuneInString(text[end:])
+		end += size
+	}
+	return start, end
+}
+
+// applyOperator runs op over text[start:end]. For linewise ranges start and
+// end are the start and end of whole lines, excluding the last newline.
+func (m *BrowserModel) applyOperator(op string, start, end int, linewise bool) {
+	text := m.query.Value()
+	switch op {
+	case "y":
+		if linewise {
+			m.storeRegister(text[start:end]+"\n", true)
+			m.statusMsg = "1 line yanked"
+			if n := strings.Count(text[start:end], "\n") + 1; n > 1 {
+				m.statusMsg = fmt.Sprintf("%d lines yanked", n)
+			}
+		} else {
+			m.storeRegister(text[start:end], true)
+		}
+		m.setCursorIndex(text, start)
+		m.vim.done()
+		return
+
+	case "d":
+		if linewise {
+			m.storeRegister(text[start:end]+"\n", false)
+			// Take the line break after the lines, or before them at the end
+			delStart, delEnd := start, end
+			if delEnd < len(text) {
+				delEnd++
+			} else if delStart > 0 {
+				delStart--
+			}
+			newText := text[:delStart] + text[delEnd:]
+			lineStart, _ := lineBounds(newText, min(delStart, len(newText)))
+			m.query.SetValue(newText)
+			m.setCursorIndex(newText, firstNonBlank(newText, lineStart))
+		} else {
+			m.storeRegister(text[start:end], false)
+			m.replaceText(start, end, "", start)
+		}
+
+	case "c":
+		if linewise {
+			m.storeRegister(text[start:end]+"\n", false)
+			// Keep the indentation of the first line
+			indent := firstNonBlank(text, start) - start
+			m.replaceText(start, end, text[start:start+indent], start+indent)
+			start += indent
+		} else {
+			m.storeRegister(text[start:end], false)
+			m.replaceText(start, end, "", start)
+		}
+		m.setCursorIndexInsert(m.query.Value(), start)
+		m.enterInsert()
+		return
+
+	case ">", "<":
+		rowStart, _ := indexToLineCol(text, start)
+		rowEnd, _ := indexToLineCol(text, end)
+		newText := indentLines(text, rowStart, rowEnd, op == ">")
+		m.query.SetValue(newText)
+		lineStart := lineColToIndex(newText, rowStart, 0)
+		m.setCursorIndex(newText, firstNonBlank(newText, lineStart))
+
+	case "=":
+		start, _ = lineBounds(text, start)
+		_, end = lineBounds(text, end)
+		formatted := sqlparse.Format(text[start:end], m.formatOptions())
+		m.replaceText(start, end, formatted, start)
+		m.statusMsg = fmt.Sprintf("Formatted %d line(s)", strings.Count(formatted, "\n")+1)
+
+	case "g~", "gu", "gU":
+		m.replaceText(start, end, changeCase(text[start:end], op), start)
+	}
+	m.vim.changed()
+}
+
+// changeCase applies the g~, gu or gU operator to s.
+func changeCase(s, op string) string {
+	switch op {
+	case "gu":
+		return strings.ToLower(s)
+	case "gU":
+		return strings.ToUpper(s)
+	}
+	return strings.Map(func(r rune) rune {
+		if unicode.IsUpper(r) {
+			return unicode.ToLower(r)
+		}
+		return unicode.ToUpper(r)
+	}, s)
+}
+
+// indentLines indents or outdents lines rowStart to rowEnd by two spaces.
+func indentLines(text string, rowStart, rowEnd int, indent bool) string {
+	lines := strings.Split(text, "\n")
+	rowStart = max(rowStart, 0)
+	rowEnd = min(rowEnd, len(lines)-1)
+	for i := rowStart; i <= rowEnd; i++ {
+		switch {
+		case indent && lines[i] != "":
+			lines[i] = "  " + lines[i]
+		case !indent && strings.HasPrefix(lines[i], "  "):
+			lines[i] = lines[i][2:]
+		case !indent && strings.HasPrefix(lines[i], " "):
+			lines[i] = lines[i][1:]
+		}
+	}
+	return strings.Join(lines, "\n")
+}
+
+// enterInsert switches to INSERT mode. A change that got here (c, s, o and
+// the like) is completed by what is typed before esc.
+func (m *BrowserModel) enterInsert() {
+	v := &m.vim
+	v.insertBase = m.query.Value()
+	v.insertStart = m.cursorIndex()
+	v.inserting = !v.replaying
+	v.count, v.opCount, v.register = 0, 0, 0
+	v.operator, v.pending = "", ""
+	m.queryMode = QueryModeInsert
+	m.query.Focus()
+}
+
+// finishInsert records the INSERT session that just ended as the last
+// change, with the text typed at the insertion point. Edits that did more
+// than insert text there are repeated without the typed part.
+func (m *BrowserModel) finishInsert() {
+	v := &m.vim
+	if !v.inserting {
+		return
+	}
+	v.inserting = false
+	text, base, at := m.query.Value(), v.insertBase, v.insertStart
+	inserted := ""
+	if n := len(text) - len(base); n >= 0 && at <= len(base) &&
+		text[:at] == base[:at] && text[at+n:] == base[at:] {
+		inserted = text[at : at+n]
+	}
+	v.lastChange = vimChange{keys: v.keys, insert: inserted}
+	v.keys = nil
+}
+
+// stepBackFromInsert moves the cursor back onto the last inserted character
+// when leaving INSERT mode, as vim does.
+func (m *BrowserModel) stepBackFromInsert() {
+	if col := m.query.Column(); col > 0 {
+		m.query.SetCursorColumn(col - 1)
+	}
+}
+
+// repeatChange implements ".": replay the last change, with a new count if
+// one was typed.
+func (m *BrowserModel) repeatChange() {
+	v := &m.vim
+	change := v.lastChange
+	count := v.count
+	v.done()
+	if len(change.keys) == 0 {
+		return
+	}
+
+	keys := change.keys
+	if count > 0 {
+		// Replace the original count with the new one
+		for len(keys) > 0 && len(keys[0].Text) == 1 && keys[0].Text[0] >= '0' && keys[0].Text[0] <= '9' {
+			keys = keys[1:]
+		}
+		var digits []tea.KeyPressMsg
+		for _, r := range strconv.Itoa(count) {
+			digits = append(digits, tea.KeyPressMsg{Code: r, Text: string(r)})
+		}
+		keys = append(digits, keys...)
+	}
+
+	v.replaying = true
+	for _, k := range keys {
+		m.handleKeyPress(k)
+	}
+	if m.queryMode == QueryModeInsert {
+		m.query.InsertString(change.insert)
+		m.queryMode = QueryModeNormal
+		m.query.Blur()
+		m.stepBackFromInsert()
+	}
+	v.replaying = false
+	v.done()
+	if count > 0 {
+		v.lastChange = vimChange{keys: keys, insert: change.insert}
+	}
+}
+
+// handleQueryNormalMode handles keys in NORMAL mode: counts, registers,
+// operators with motions or text objects, and single-key commands.
+func (m *BrowserModel) handleQueryNormalMode(msg tea.KeyPressMsg) (tea.Model, tea.Cmd) {
+	v := &m.vim
+	k := msg.String()
+	if !v.replaying {
+		v.keys = append(v.keys, msg)
+	}
+	text := m.query.Value()
+	pos := m.cursorIndex()
+
+	if k == "esc" {
+		v.done()
+		m.statusMsg = ""
+		return m, nil
+	}
+
+	// Prefixes that take the next key as their argument
+	if v.pending != "" {
+		pending := v.pending
+		v.pending = ""
+		switch pending {
+		case `"`:
+			if r := []rune(k); len(r) == 1 && validRegister(r[0]) {
+				v.register = r[0]
+				m.statusMsg = v.describe()
+			} else {
+				v.done()
+				m.statusMsg = ""
+			}
+			return m, nil
+		case "r":
+			m.replaceChars(text, pos, k)
+			return m, nil
+		case "g":
+			return m.handleNormalG(text, pos, k)
+		case "i", "a":
+			start, end, linewise, ok := textObject(text, pos, pending+k, v.totalCount())
+			if !ok {
+				v.done()
+				m.statusMsg = ""
+				return m, nil
+			}
+			m.statusMsg = ""
+			if linewise {
+				_, end = lineBounds(text, max(end-1, start))
+			}
+			m.applyOperator(v.operator, start, end, linewise)
+			return m, nil
+		default: // f t F T
+			if v.operator != "" {
+				m.operatorMotion(text, pos, pending+k)
+				return m, nil
+			}
+			return m.normalMotion(text, pos, pending+k)
+		}
+	}
+
+	// Counts: 0 is a motion unless a count is being typed
+	if len(k) == 1 && k[0] >= '0' && k[0] <= '9' && (k != "0" || v.count > 0 || v.opCount > 0) {
+		digit := int(k[0] - '0')
+		if v.operator != "" {
+			v.opCount = v.opCount*10 + digit
+		} else {
+			v.count = v.count*10 + digit
+		}
+		m.statusMsg = v.describe()
+		return m, nil
+	}
+
+	if v.operator != "" {
+		switch {
+		case k == v.operator || len(v.operator) == 2 && k == v.operator[1:]:
+			// Doubled operator (dd, yy, >>, gUU) acts on count lines
+			m.statusMsg = ""
+			m.operatorLines(text, pos)
+		case k == "i" || k == "a":
+			v.pending = k
+			m.statusMsg = v.describe()
+		case k == "g" || strings.ContainsAny(k, "fFtT") && len(k) == 1:
+			v.pending = k
+			m.statusMsg = v.describe()
+		default:
+			m.statusMsg = ""
+			m.operatorMotion(text, pos, k)
+		}
+		return m, nil
+	}
+
+	switch k {
+	// === Mode switching ===
+	case "i":
+		m.enterInsert()
+	case "a":
+		if _, end := lineBounds(text, pos); pos < end {
+			_, size := utf8.DecodeRuneInString(text[pos:])
+			m.setCursorIndexInsert(text, pos+size)
+		}
+		m.enterInsert()
+	case "I":
+		start, _ := lineBounds(text, pos)
+		m.setCursorIndexInsert(text, firstNonBlank(text, start))
+		m.enterInsert()
+	case "A":
+		_, end := lineBounds(text, pos)
+		m.setCursorIndexInsert(text, end)
+		m.enterInsert()
+	case "o":
+		_, end := lineBounds(text, pos)
+		m.replaceText(end, end, "\n", end+1)
+		m.setCursorIndexInsert(m.query.Value(), end+1)
+		m.enterInsert()
+	case "O":
+		start, _ := lineBounds(text, pos)
+		m.replaceText(start, start, "\n", start)
+		m.setCursorIndexInsert(m.query.Value(), start)
+		m.enterInsert()
+	case "v", "V":
+		m.queryMode = QueryModeVisual
+		m.statusMsg = "-- VISUAL --"
+		if k == "V" {
+			m.queryMode = QueryModeVisualLine
+			m.statusMsg = "-- VISUAL LINE --"
+		}
+		m.visualStart.row, m.visualStart.col = m.query.Line(), m.query.Column()
+		m.visualEnd = m.visualStart
+		v.done()
+
+	// === Operators and their shorthands ===
+	case "d", "c", "y", ">", "<", "=":
+		v.operator = k
+		m.statusMsg = v.describe()
+	case "x", "X", "D", "C", "s":
+		// Shorthands for an operator and motion
+		short := vimShorthands[k]
+		v.operator = short[0]
+		m.operatorMotion(text, pos, short[1])
+	case "S", "Y":
+		v.operator = map[string]string{"S": "c", "Y": "y"}[k]
+		m.operatorLines(text, pos)
+	case "g", "f", "F", "t", "T", "r", `"`:
+		v.pending = k
+		m.statusMsg = v.describe()
+
+	// === Editing ===
+	case "p", "P":
+		m.put(text, pos, k == "P")
+	case "J":
+		m.joinLines(text, pos)
+	case "~":
+		_, end := lineBounds(text, pos)
+		stop := min(end, pos+v.totalCount())
+		m.replaceText(pos, stop, changeCase(text[pos:stop], "g~"), min(stop, end))
+		v.changed()
+	case "u":
+		for i := 0; i < v.totalCount(); i++ {
+			m.undoEdit()
+		}
+		v.done()
+	case "ctrl+r":
+		for i := 0; i < v.totalCount(); i++ {
+			m.redoEdit()
+		}
+		v.done()
+	case ".":
+		m.repeatChange()
+
+	// === Search ===
+	case "/", "?":
+		v.searching = true
+		v.searchInput = ""
+		v.searchBackward = k == "?"
+		v.done()
+
+	// === Scrolling and execution ===
+	case "pgup":
+		m.query.PageUp()
+		v.done()
+	case "pgdown":
+		m.query.PageDown()
+		v.done()
+	case "enter":
+		v.done()
+		return m, m.executeQuery()
+
+	default:
+		return m.normalMotion(text, pos, k)
+	}
+	return m, nil
+}
+
+// vimShorthands maps single-key commands to the operator and motion they stand for.
+var vimShorthands = map[string][2]string{
+	"x": {"d", "l"}, "X": {"d", "h"}, "D": {"d", "$"}, "C": {"c", "$"}, "s": {"c", "l"},
+}
+
+// operatorLines applies the pending operator to count lines from the
+// cursor, for doubled operators such as dd, yy and >>.
+func (m *BrowserModel) operatorLines(text string, pos int) {
+	row, _ := indexToLineCol(text, pos)
+	last := min(row+m.vim.totalCount()-1, strings.Count(text, "\n"))
+	start := lineColToIndex(text, row, 0)
+	_, end := lineBounds(text, lineColToIndex(text, last, 0))
+	m.applyOperator(m.vim.operator, start, end, true)
+}
+
+// handleNormalG handles the second key of g commands.
+func (m *BrowserModel) handleNormalG(text string, pos int, k string) (tea.Model, tea.Cmd) {
+	v := &m.vim
+	m.statusMsg = ""
+	switch k {
+	case "t", "T":
+		v.done()
+		if k == "t" {
+			m.switchBuffer(1)
+		} else {
+			m.switchBuffer(-1)
+		}
+		return m, nil
+	case "~", "u", "U":
+		if v.operator == "g"+k {
+			// gUgU and friends: the doubled form
+			m.operatorLines(text, pos)
+			return m, nil
+		}
+		if v.operator == "" {
+			v.operator = "g" + k
+			m.statusMsg = v.describe()
+			return m, nil
+		}
+	}
+	if v.operator != "" {
+		m.operatorMotion(text, pos, "g"+k)
+		return m, nil
+	}
+	return m.normalMotion(text, pos, "g"+k)
+}
+
+// normalMotion moves the cursor by a motion key.
+func (m *BrowserModel) normalMotion(text string, pos int, key string) (tea.Model, tea.Cmd) {
+	v := &m.vim
+	// Plain j and k go through the textarea, which keeps the column
+	switch key {
+	case "j", "down", "k", "up":
+		for i := 0; i < v.totalCount(); i++ {
+			if key == "j" || key == "down" {
+				m.query.CursorDown()
+			} else {
+				m.query.CursorUp()
+			}
+		}
+		v.done()
+		return m, nil
+	}
+	if mo, ok := m.vimMotion(text, pos, key, false); ok {
+		m.setCursorIndex(text, mo.pos)
+	}
+	v.done()
+	return m, nil
+}
+
+// operatorMotion applies the pending operator over a motion.
+func (m *BrowserModel) operatorMotion(text string, pos int, key string) {
+	v := &m.vim
+	bigWord := key == "W"
+	if v.operator == "c" && (key == "w" || bigWord) && pos < len(text) && charClass(text[pos], bigWord) != classBlank {
+		// cw on a word changes to the end of the word, like ce
+		end := pos
+		if pos+1 < len(text) && charClass(text[pos+1], bigWord) == charClass(text[pos], bigWord) {
+			end = wordEnd(text, pos, bigWord)
+		}
+		for i := 1; i < v.totalCount(); i++ {
+			end = wordEnd(text, end, bigWord)
+		}
+		start, stop := operatorRange(text, pos, motion{pos: end, inclusive: true})
+		m.applyOperator("c", start, stop, false)
+		return
+	}
+	mo, ok := m.vimMotion(text, pos, key, true)
+	if !ok {
+		v.done()
+		return
+	}
+	start, end := operatorRange(text, pos, mo)
+	if start == end && !mo.linewise {
+		v.done()
+		return
+	}
+	m.applyOperator(v.operator, start, end, mo.linewise)
+}
+
+// setCursorIndexInsert places the cursor for INSERT mode, where it may sit
+// after the last character of a line.
+func (m *BrowserModel) setCursorIndexInsert(text string, pos int) {
+	row, col := indexToLineCol(text, max(0, min(pos, len(text))))
+	m.setQueryCursor(row, col)
+}
+
+// replaceChars implements r: replace count characters with ch.
+func (m *BrowserModel) replaceChars(text string, pos int, ch string) {
+	v := &m.vim
+	_, end := lineBounds(text, pos)
+	n := v.totalCount()
+	if utf8.RuneCountInString(ch) != 1 || utf8.RuneCountInString(text[pos:end]) < n {
+		v.done()
+		m.statusMsg = ""
+		return
+	}
+	stop := pos
+	for i := 0; i < n; i++ {
+		_, size := utf8.DecodeRuneInString(text[stop:])
+		stop += size
+	}
+	m.statusMsg = ""
+	m.replaceText(pos, stop, strings.Repeat(ch, n), stop-len(ch))
+	v.changed()
+}
+
+// put implements p and P with the selected register and count.
+func (m *BrowserModel) put(text string, pos int, before bool) {
+	v := &m.vim
+	content := m.register(v.register)
+	if content == "" {
+		v.done()
+		return
+	}
+	content = strings.Repeat(content, v.totalCount())
+
+	if strings.HasSuffix(content, "\n") {
+		// Linewise: on new lines below or above the cursor line
+		start, end := lineBounds(text, pos)
+		lines := strings.TrimSuffix(content, "\n")
+		if before {
+			m.replaceText(start, start, lines+"\n", start)
+			m.setCursorIndex(m.query.Value(), firstNonBlank(m.query.Value(), start))
+		} else {
+			m.replaceText(end, end, "\n"+lines, end+1)
+			m.setCursorIndex(m.query.Value(), firstNonBlank(m.query.Value(), end+1))
+		}
+	} else {
+		at := pos
+		if _, end := lineBounds(text, pos); !before && pos < end {
+			_, size := utf8.DecodeRuneInString(text[pos:])
+			at += size
+		}
+		m.replaceText(at, at, content, at+len(content)-1)
+	}
+	v.changed()
+}
+
+// joinLines implements J: join count lines (at least two) with single spaces.
+func (m *BrowserModel) joinLines(text string, pos int) {
+	v := &m.vim
+	joins := max(v.totalCount()-1, 1)
+	var cursor int
+	for i := 0; i < joins; i++ {
+		_, end := lineBounds(text, pos)
+		if end >= len(text) {
+			break
+		}
+		next := firstNonBlank(text, end+1)
+		trimmed := strings.TrimRight(text[:end], " \t")
+		sep := " "
+		if len(trimmed) == 0 || trimmed[len(trimmed)-1] == '\n' || next >= len(text) || text[next] == '\n' || text[next] == ')' {
+			sep = ""
+		}
+		cursor = len(trimmed)
+		text = trimmed + sep + text[next:]
+	}
+	m.query.SetValue(text)
+	m.setCursorIndex(text, cursor)
+	v.changed()
+}
+
+// validRegister reports whether r names a register.
+func validRegister(r rune) bool {
+	return r >= 'a' && r <= 'z' || r >= 'A' && r <= 'Z' || r >= '0' && r <= '9' ||
+		strings.ContainsRune(`"-_+*`, r)
+}
+
+// handleQueryVisualMode handles keys in VISUAL and VISUAL LINE mode.
+func (m *BrowserModel) handleQueryVisualMode(msg tea.KeyPressMsg) (tea.Model, tea.Cmd) {
+	v := &m.vim
+	k := msg.String()
+	text := m.query.Value()
+	pos := m.cursorIndex()
+
+	// updateEnd moves the selection end to the cursor
+	updateEnd := func() {
+		m.visualEnd.row = m.query.Line()
+		m.visualEnd.col = m.query.Column()
+	}
+	exit := func() {
+		m.queryMode = QueryModeNormal
+		v.done()
+	}
+
+	if v.pending != "" {
+		pending := v.pending
+		v.pending = ""
+		switch pending {
+		case `"`:
+			if r := []rune(k); len(r) == 1 && validRegister(r[0]) {
+				v.register = r[0]
+			}
+			return m, nil
+		case "i", "a":
+			start, end, linewise, ok := textObject(text, pos, pending+k, v.totalCount())
+			if ok && end > start {
+				if linewise {
+					m.queryMode = QueryModeVisualLine
+				}
+				m.visualStart.row, m.visualStart.col = indexToLineCol(text, start)
+				_, size := utf8.DecodeLastRuneInString(text[:end])
+				m.setCursorIndex(text, end-size)
+				updateEnd()
+			}
+			v.count = 0
+			return m, nil
+		case "g":
+			if k == "g" {
+				k = "gg"
+			} else {
+				v.count = 0
+				return m, nil
+			}
+		default: // f t F T
+			k = pending + k
+		}
+	} else if len(k) == 1 && k[0] >= '0' && k[0] <= '9' && (k != "0" || v.count > 0) {
+		v.count = v.count*10 + int(k[0]-'0')
+		return m, nil
+	}
+
+	linewise := m.queryMode == QueryModeVisualLine
+	switch k {
+	case "esc":
+		exit()
+		m.statusMsg = ""
+		return m, nil
+	case "v", "V":
+		if (k == "V") == linewise {
+			exit()
+			m.statusMsg = ""
+			return m, nil
+		}
+		m.queryMode = QueryModeVisual
+		m.statusMsg = "-- VISUAL --"
+		if k == "V" {
+			m.queryMode = QueryModeVisualLine
+			m.statusMsg = "-- VISUAL LINE --"
+		}
+		return m, nil
+	case "o":
+		// Swap the cursor to the other end of the selection
+		m.visualStart, m.visualEnd = m.visualEnd, m.visualStart
+		m.setQueryCursor(m.visualEnd.row, m.visualEnd.col)
+		return m, nil
+	case "i", "a", "g", "f", "F", "t", "T", `"`:
+		v.pending = k
+		return m, nil
+	}
+
+	start, end, ok := m.selectedQueryRange()
+	if linewise && ok {
+		_, end = lineBounds(text, max(end-1, start))
+	}
+	switch k {
+	case "y", "d", "x", "c", "s", ">", "<", "=", "~", "u", "U":
+		if !ok {
+			exit()
+			return m, nil
+		}
+		op := map[string]string{"x": "d", "s": "c", "~": "g~", "u": "gu", "U": "gU"}[k]
+		if op == "" {
+			op = k
+		}
+		m.queryMode = QueryModeNormal
+		m.statusMsg = ""
+		m.applyOperator(op, start, end, linewise)
+		if op == "y" && !linewise {
+			m.statusMsg = "yanked"
+		}
+		v.done()
+		return m, nil
+	case "p", "P":
+		content := m.register(v.register)
+		if ok && content != "" {
+			// The replaced text goes to the unnamed register, as in vim
+			v.register = 0
+			m.storeRegister(text[start:end], false)
+			if linewise {
+				content = strings.TrimSuffix(content, "\n")
+			}
+			m.replaceText(start, end, content, start)
+		}
+		exit()
+		return m, nil
+	case "J":
+		if ok {
+			rowStart, _ := indexToLineCol(text, start)
+			rowEnd, _ := indexToLineCol(text, end)
+			m.setCursorIndex(text, start)
+			v.count = rowEnd - rowStart + 1
+			m.joinLines(text, start)
+		}
+		exit()
+		return m, nil
+	}
+
+	// Everything else is a motion that extends the selection
+	if mo, ok := m.vimMotion(text, pos, k, false); ok {
+		m.setCursorIndex(text, mo.pos)
+		updateEnd()
+	}
+	v.count = 0
+	return m, nil
+}
+
+// handleSearchKey edits the "/" or "?" search prompt.
+func (m *BrowserModel) handleSearchKey(msg tea.KeyPressMsg) (tea.Model, tea.Cmd) {
+	v := &m.vim
+	switch msg.String() {
+	case "esc":
+		v.searching = false
+		m.statusMsg = ""
+	case "enter":
+		v.searching = false
+		if v.searchInput != "" {
+			v.searchPattern = v.searchInput
+		}
+		text := m.query.Value()
+		if mo, ok := m.searchMotion(text, m.cursorIndex(), false, 1); ok {
+			m.setCursorIndex(text, mo.pos)
+			if m.queryMode == QueryModeVisual || m.queryMode == QueryModeVisualLine {
+				m.visualEnd.row, m.visualEnd.col = m.query.Line(), m.query.Column()
+			}
+		}
+	case "backspace", "ctrl+h":
+		r := []rune(v.searchInput)
+		if len(r) == 0 {
+			v.searching = false
+			return m, nil
+		}
+		v.searchInput = string(r[:len(r)-1])
+	default:
+		if msg.Text != "" {
+			v.searchInput += msg.Text
+		}
+	}
+	return m, nil
+}
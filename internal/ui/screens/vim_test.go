@@ -0,0 +1,127 @@
+package screens
+
+import (
+	"strings"
+	"testing"
+
+	tea "charm.land/bubbletea/v2"
+
+	"github.com/jupiterozeye/tornado/internal/models"
+)
+
+// vimEdit starts an editor on text with the cursor at the "|" marker, types
+// keys (with <esc> and <cr> for escape and enter) and returns the text with
+// the cursor marked again.
+func vimEdit(t *testing.T, text, keys string) string {
+	t.Helper()
+	m := NewBrowserModel(nil, models.ConnectionConfig{})
+	m.focusedPane = PaneQuery
+	cursor := strings.Index(text, "|")
+	text = strings.Replace(text, "|", "", 1)
+	m.query.SetValue(text)
+	m.setCursorIndex(text, cursor)
+
+	for keys != "" {
+		var msg tea.KeyPressMsg
+		switch {
+		case strings.HasPrefix(keys, "<esc>"):
+			msg, keys = tea.KeyPressMsg{Code: tea.KeyEscape}, keys[5:]
+		case strings.HasPrefix(keys, "<cr>"):
+			msg, keys = tea.KeyPressMsg{Code: tea.KeyEnter}, keys[4:]
+		default:
+			msg, keys = tea.KeyPressMsg{Code: rune(keys[0]), Text: keys[:1]}, keys[1:]
+		}
+		m.Update(msg)
+	}
+	got := m.query.Value()
+	at := m.cursorIndex()
+	return got[:at] + "|" + got[at:]
+}
+
+func TestVim_operatorsAndMotions(t *testing.T) {
+	tests := []struct {
+		text, keys, want string
+	}{
+		{"|SELECT a, b FROM t", "dw", "|a, b FROM t"},
+		{"|SELECT a, b FROM t", "2dw", "|, b FROM t"},
+		{"|SELECT a, b FROM t", "d3w", "|b FROM t"},
+		{"SELECT |a, b FROM t", "dt,", "SELECT |, b FROM t"},
+		{"SELECT a, b |FROM t", "D", "SELECT a, b| "},
+		{"SELECT |a, b FROM t", "cwx<esc>", "SELECT |x, b FROM t"},
+		{"|one\ntwo\nthree", "2dd", "|three"},
+		{"one\n|two", "dd", "|one"},
+		{"|one\ntwo", "yyjp", "one\ntwo\n|one"},
+		{"|abc", "3x", "|"},
+		{"a|bc", "xu", "a|bc"},
+		{"|select 1", "gUiw", "|SELECT 1"},
+		{"|one\ntwo", ">j", "  |one\n  two"},
+		{"|a\nb\nc", "3J", "a b| c"},
+		{"|count(a, (b))", "%x", "count(a, (b|)"},
+	}
+	for _, tt := range tests {
+		if got := vimEdit(t, tt.text, tt.keys); got != tt.want {
+			t.Errorf("%q + %q = %q, want %q", tt.text, tt.keys, got, tt.want)
+		}
+	}
+}
+
+func TestVim_textObjects(t *testing.T) {
+	tests := []struct {
+		text, keys, want string
+	}{
+		{"SELECT na|me FROM t", "ciwid<esc>", "SELECT i|d FROM t"},
+		{"SELECT na|me FROM t", "daw", "SELECT |FROM t"},
+		{"count(a, |b)", "di(", "count(|)"},
+		{"f(g(|x), y)", "d2i(", "f(|)"},
+		{"WHERE n = 'it''s |here'", "di'", "WHERE n = '|'"},
+		{"WHERE n = 'it''s |here'", "da'", "WHERE n |="},
+		{"SELECT 1;\nSELECT |2;\nSELECT 3", "das", "SELECT 1;\n|SELECT 3"},
+		{"SELECT 1;\nSELECT |2;", "cisSELECT 9<esc>", "SELECT 1;\nSELECT |9;"},
+	}
+	for _, tt := range tests {
+		if got := vimEdit(t, tt.text, tt.keys); got != tt.want {
+			t.Errorf("%q + %q = %q, want %q", tt.text, tt.keys, got, tt.want)
+		}
+	}
+}
+
+func TestVim_searchRegistersAndRepeat(t *testing.T) {
+	tests := []struct {
+		text, keys, want string
+	}{
+		{"|a FROM b FROM c", "/from<cr>", "a |FROM b FROM c"},
+		{"|a FROM b FROM c", "/from<cr>n", "a FROM b |FROM c"},
+		{"|a FROM b FROM c", "/from<cr>nn", "a |FROM b FROM c"},
+		{"a FROM b FROM |c", "?FROM<cr>", "a FROM b |FROM c"},
+		{"|id = id", "*", "id = |id"},
+		{"|one\ntwo", `"ayyj"byy"aP`, "one\n|one\ntwo"},
+		{"|one two", `"_dwP`, "|two"},
+		{"|a b c d", "dw..", "|d"},
+		{"|a b c d", "dw2.", "|d"},
+		{"|x = 1, y = 2", "ciwa<esc>/y<cr>.", "a = 1, |a = 2"},
+		{"|one\ntwo", "Atest<esc>j.", "onetest\ntwotes|t"},
+	}
+	for _, tt := range tests {
+		if got := vimEdit(t, tt.text, tt.keys); got != tt.want {
+			t.Errorf("%q + %q = %q, want %q", tt.text, tt.keys, got, tt.want)
+		}
+	}
+}
+
+func TestVim_visual(t *testing.T) {
+	tests := []struct {
+		text, keys, want string
+	}{
+		{"SELECT na|me FROM t", "viwd", "SELECT | FROM t"},
+		{"|one\ntwo\nthree", "Vjd", "|three"},
+		{"f(|a, b) + x", "vi(y$p", "f(a, b) + xa, |b"},
+		{"SELECT |a, b", "yiwWviwp", "SELECT a, |a"},
+		{"|one two", "vwU", "|ONE Two"},
+		{"SELECT 1;\nselect |a from t;", "vis=", "SELECT 1;\n|SELECT a\nFROM t;"},
+	}
+	for _, tt := range tests {
+		if got := vimEdit(t, tt.text, tt.keys); got != tt.want {
+			t.Errorf("%q + %q = %q, want %q", tt.text, tt.keys, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,451 @@
+package screens
+
+import (
+	"regexp"
+	"strings"
+	"unicode"
+
+	"github.com/jupiterozeye/tornado/internal/sqlparse"
+)
+
+// Text motions and objects for the vim engine. They work on the editor text
+// with byte offsets and know nothing about the textarea.
+
+// Character classes for word motions: a word is a run of letters, digits
+// and underscores or a run of other non-blank characters; a WORD is any run
+// of non-blank characters.
+const (
+	classBlank = iota
+	classPunct
+	classWord
+)
+
+func charClass(b byte, bigWord bool) int {
+	switch {
+	case b == ' ' || b == '\t' || b == '\n' || b == '\r':
+		return classBlank
+	case bigWord || b == '_' || b >= 0x80 || isAlnum(b):
+		return classWord
+	}
+	return classPunct
+}
+
+func isAlnum(b byte) bool {
+	return b >= 'a' && b <= 'z' || b >= 'A' && b <= 'Z' || b >= '0' && b <= '9'
+}
+
+func isBlank(b byte) bool {
+	return b == ' ' || b == '\t'
+}
+
+// lineBounds returns the start of the line containing pos and the offset of
+// its terminating newline (or the end of the text).
+func lineBounds(text string, pos int) (start, end int) {
+	if pos > len(text) {
+		pos = len(text)
+	}
+	start = strings.LastIndexByte(text[:pos], '\n') + 1
+	end = strings.IndexByte(text[pos:], '\n')
+	if end < 0 {
+		return start, len(text)
+	}
+	return start, pos + end
+}
+
+// firstNonBlank returns the first non-blank offset of the line starting at start.
+func firstNonBlank(text string, start int) int {
+	i := start
+	for i < len(text) && isBlank(text[i]) {
+		i++
+	}
+	return i
+}
+
+// nextWordStart implements w and W.
+func nextWordStart(text string, pos int, bigWord bool) int {
+	n := len(text)
+	if pos >= n {
+		return n
+	}
+	i := pos
+	if c := charClass(text[i], bigWord); c != classBlank {
+		for i < n && charClass(text[i], bigWord) == c {
+			i++
+		}
+	}
+	for i < n && charClass(text[i], bigWord) == classBlank {
+		i++
+	}
+	return i
+}
+
+// wordEnd implements e and E.
+func wordEnd(text string, pos int, bigWord bool) int {
+	n := len(text)
+	i := pos + 1
+	for i < n && charClass(text[i], bigWord) == classBlank {
+		i++
+	}
+	if i >= n {
+		return max(n-1, 0)
+	}
+	c := charClass(text[i], bigWord)
+	for i+1 < n && charClass(text[i+1], bigWord) == c {
+		i++
+	}
+	return i
+}
+
+// prevWordStart implements b and B.
+func prevWordStart(text string, pos int, bigWord bool) int {
+	if pos > len(text) {
+		pos = len(text)
+	}
+	i := pos
+	for i > 0 && charClass(text[i-1], bigWord) == classBlank {
+		i--
+	}
+	if i == 0 {
+		return 0
+	}
+	c := charClass(text[i-1], bigWord)
+	for i > 0 && charClass(text[i-1], bigWord) == c {
+		i--
+	}
+	return i
+}
+
+// findInLine implements f, t, F and T: the count-th occurrence of ch on the
+// cursor's line. till stops one character short of it.
+func findInLine(text string, pos int, ch string, forward, till bool, count int) (int, bool) {
+	start, end := lineBounds(text, pos)
+	i := pos
+	for n := 0; n < count; n++ {
+		var j int
+		if forward {
+			from := i + 1
+			if from > end {
+				return pos, false
+			}
+			j = strings.Index(text[from:end], ch)
+			if j < 0 {
+				return pos, false
+			}
+			j += from
+		} else {
+			j = strings.LastIndex(text[start:i], ch)
+			if j < 0 {
+				return pos, false
+			}
+			j += start
+		}
+		i = j
+	}
+	switch {
+	case till && forward:
+		return i - 1, true
+	case till:
+		return i + len(ch), true
+	}
+	return i, true
+}
+
+// bracketPairs returns the offsets of matching ( ) and { } pairs, ignoring
+// brackets inside strings, quoted identifiers and comments.
+func bracketPairs(text string, open, close string) [][2]int {
+	var pairs [][2]int
+	var stack []int
+	for _, t := range sqlparse.Tokenize(text) {
+		if t.Kind != sqlparse.Punct {
+			continue
+		}
+		switch t.Text {
+		case open:
+			stack = append(stack, t.Pos)
+		case close:
+			if len(stack) > 0 {
+				pairs = append(pairs, [2]int{stack[len(stack)-1], t.Pos})
+				stack = stack[:len(stack)-1]
+			}
+		}
+	}
+	return pairs
+}
+
+// matchBracket implements %: jump from the first bracket at or after the
+// cursor on its line to its partner.
+func matchBracket(text string, pos int) (int, bool) {
+	_, end := lineBounds(text, pos)
+	best, target := -1, 0
+	for _, br := range [][2]string{{"(", ")"}, {"{", "}"}} {
+		for _, p := range bracketPairs(text, br[0], br[1]) {
+			for side, at := range p {
+				if at < pos || at >= end || (best >= 0 && at >= best) {
+					continue
+				}
+				best, target = at, p[1-side]
+			}
+		}
+	}
+	return target, best >= 0
+}
+
+// paragraphForward implements }: the next blank line after a non-blank one.
+func paragraphForward(text string, pos int) int {
+	lines := strings.Split(text, "\n")
+	row, _ := indexToLineCol(text, pos)
+	seenText := false
+	for r := row + 1; r < len(lines); r++ {
+		blank := strings.TrimSpace(lines[r]) == ""
+		if blank && seenText {
+			return lineColToIndex(text, r, 0)
+		}
+		seenText = seenText || !blank
+	}
+	return len(text)
+}
+
+// paragraphBackward implements {.
+func paragraphBackward(text string, pos int) int {
+	lines := strings.Split(text, "\n")
+	row, _ := indexToLineCol(text, pos)
+	seenText := false
+	for r := row - 1; r >= 0; r-- {
+		blank := strings.TrimSpace(lines[r]) == ""
+		if blank && seenText {
+			return lineColToIndex(text, r, 0)
+		}
+		seenText = seenText || !blank
+	}
+	return 0
+}
+
+// compileSearch compiles a search pattern: a regular expression, or literal
+// text if it is not valid as one. All-lowercase patterns ignore case.
+func compileSearch(pattern string) *regexp.Regexp {
+	flags := ""
+	if strings.IndexFunc(pattern, unicode.IsUpper) < 0 {
+		flags = "(?i)"
+	}
+	re, err := regexp.Compile(flags + pattern)
+	if err != nil {
+		re = regexp.MustCompile(flags + regexp.QuoteMeta(pattern))
+	}
+	return re
+}
+
+// searchText returns the start of the next match of pattern after pos, or the
+// previous one before it, wrapping around the text.
+func searchText(text, pattern string, pos int, backward bool) (match int, wrapped, ok bool) {
+	matches := compileSearch(pattern).FindAllStringIndex(text, -1)
+	if len(matches) == 0 {
+		return pos, false, false
+	}
+	if backward {
+		for i := len(matches) - 1; i >= 0; i-- {
+			if matches[i][0] < pos {
+				return matches[i][0], false, true
+			}
+		}
+		return matches[len(matches)-1][0], true, true
+	}
+	for _, loc := range matches {
+		if loc[0] > pos {
+			return loc[0], false, true
+		}
+	}
+	return matches[0][0], true, true
+}
+
+// wordAt returns the word under or after the cursor on its line, for * and #.
+func wordAt(text string, pos int) string {
+	_, end := lineBounds(text, pos)
+	for pos < end && charClass(text[pos], false) != classWord {
+		pos++
+	}
+	if pos >= end {
+		return ""
+	}
+	start := pos
+	for start > 0 && charClass(text[start-1], false) == classWord {
+		start--
+	}
+	for pos < end && charClass(text[pos], false) == classWord {
+		pos++
+	}
+	return text[start:pos]
+}
+
+// textObject selects the range for a text object key such as "iw", "a(",
+// "i'" or "is". Linewise objects cover whole lines.
+func textObject(text string, pos int, key string, count int) (start, end int, linewise, ok bool) {
+	if len(key) != 2 || len(text) == 0 {
+		return 0, 0, false, false
+	}
+	around := key[0] == 'a'
+	switch obj := key[1:]; obj {
+	case "w", "W":
+		start, end, ok = wordObject(text, pos, around, obj == "W")
+	case "(", ")", "b":
+		start, end, ok = bracketObject(text, pos, "(", ")", around, count)
+	case "{", "}", "B":
+		start, end, ok = bracketObject(text, pos, "{", "}", around, count)
+	case "'", "\"", "`":
+		start, end, ok = quoteObject(text, pos, obj[0], around)
+	case "s":
+		start, end, ok = statementObject(text, pos, around)
+	case "p":
+		start, end, ok = paragraphObject(text, pos, around)
+		linewise = true
+	}
+	return start, end, linewise, ok
+}
+
+// wordObject implements iw/aw and iW/aW. The around form takes the blanks
+// after the word, or before it when there are none after.
+func wordObject(text string, pos int, around, bigWord bool) (int, int, bool) {
+	if pos >= len(text) {
+		return 0, 0, false
+	}
+	lineStart, lineEnd := lineBounds(text, pos)
+	if pos == lineEnd {
+		return 0, 0, false
+	}
+	c := charClass(text[pos], bigWord)
+	start, end := pos, pos+1
+	for start > lineStart && charClass(text[start-1], bigWord) == c {
+		start--
+	}
+	for end < lineEnd && charClass(text[end], bigWord) == c {
+		end++
+	}
+	if !around || c == classBlank {
+		return start, end, true
+	}
+	if end < lineEnd && isBlank(text[end]) {
+		for end < lineEnd && isBlank(text[end]) {
+			end++
+		}
+	} else {
+		for start > lineStart && isBlank(text[start-1]) {
+			start--
+		}
+	}
+	return start, end, true
+}
+
+// bracketObject implements i( a( and i{ a{: the count-th pair of brackets
+// enclosing the cursor.
+func bracketObject(text string, pos int, open, close string, around bool, count int) (int, int, bool) {
+	var enclosing [][2]int
+	for _, p := range bracketPairs(text, open, close) {
+		if p[0] <= pos && pos <= p[1] {
+			enclosing = append(enclosing, p)
+		}
+	}
+	if count > len(enclosing) || count < 1 {
+		return 0, 0, false
+	}
+	// Innermost first: pairs close in order, so inner pairs start later
+	for i := 1; i < len(enclosing); i++ {
+		for j := i; j > 0 && enclosing[j][0] > enclosing[j-1][0]; j-- {
+			enclosing[j], enclosing[j-1] = enclosing[j-1], enclosing[j]
+		}
+	}
+	p := enclosing[count-1]
+	if around {
+		return p[0], p[1] + 1, true
+	}
+	return p[0] + 1, p[1], true
+}
+
+// quoteObject implements i' a' i" a" and i` a`. It uses the SQL lexer, so a
+// doubled quote inside a string does not end it. Without a string under the
+// cursor it takes the next one on the line.
+func quoteObject(text string, pos int, quote byte, around bool) (int, int, bool) {
+	_, lineEnd := lineBounds(text, pos)
+	for _, t := range sqlparse.Tokenize(text) {
+		if t.End() <= pos || t.Pos >= lineEnd {
+			continue
+		}
+		if (t.Kind != sqlparse.String && t.Kind != sqlparse.QuotedIdent) || t.Text[0] != quote {
+			continue
+		}
+		start, end := t.Pos, t.End()
+		if !around {
+			start++
+			if !t.Unterminated && end > start {
+				end--
+			}
+			return start, end, true
+		}
+		if end < len(text) && isBlank(text[end]) {
+			for end < len(text) && isBlank(text[end]) {
+				end++
+			}
+		} else {
+			for start > 0 && isBlank(text[start-1]) {
+				start--
+			}
+		}
+		return start, end, true
+	}
+	return 0, 0, false
+}
+
+// statementObject implements is/as: the SQL statement under the cursor. The
+// around form includes the terminating semicolon and the blanks after it.
+func statementObject(text string, pos int, around bool) (int, int, bool) {
+	stmts := sqlparse.Statements(text)
+	if len(stmts) == 0 {
+		return 0, 0, false
+	}
+	s := stmts[0]
+	for _, st := range stmts {
+		if st.Pos <= pos {
+			s = st
+		}
+	}
+	start, end := s.Pos, s.Pos+len(s.Text)
+	if !around {
+		return start, end, true
+	}
+	if i := strings.IndexByte(text[end:], ';'); i >= 0 && strings.TrimSpace(text[end:end+i]) == "" {
+		end += i + 1
+	}
+	for end < len(text) && charClass(text[end], false) == classBlank {
+		end++
+	}
+	return start, end, true
+}
+
+// paragraphObject implements ip/ap: the block of non-blank (or blank) lines
+// around the cursor, plus the blank lines after it for ap.
+func paragraphObject(text string, pos int, around bool) (int, int, bool) {
+	lines := strings.Split(text, "\n")
+	row, _ := indexToLineCol(text, pos)
+	blank := func(r int) bool { return strings.TrimSpace(lines[r]) == "" }
+	kind := blank(row)
+	first, last := row, row
+	for first > 0 && blank(first-1) == kind {
+		first--
+	}
+	for last+1 < len(lines) && blank(last+1) == kind {
+		last++
+	}
+	if around {
+		next := last
+		for next+1 < len(lines) && blank(next+1) != kind {
+			next++
+		}
+		if next > last {
+			last = next
+		} else if !kind {
+			// Last paragraph: take the blank lines before it instead
+			for first > 0 && blank(first-1) {
+				first--
+			}
+		}
+	}
+	return lineColToIndex(text, first, 0), lineColToIndex(text, last, len(lines[last])), true
+}
@@ -0,0 +1,290 @@
+package screens
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	tea "charm.land/bubbletea/v2"
+
+	"github.com/jupiterozeye/tornado/internal/sqlparse"
+)
+
+// watchInterval is how often the database is checked for commits from other
+// connections while watching.
+const watchInterval = time.Second
+
+// defaultQueryWatchInterval is how often ":watch" re-runs its query when no
+// interval is given.
+const defaultQueryWatchInterval = 2 * time.Second
+
+// minQueryWatchInterval is the shortest interval ":watch" accepts, so a
+// watched query cannot keep the database busy.
+const minQueryWatchInterval = time.Second
+
+// queryWatch re-runs a query on an interval, started with ":watch".
+type queryWatch struct {
+	Query    string
+	Interval time.Duration
+	Paused   bool
+	gen      int
+	running  bool // A run is in flight; ticks skip until it reports
+}
+
+// queryWatchTickMsg fires when a watched query is due to run again.
+type queryWatchTickMsg struct {
+	Gen int
+}
+
+// watchTickMsg fires when the data version is due to be checked. Gen ties it
+// to the watch that scheduled it, so turning watching off and on again does
+// not leave two polling loops running.
+type watchTickMsg struct {
+	Gen int
+}
+
+// DataVersionMsg carries the data version read by a watch poll.
+type DataVersionMsg struct {
+	Gen     int
+	Version int64
+	Err     error
+}
+
+// watching reports whether the database is being polled for changes.
+func (m *BrowserModel) watching() bool {
+	return m.watch || m.autoRerun
+}
+
+// restartWatch starts or stops polling after a watch option changed.
+func (m *BrowserModel) restartWatch() tea.Cmd {
+	m.watchGen++
+	m.dbChanged = false
+	m.dataVersionKnown = false
+	if !m.watching() || m.db == nil {
+		return nil
+	}
+	if m.db.GetType() != "sqlite" {
+		m.statusMsg = "watch: only SQLite databases report external changes"
+		return nil
+	}
+	return m.scheduleWatch()
+}
+
+func (m *BrowserModel) scheduleWatch() tea.Cmd {
+	gen := m.watchGen
+	return tea.Tick(watchInterval, func(time.Time) tea.Msg {
+		return watchTickMsg{Gen: gen}
+	})
+}
+
+// checkDataVersion reads the data version in the background.
+func (m *BrowserModel) checkDataVersion(gen int) tea.Cmd {
+	if gen != m.watchGen || !m.watching() || m.db == nil || m.ctx.Err() != nil {
+		return nil
+	}
+	database := m.db
+	return func() tea.Msg {
+		version, err := database.DataVersion()
+		return DataVersionMsg{Gen: gen, Version: version, Err: err}
+	}
+}
+
+// handleDataVersion flags the results as stale when another connection
+// committed since the last poll, re-running the active result's query when
+// autorerun is set, and keeps polling.
+func (m *BrowserModel) handleDataVersion(msg DataVersionMsg) tea.Cmd {
+	if msg.Gen != m.watchGen || !m.watching() {
+		return nil
+	}
+	if msg.Err != nil {
+		return m.scheduleWatch()
+	}
+
+	changed := m.dataVersionKnown && msg.Version != m.dataVersion
+	m.dataVersion = msg.Version
+	m.dataVersionKnown = true
+	if !changed {
+		return m.scheduleWatch()
+	}
+
+	m.dbChanged = true
+	if m.autoRerun {
+		if cmd := m.rerunActiveResult(); cmd != nil {
+			return tea.Batch(cmd, m.scheduleWatch())
+		}
+	}
+	return m.scheduleWatch()
+}
+
+// rerunActiveResult runs the active result tab's query again. Only scripts
+// whose every statement reads are re-run; writes never repeat on their own.
+func (m *BrowserModel) rerunActiveResult() tea.Cmd {
+	if m.activeTab < 0 || m.activeTab >= len(m.resultTabs) {
+		return nil
+	}
+	query := m.resultTabs[m.activeTab].Query
+	if query == "" || !sqlparse.ReadOnly(query) {
+		return nil
+	}
+	return m.rerunQuery(query)
+}
+
+// rerunQuery runs query in the background, reporting a QueryExecutedMsg
+// marked as a re-run.
+func (m *BrowserModel) rerunQuery(query string) tea.Cmd {
+	run := m.executeSQL(query)
+	return func() tea.Msg {
+		msg := run().(QueryExecutedMsg)
+		msg.Rerun = true
+		return msg
+	}
+}
+
+// applyRerun refreshes the tab showing a re-run query: the active tab when
+// it matches, otherwise the newest tab with that query. The active tab keeps
+// its filter, cursor and scroll position and focus stays where it is. Cells
+// that changed since the previous run are recorded for highlighting.
+func (m *BrowserModel) applyRerun(msg QueryExecutedMsg) {
+	if w := m.queryWatch; w != nil && w.Query == msg.Query {
+		w.running = false
+	}
+
+	i := -1
+	if m.activeTab >= 0 && m.activeTab < len(m.resultTabs) && m.resultTabs[m.activeTab].Query == msg.Query {
+		i = m.activeTab
+	} else {
+		for j := len(m.resultTabs) - 1; j >= 0; j-- {
+			if m.resultTabs[j].Query == msg.Query {
+				i = j
+				break
+			}
+		}
+	}
+	if i < 0 {
+		return
+	}
+
+	tab := m.resultTabs[i]
+	if i == m.activeTab {
+		m.saveActiveTab()
+	}
+	tab.diff = diffResults(tab.Result, msg.Result)
+	tab.Result = msg.Result
+	tab.Err = ""
+	if msg.Err != nil {
+		tab.Err = msg.Err.Error()
+	}
+	tab.ExecutedAt = time.Now()
+	if i == m.activeTab {
+		m.loadTab(i)
+		m.dbChanged = false
+	}
+}
+
+// watchQuery handles ":watch": with an interval in seconds (or none) it runs
+// the query buffer now and again on every interval; "pause" pauses or
+// resumes and "off" stops.
+func (m *BrowserModel) watchQuery(arg string) tea.Cmd {
+	switch arg {
+	case "off":
+		if m.queryWatch == nil {
+			m.statusMsg = "No query is being watched"
+			return nil
+		}
+		m.queryWatch = nil
+		m.statusMsg = "Watch stopped"
+		return nil
+	case "pause":
+		m.toggleQueryWatchPause()
+		return nil
+	}
+
+	interval := defaultQueryWatchInterval
+	if arg != "" {
+		secs, err := strconv.ParseFloat(arg, 64)
+		if err != nil || secs <= 0 {
+			m.statusMsg = "Usage: watch [seconds|pause|off]"
+			return nil
+		}
+		interval = time.Duration(secs * float64(time.Second))
+		if interval < minQueryWatchInterval {
+			m.statusMsg = fmt.Sprintf("watch interval must be at least %s", minQueryWatchInterval)
+			return nil
+		}
+	}
+
+	query := m.query.Value()
+	if strings.TrimSpace(query) == "" {
+		m.statusMsg = "Nothing to watch"
+		return nil
+	}
+	if !sqlparse.ReadOnly(query) {
+		m.statusMsg = "watch only re-runs SELECT, WITH and EXPLAIN"
+		return nil
+	}
+
+	m.queryWatchGen++
+	m.queryWatch = &queryWatch{Query: query, Interval: interval, gen: m.queryWatchGen}
+	m.statusMsg = fmt.Sprintf("Watching every %s: P pauses, :watch off stops", interval)
+	return tea.Batch(m.executeQuery(), m.scheduleQueryWatch())
+}
+
+// toggleQueryWatchPause pauses or resumes the watched query.
+func (m *BrowserModel) toggleQueryWatchPause() {
+	w := m.queryWatch
+	if w == nil {
+		m.statusMsg = "No query is being watched"
+		return
+	}
+	w.Paused = !w.Paused
+	if w.Paused {
+		m.statusMsg = "Watch paused"
+	} else {
+		m.statusMsg = "Watch resumed"
+	}
+}
+
+func (m *BrowserModel) scheduleQueryWatch() tea.Cmd {
+	w := m.queryWatch
+	if w == nil {
+		return nil
+	}
+	gen := w.gen
+	return tea.Tick(w.Interval, func(time.Time) tea.Msg {
+		return queryWatchTickMsg{Gen: gen}
+	})
+}
+
+// handleQueryWatchTick re-runs the watched query unless it is paused or the
+// previous run has not finished, and schedules the next tick.
+func (m *BrowserModel) handleQueryWatchTick(msg queryWatchTickMsg) tea.Cmd {
+	w := m.queryWatch
+	if w == nil || msg.Gen != w.gen || m.ctx.Err() != nil {
+		return nil
+	}
+	next := m.scheduleQueryWatch()
+	if w.Paused || w.running {
+		return next
+	}
+	w.running = true
+	return tea.Batch(next, m.rerunQuery(w.Query))
+}
+
+// resultsTitle names the results pane along with any watch state.
+func (m *BrowserModel) resultsTitle() string {
+	title := "Results"
+	if w := m.queryWatch; w != nil {
+		if w.Paused {
+			title += " [watch paused]"
+		} else {
+			title += fmt.Sprintf(" [every %s]", w.Interval)
+		}
+	}
+	if m.dbChanged {
+		title += " [database changed]"
+	} else if m.watching() {
+		title += " [watching]"
+	}
+	return title
+}